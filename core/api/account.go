@@ -30,8 +30,9 @@ import (
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {Boolean} debitBalance True if Account has a debit balance.
- * @apiSuccess {Number} balance Current Account balance in this Account's currency
- * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency
+ * @apiSuccess {Boolean} cash True if Account and its descendants hold cash.
+ * @apiSuccess {Number} balance Current Account balance in this Account's currency. 0 if the Account has no splits.
+ * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency. 0 if the Account has no splits.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -46,6 +47,7 @@ import (
  *         "currency": "USD",
  *         "precision": 2,
  *         "debitBalance": true,
+ *         "cash": false,
  *         "balance": 10000,
  *         "nativeBalance": 10000
  *       }
@@ -102,8 +104,9 @@ func GetOrgAccounts(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {Boolean} debitBalance True if Account has a debit balance.
- * @apiSuccess {Number} balance Current Account balance in this Account's currency
- * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency
+ * @apiSuccess {Boolean} cash True if Account and its descendants hold cash.
+ * @apiSuccess {Number} balance Current Account balance in this Account's currency. 0 if the Account has no splits.
+ * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency. 0 if the Account has no splits.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -117,6 +120,7 @@ func GetOrgAccounts(w rest.ResponseWriter, r *rest.Request) {
  *       "currency": "USD",
  *       "precision": 2,
  *       "debitBalance": true,
+ *       "cash": false,
  *       "balance": 10000,
  *       "nativeBalance": 10000
  *    }
@@ -170,6 +174,7 @@ func GetOrgAccount(w rest.ResponseWriter, r *rest.Request) {
  * @apiParam {String} currency Three letter currency code.
  * @apiParam {Number} precision How many digits the currency goes out to.
  * @apiParam {Boolean} debitBalance True if account has a debit balance.
+ * @apiParam {Boolean} cash True if Account and its descendants hold cash.
  * @apiParam {Number} balance Current Account balance in this Account's currency
  * @apiParam {Number} nativeBalance Current Account balance in the Org's currency
  *
@@ -182,8 +187,9 @@ func GetOrgAccount(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {Boolean} debitBalance True if account has a debit balance.
- * @apiSuccess {Number} balance Current Account balance in this Account's currency
- * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency
+ * @apiSuccess {Boolean} cash True if Account and its descendants hold cash.
+ * @apiSuccess {Number} balance Current Account balance in this Account's currency. 0 if the Account has no splits.
+ * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency. 0 if the Account has no splits.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -197,6 +203,7 @@ func GetOrgAccount(w rest.ResponseWriter, r *rest.Request) {
  *       "currency": "USD",
  *       "precision": 2,
  *       "debitBalance": true,
+ *       "cash": false,
  *       "balance": 10000,
  *       "nativeBalance": 10000
  *       }
@@ -283,6 +290,7 @@ func PostAccounts(w rest.ResponseWriter, r *rest.Request, content []byte) {
  * @apiParam {String} currency Three letter currency code.
  * @apiParam {Number} precision How many digits the currency goes out to.
  * @apiParam {Boolean} debitBalance True if Account has a debit balance.
+ * @apiParam {Boolean} cash True if Account and its descendants hold cash.
  * @apiParam {Number} balance Current Account balance in this Account's currency
  * @apiParam {Number} nativeBalance Current Account balance in the Org's currency
  *
@@ -295,8 +303,9 @@ func PostAccounts(w rest.ResponseWriter, r *rest.Request, content []byte) {
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {Boolean} debitBalance True if Account has a debit balance.
- * @apiSuccess {Number} balance Current Account balance in this Account's currency
- * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency
+ * @apiSuccess {Boolean} cash True if Account and its descendants hold cash.
+ * @apiSuccess {Number} balance Current Account balance in this Account's currency. 0 if the Account has no splits.
+ * @apiSuccess {Number} nativeBalance Current Account balance in the Org's currency. 0 if the Account has no splits.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -310,6 +319,7 @@ func PostAccounts(w rest.ResponseWriter, r *rest.Request, content []byte) {
  *       "currency": "USD",
  *       "precision": 2,
  *       "debitBalance": true,
+ *       "cash": false,
  *       "balance": 10000,
  *       "nativeBalance": 10000
  *       }
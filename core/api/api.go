@@ -7,6 +7,52 @@ import (
 /**
  * Changelog
  *
+ * 1.5.0
+ * - add `GET /orgs/:orgId/reports/trial-balance`
+ * - add `GET /orgs/:orgId/reports/income-statement`
+ * - add `GET /orgs/:orgId/reports/balance-sheet`
+ * - reports accept `periods` and `interval` to return one column per period
+ * - add account.cash
+ * - add `GET /orgs/:orgId/reports/cash-flow`
+ * - add `GET /orgs/:orgId/accounts/:accountId/general-ledger`
+ * - add `GET /orgs/:orgId/accounts/:accountId/balance-history`
+ * - account.balance and account.nativeBalance are 0 instead of null for accounts without splits
+ * - add named budgets with per-period amounts under `/orgs/:orgId/budgets`
+ * - add `GET /orgs/:orgId/reports/budget-variance`
+ * - add org.lockDate and `PUT /orgs/:orgId/lock-date`
+ * - add accounting periods under `/orgs/:orgId/periods` with close and reopen
+ * - transactions dated before the lock date or in a closed period cannot be changed
+ * - add org.fiscalYearStart
+ * - add year-end closings under `/orgs/:orgId/closings`
+ * - income statements leave out closing transactions
+ * - add transaction.reversalOf and transaction.autoReverseDate
+ * - add `POST /orgs/:orgId/transactions/:transactionId/reverse`
+ * - transactions with an autoReverseDate are reversed automatically on that date
+ * - add recurring transactions under `/orgs/:orgId/recurring-transactions`
+ * - add transaction templates under `/orgs/:orgId/templates`
+ * - add `POST /orgs/:orgId/templates/:templateId/instantiate`
+ * - add `POST /orgs/:orgId/transactions/batch`
+ * - add transaction split.externalId
+ * - add CSV statement import under `/orgs/:orgId/accounts/:accountId/import/csv`
+ * - add `GET /orgs/:orgId/accounts/:accountId/csv-mapping` and `PUT /orgs/:orgId/accounts/:accountId/csv-mapping`
+ * - add OFX, QFX and QIF import under `/orgs/:orgId/accounts/:accountId/import/ofx` and `/orgs/:orgId/accounts/:accountId/import/qif`
+ * - add transaction.possibleDuplicates to `POST /orgs/:orgId/transactions` responses
+ * - imports hold back possible duplicates unless allowDuplicates is set
+ * - add categorization rules under `/orgs/:orgId/rules`, applied automatically on import
+ * - add `POST /orgs/:orgId/rules/apply`
+ * - add split.status: uncleared, cleared or reconciled
+ * - add account reconciliations under `/orgs/:orgId/reconciliations`
+ * - reconciled splits cannot be changed or deleted
+ * - add split.memo, split.quantity and split.unitPrice
+ * - splits are returned in the order they were saved in
+ * - add contacts under `/orgs/:orgId/contacts`
+ * - add transaction.contactId and the contactId query option for listing transactions
+ * - add transaction.tags and the tag query option for listing transactions
+ * - add `GET /orgs/:orgId/reports/tags`
+ * - add dimensions under `/orgs/:orgId/dimensions`
+ * - add split.dimensions
+ * - add the dimensionValue and pivot options to the trial balance, income statement and balance sheet
+ *
  * 1.4.0
  * - add `GET /orgs/:orgId/budget`
  * - add `POST /orgs/:orgId/budget`
@@ -48,6 +94,24 @@ import (
  *
  */
 
+/**
+ * @apiDefine ReportPeriodParams
+ *
+ * @apiParam {String} start First day of the period (YYYY-MM-DD) in the Org's timezone
+ * @apiParam {String} end Last day of the period (YYYY-MM-DD) in the Org's timezone
+ * @apiParam {String} interval "day", "week", "month", "quarter" or "year" to split each period into one column per interval
+ * @apiParam {String} periods Comma separated list of periods (YYYY-MM-DD..YYYY-MM-DD), one column each. Overrides start and end.
+ */
+
+/**
+ * @apiDefine ReportDimensionParams
+ *
+ * @apiParam {String} dimensionValue Only include splits with this Dimension value. Can be repeated. Splits need one of the values given for each Dimension.
+ * @apiParam {String} pivot Id of a Dimension. Repeats the columns for each of its values followed by splits without a value.
+ *
+ * @apiSuccess {String[]} pivotValues Pivot value of each column, empty for splits without a value. Null if not pivoted.
+ */
+
 func Init(prefix string) (*rest.Api, error) {
 	rest.ErrorFieldName = "error"
 	app := rest.NewApi()
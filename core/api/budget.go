@@ -147,3 +147,316 @@ func DeleteBudget(w rest.ResponseWriter, r *rest.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+/**
+ * @api {get} /orgs/:orgId/budgets Get Named Budgets
+ * @apiVersion 1.5.0
+ * @apiName GetNamedBudgets
+ * @apiGroup Budget
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Only items for Accounts the user has access to are returned.
+ *
+ * @apiSuccess {String} id Id of the Budget.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Budget was created
+ * @apiSuccess {Date} updated Date Budget was last updated
+ * @apiSuccess {String} name Name of the Budget
+ * @apiSuccess {String} interval Length of each period ("month" or "quarter")
+ * @apiSuccess {Date} start Start of the first period
+ * @apiSuccess {Number} periods Number of periods
+ * @apiSuccess {Object[]} items Array of Budget Items
+ * @apiSuccess {String} items.accountId Id of Account
+ * @apiSuccess {Number} items.period Index of the period, starting at 0
+ * @apiSuccess {Number} items.amount Amount budgeted for the period
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "33333333333333333333333333333333",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "inserted": "2026-01-13T20:12:29.720Z",
+ *         "updated": "2026-01-13T20:12:29.720Z",
+ *         "name": "FY2026",
+ *         "interval": "month",
+ *         "start": "2026-01-01T05:00:00.000Z",
+ *         "periods": 12,
+ *         "items": [
+ *           {
+ *             "accountId": "11111111111111111111111111111111",
+ *             "period": 0,
+ *             "amount": 35000
+ *           },
+ *           {
+ *             "accountId": "11111111111111111111111111111111",
+ *             "period": 1,
+ *             "amount": 32000
+ *           }
+ *         ]
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetNamedBudgets(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	budgets, err := model.Instance.GetNamedBudgets(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&budgets)
+}
+
+/**
+ * @api {get} /orgs/:orgId/budgets/:budgetId Get Named Budget
+ * @apiVersion 1.5.0
+ * @apiName GetNamedBudget
+ * @apiGroup Budget
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Only items for Accounts the user has access to are returned.
+ *
+ * @apiSuccess {String} id Id of the Budget.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Budget was created
+ * @apiSuccess {Date} updated Date Budget was last updated
+ * @apiSuccess {String} name Name of the Budget
+ * @apiSuccess {String} interval Length of each period ("month" or "quarter")
+ * @apiSuccess {Date} start Start of the first period
+ * @apiSuccess {Number} periods Number of periods
+ * @apiSuccess {Object[]} items Array of Budget Items
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "33333333333333333333333333333333",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2026-01-13T20:12:29.720Z",
+ *       "updated": "2026-01-13T20:12:29.720Z",
+ *       "name": "Forecast Q3",
+ *       "interval": "quarter",
+ *       "start": "2026-07-01T04:00:00.000Z",
+ *       "periods": 1,
+ *       "items": [
+ *         {
+ *           "accountId": "11111111111111111111111111111111",
+ *           "period": 0,
+ *           "amount": 105000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetNamedBudget(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	budgetId := r.PathParam("budgetId")
+
+	budget, err := model.Instance.GetNamedBudget(orgId, budgetId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&budget)
+}
+
+/**
+ * @api {post} /orgs/:orgId/budgets Create a Named Budget
+ * @apiVersion 1.5.0
+ * @apiName PostNamedBudget
+ * @apiGroup Budget
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} id 32 character hex string
+ * @apiParam {String} name Name of the Budget
+ * @apiParam {String} interval Length of each period ("month" or "quarter")
+ * @apiParam {Date} start Start of the first period
+ * @apiParam {Number} periods Number of periods
+ * @apiParam {Object[]} items Array of Budget Items
+ * @apiParam {String} items.accountId Id of Account
+ * @apiParam {Number} items.period Index of the period, starting at 0
+ * @apiParam {Number} items.amount Amount budgeted for the period
+ *
+ * @apiSuccess {String} id Id of the Budget.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Budget was created
+ * @apiSuccess {Date} updated Date Budget was last updated
+ * @apiSuccess {String} name Name of the Budget
+ * @apiSuccess {String} interval Length of each period ("month" or "quarter")
+ * @apiSuccess {Date} start Start of the first period
+ * @apiSuccess {Number} periods Number of periods
+ * @apiSuccess {Object[]} items Array of Budget Items
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "33333333333333333333333333333333",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2026-01-13T20:12:29.720Z",
+ *       "updated": "2026-01-13T20:12:29.720Z",
+ *       "name": "FY2026",
+ *       "interval": "month",
+ *       "start": "2026-01-01T05:00:00.000Z",
+ *       "periods": 12,
+ *       "items": [
+ *         {
+ *           "accountId": "11111111111111111111111111111111",
+ *           "period": 0,
+ *           "amount": 35000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostNamedBudget(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	budget := types.Budget{}
+	err := r.DecodeJsonPayload(&budget)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	budget.OrgId = orgId
+
+	err = model.Instance.CreateNamedBudget(&budget, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&budget)
+}
+
+/**
+ * @api {put} /orgs/:orgId/budgets/:budgetId Modify a Named Budget
+ * @apiVersion 1.5.0
+ * @apiName PutNamedBudget
+ * @apiGroup Budget
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Items for Accounts the user does not have access to are kept
+ * as they were.
+ *
+ * @apiParam {String} name Name of the Budget
+ * @apiParam {String} interval Length of each period ("month" or "quarter")
+ * @apiParam {Date} start Start of the first period
+ * @apiParam {Number} periods Number of periods
+ * @apiParam {Object[]} items Array of Budget Items. Replaces all existing items.
+ * @apiParam {String} items.accountId Id of Account
+ * @apiParam {Number} items.period Index of the period, starting at 0
+ * @apiParam {Number} items.amount Amount budgeted for the period
+ *
+ * @apiSuccess {String} id Id of the Budget.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Budget was created
+ * @apiSuccess {Date} updated Date Budget was last updated
+ * @apiSuccess {String} name Name of the Budget
+ * @apiSuccess {String} interval Length of each period ("month" or "quarter")
+ * @apiSuccess {Date} start Start of the first period
+ * @apiSuccess {Number} periods Number of periods
+ * @apiSuccess {Object[]} items Array of Budget Items
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "33333333333333333333333333333333",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2026-01-13T20:12:29.720Z",
+ *       "updated": "2026-02-01T16:40:02.113Z",
+ *       "name": "FY2026",
+ *       "interval": "month",
+ *       "start": "2026-01-01T05:00:00.000Z",
+ *       "periods": 12,
+ *       "items": [
+ *         {
+ *           "accountId": "11111111111111111111111111111111",
+ *           "period": 0,
+ *           "amount": 40000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutNamedBudget(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	budgetId := r.PathParam("budgetId")
+
+	budget := types.Budget{}
+	err := r.DecodeJsonPayload(&budget)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	budget.Id = budgetId
+	budget.OrgId = orgId
+
+	err = model.Instance.UpdateNamedBudget(&budget, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&budget)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/budgets/:budgetId Delete a Named Budget
+ * @apiVersion 1.5.0
+ * @apiName DeleteNamedBudget
+ * @apiGroup Budget
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteNamedBudget(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	budgetId := r.PathParam("budgetId")
+
+	err := model.Instance.DeleteNamedBudget(orgId, budgetId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
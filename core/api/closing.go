@@ -0,0 +1,162 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/closings Get year-end Closings
+ * @apiVersion 1.5.0
+ * @apiName GetClosings
+ * @apiGroup Closing
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Closing.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who closed the year.
+ * @apiSuccess {Date} inserted Date the year was closed
+ * @apiSuccess {Number} year Fiscal year, named after the calendar year it ends in
+ * @apiSuccess {Date} start Start of the fiscal year
+ * @apiSuccess {Date} end End of the fiscal year (exclusive)
+ * @apiSuccess {String} equityAccountId Id of the Equity Account earnings were closed to
+ * @apiSuccess {String} transactionId Id of the closing Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "userId": "33333333333333333333333333333333",
+ *         "inserted": "2019-01-20T18:05:04.420Z",
+ *         "year": 2018,
+ *         "start": "2018-01-01T05:00:00.000Z",
+ *         "end": "2019-01-01T05:00:00.000Z",
+ *         "equityAccountId": "44444444444444444444444444444444",
+ *         "transactionId": "55555555555555555555555555555555"
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetClosings(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	closings, err := model.Instance.GetClosings(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&closings)
+}
+
+/**
+ * @api {post} /orgs/:orgId/closings Close a fiscal year
+ * @apiVersion 1.5.0
+ * @apiName PostClosing
+ * @apiGroup Closing
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Creates a closing Transaction dated at the end of the fiscal
+ * year that moves the balance of every Income and Expense Account for the year
+ * into an Equity Account. Org admins only. Income statements leave closing
+ * Transactions out.
+ *
+ * @apiParam {String} id 32 character hex string
+ * @apiParam {Number} year Fiscal year to close, named after the calendar year it ends in
+ * @apiParam {String} equityAccountId Id of the Equity Account to close earnings to
+ *
+ * @apiSuccess {String} id Id of the Closing.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who closed the year.
+ * @apiSuccess {Date} inserted Date the year was closed
+ * @apiSuccess {Number} year Fiscal year, named after the calendar year it ends in
+ * @apiSuccess {Date} start Start of the fiscal year
+ * @apiSuccess {Date} end End of the fiscal year (exclusive)
+ * @apiSuccess {String} equityAccountId Id of the Equity Account earnings were closed to
+ * @apiSuccess {String} transactionId Id of the closing Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2019-01-20T18:05:04.420Z",
+ *       "year": 2018,
+ *       "start": "2018-01-01T05:00:00.000Z",
+ *       "end": "2019-01-01T05:00:00.000Z",
+ *       "equityAccountId": "44444444444444444444444444444444",
+ *       "transactionId": "55555555555555555555555555555555"
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostClosing(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	closing := types.Closing{}
+	err := r.DecodeJsonPayload(&closing)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	closing.OrgId = orgId
+
+	err = model.Instance.CreateClosing(&closing, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&closing)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/closings/:closingId Reverse a Closing
+ * @apiVersion 1.5.0
+ * @apiName DeleteClosing
+ * @apiGroup Closing
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Deletes the closing Transaction so the fiscal year can be
+ * closed again. Org admins only.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteClosing(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	closingId := r.PathParam("closingId")
+
+	err := model.Instance.DeleteClosing(orgId, closingId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,292 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/contacts Get Contacts
+ * @apiVersion 1.5.0
+ * @apiName GetContacts
+ * @apiGroup Contact
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Contact.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Contact was created
+ * @apiSuccess {Date} updated Date Contact was updated
+ * @apiSuccess {String} name Name of the Contact
+ * @apiSuccess {String} type customer, vendor or employee
+ * @apiSuccess {String} email Email address
+ * @apiSuccess {String} phone Phone number
+ * @apiSuccess {String} address Postal address
+ * @apiSuccess {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "name": "Corner Store",
+ *         "type": "vendor",
+ *         "email": "orders@cornerstore.com",
+ *         "phone": "555-0100",
+ *         "address": "1 Main St, Springfield",
+ *         "inactive": false
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetContacts(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	contacts, err := model.Instance.GetContacts(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&contacts)
+}
+
+/**
+ * @api {get} /orgs/:orgId/contacts/:contactId Get a Contact
+ * @apiVersion 1.5.0
+ * @apiName GetContact
+ * @apiGroup Contact
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Contact.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Contact was created
+ * @apiSuccess {Date} updated Date Contact was updated
+ * @apiSuccess {String} name Name of the Contact
+ * @apiSuccess {String} type customer, vendor or employee
+ * @apiSuccess {String} email Email address
+ * @apiSuccess {String} phone Phone number
+ * @apiSuccess {String} address Postal address
+ * @apiSuccess {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Corner Store",
+ *       "type": "vendor",
+ *       "email": "orders@cornerstore.com",
+ *       "phone": "555-0100",
+ *       "address": "1 Main St, Springfield",
+ *       "inactive": false
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetContact(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	contactId := r.PathParam("contactId")
+
+	contact, err := model.Instance.GetContact(orgId, contactId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(contact)
+}
+
+/**
+ * @api {post} /orgs/:orgId/contacts Create a Contact
+ * @apiVersion 1.5.0
+ * @apiName PostContact
+ * @apiGroup Contact
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Contacts are the customers, vendors and employees an Org
+ * deals with. Transactions can refer to a Contact with contactId.
+ *
+ * @apiParam {String} id Id 32 character hex string
+ * @apiParam {String} name Name of the Contact
+ * @apiParam {String} type customer, vendor or employee
+ * @apiParam {String} email Email address
+ * @apiParam {String} phone Phone number
+ * @apiParam {String} address Postal address
+ * @apiParam {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccess {String} id Id of the Contact.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Contact was created
+ * @apiSuccess {Date} updated Date Contact was updated
+ * @apiSuccess {String} name Name of the Contact
+ * @apiSuccess {String} type customer, vendor or employee
+ * @apiSuccess {String} email Email address
+ * @apiSuccess {String} phone Phone number
+ * @apiSuccess {String} address Postal address
+ * @apiSuccess {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Corner Store",
+ *       "type": "vendor",
+ *       "email": "orders@cornerstore.com",
+ *       "phone": "555-0100",
+ *       "address": "1 Main St, Springfield",
+ *       "inactive": false
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostContact(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	contact := types.Contact{}
+	err := r.DecodeJsonPayload(&contact)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contact.OrgId = orgId
+
+	err = model.Instance.CreateContact(&contact, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&contact)
+}
+
+/**
+ * @api {put} /orgs/:orgId/contacts/:contactId Modify a Contact
+ * @apiVersion 1.5.0
+ * @apiName PutContact
+ * @apiGroup Contact
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} name Name of the Contact
+ * @apiParam {String} type customer, vendor or employee
+ * @apiParam {String} email Email address
+ * @apiParam {String} phone Phone number
+ * @apiParam {String} address Postal address
+ * @apiParam {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccess {String} id Id of the Contact.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Contact was created
+ * @apiSuccess {Date} updated Date Contact was updated
+ * @apiSuccess {String} name Name of the Contact
+ * @apiSuccess {String} type customer, vendor or employee
+ * @apiSuccess {String} email Email address
+ * @apiSuccess {String} phone Phone number
+ * @apiSuccess {String} address Postal address
+ * @apiSuccess {Boolean} inactive True if the Contact is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Corner Store",
+ *       "type": "vendor",
+ *       "email": "orders@cornerstore.com",
+ *       "phone": "555-0100",
+ *       "address": "1 Main St, Springfield",
+ *       "inactive": false
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutContact(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	contactId := r.PathParam("contactId")
+
+	contact := types.Contact{}
+	err := r.DecodeJsonPayload(&contact)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	contact.Id = contactId
+	contact.OrgId = orgId
+
+	err = model.Instance.UpdateContact(&contact, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&contact)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/contacts/:contactId Delete a Contact
+ * @apiVersion 1.5.0
+ * @apiName DeleteContact
+ * @apiGroup Contact
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Only Contacts without Transactions can be deleted. Set
+ * inactive instead to hide a Contact that has been used.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteContact(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	contactId := r.PathParam("contactId")
+
+	err := model.Instance.DeleteContact(orgId, contactId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/dimensions Get Dimensions
+ * @apiVersion 1.5.0
+ * @apiName GetDimensions
+ * @apiGroup Dimension
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Dimension.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Dimension was created
+ * @apiSuccess {Date} updated Date Dimension was updated
+ * @apiSuccess {String} name Name of the Dimension
+ * @apiSuccess {Object[]} values Values of the Dimension in name order
+ * @apiSuccess {String} values.id Id of the value
+ * @apiSuccess {String} values.name Name of the value
+ * @apiSuccess {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "name": "Department",
+ *         "values": [
+ *           {
+ *             "id": "33333333333333333333333333333333",
+ *             "name": "Sales",
+ *             "inactive": false
+ *           },
+ *           {
+ *             "id": "44444444444444444444444444444444",
+ *             "name": "Support",
+ *             "inactive": false
+ *           }
+ *         ]
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetDimensions(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	dimensions, err := model.Instance.GetDimensions(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&dimensions)
+}
+
+/**
+ * @api {get} /orgs/:orgId/dimensions/:dimensionId Get a Dimension
+ * @apiVersion 1.5.0
+ * @apiName GetDimension
+ * @apiGroup Dimension
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Dimension.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Dimension was created
+ * @apiSuccess {Date} updated Date Dimension was updated
+ * @apiSuccess {String} name Name of the Dimension
+ * @apiSuccess {Object[]} values Values of the Dimension in name order
+ * @apiSuccess {String} values.id Id of the value
+ * @apiSuccess {String} values.name Name of the value
+ * @apiSuccess {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Department",
+ *       "values": [
+ *         {
+ *           "id": "33333333333333333333333333333333",
+ *           "name": "Sales",
+ *           "inactive": false
+ *         },
+ *         {
+ *           "id": "44444444444444444444444444444444",
+ *           "name": "Support",
+ *           "inactive": false
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetDimension(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	dimensionId := r.PathParam("dimensionId")
+
+	dimension, err := model.Instance.GetDimension(orgId, dimensionId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(dimension)
+}
+
+/**
+ * @api {post} /orgs/:orgId/dimensions Create a Dimension
+ * @apiVersion 1.5.0
+ * @apiName PostDimension
+ * @apiGroup Dimension
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Dimensions such as Department or Project classify splits
+ * independently of the account tree. Each split can have one value of each
+ * Dimension and reports can be filtered or pivoted by those values.
+ *
+ * @apiParam {String} id Id 32 character hex string
+ * @apiParam {String} name Name of the Dimension
+ * @apiParam {Object[]} values Values of the Dimension
+ * @apiParam {String} values.id Id 32 character hex string
+ * @apiParam {String} values.name Name of the value
+ * @apiParam {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccess {String} id Id of the Dimension.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Dimension was created
+ * @apiSuccess {Date} updated Date Dimension was updated
+ * @apiSuccess {String} name Name of the Dimension
+ * @apiSuccess {Object[]} values Values of the Dimension in name order
+ * @apiSuccess {String} values.id Id of the value
+ * @apiSuccess {String} values.name Name of the value
+ * @apiSuccess {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Department",
+ *       "values": [
+ *         {
+ *           "id": "33333333333333333333333333333333",
+ *           "name": "Sales",
+ *           "inactive": false
+ *         },
+ *         {
+ *           "id": "44444444444444444444444444444444",
+ *           "name": "Support",
+ *           "inactive": false
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostDimension(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	dimension := types.Dimension{}
+	err := r.DecodeJsonPayload(&dimension)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	dimension.OrgId = orgId
+
+	err = model.Instance.CreateDimension(&dimension, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&dimension)
+}
+
+/**
+ * @api {put} /orgs/:orgId/dimensions/:dimensionId Modify a Dimension
+ * @apiVersion 1.5.0
+ * @apiName PutDimension
+ * @apiGroup Dimension
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription values replaces the existing values. Values used by splits
+ * cannot be left out but can be made inactive.
+ *
+ * @apiParam {String} name Name of the Dimension
+ * @apiParam {Object[]} values Values of the Dimension
+ * @apiParam {String} values.id Id 32 character hex string
+ * @apiParam {String} values.name Name of the value
+ * @apiParam {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccess {String} id Id of the Dimension.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Dimension was created
+ * @apiSuccess {Date} updated Date Dimension was updated
+ * @apiSuccess {String} name Name of the Dimension
+ * @apiSuccess {Object[]} values Values of the Dimension in name order
+ * @apiSuccess {String} values.id Id of the value
+ * @apiSuccess {String} values.name Name of the value
+ * @apiSuccess {Boolean} values.inactive True if the value is no longer used
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Department",
+ *       "values": [
+ *         {
+ *           "id": "33333333333333333333333333333333",
+ *           "name": "Sales",
+ *           "inactive": false
+ *         },
+ *         {
+ *           "id": "44444444444444444444444444444444",
+ *           "name": "Support",
+ *           "inactive": false
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutDimension(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	dimensionId := r.PathParam("dimensionId")
+
+	dimension := types.Dimension{}
+	err := r.DecodeJsonPayload(&dimension)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	dimension.Id = dimensionId
+	dimension.OrgId = orgId
+
+	err = model.Instance.UpdateDimension(&dimension, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&dimension)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/dimensions/:dimensionId Delete a Dimension
+ * @apiVersion 1.5.0
+ * @apiName DeleteDimension
+ * @apiGroup Dimension
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Only Dimensions whose values are not used by any split can
+ * be deleted.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteDimension(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	dimensionId := r.PathParam("dimensionId")
+
+	err := model.Instance.DeleteDimension(orgId, dimensionId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
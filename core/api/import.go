@@ -0,0 +1,377 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/accounts/:accountId/csv-mapping Get the CSV Mapping of an Account
+ * @apiVersion 1.5.0
+ * @apiName GetCsvMapping
+ * @apiGroup Import
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} accountId Id of the Account statements are imported into.
+ * @apiSuccess {Date} inserted Date CSV Mapping was first saved
+ * @apiSuccess {Date} updated Date CSV Mapping was last saved
+ * @apiSuccess {String} delimiter Character between columns
+ * @apiSuccess {Number} headerRows Number of rows to skip at the top of the file
+ * @apiSuccess {Number} dateColumn Column of the date. Columns are numbered from 1.
+ * @apiSuccess {String} dateFormat Format of the date using YYYY, YY, MM, M, DD and D
+ * @apiSuccess {Number} amountColumn Column of the amount, or of inflows if outflowColumn is set
+ * @apiSuccess {Number} outflowColumn Column of outflows. 0 if amounts are in a single column.
+ * @apiSuccess {String} amountSign "inflow" if positive amounts are money coming into the account or "outflow" if they are money going out
+ * @apiSuccess {String} decimalSeparator "." or ","
+ * @apiSuccess {Number} descriptionColumn Column of the description. 0 if not used.
+ * @apiSuccess {Number} referenceColumn Column of the bank's unique reference. 0 if not used.
+ * @apiSuccess {Number} categoryColumn Column containing the name of the Account to balance against. 0 if not used.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "accountId": "22222222222222222222222222222222",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "delimiter": ",",
+ *       "headerRows": 1,
+ *       "dateColumn": 1,
+ *       "dateFormat": "MM/DD/YYYY",
+ *       "amountColumn": 3,
+ *       "outflowColumn": 0,
+ *       "amountSign": "inflow",
+ *       "decimalSeparator": ".",
+ *       "descriptionColumn": 2,
+ *       "referenceColumn": 4,
+ *       "categoryColumn": 0
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetCsvMapping(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	mapping, err := model.Instance.GetCsvMapping(orgId, accountId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(mapping)
+}
+
+/**
+ * @api {put} /orgs/:orgId/accounts/:accountId/csv-mapping Save the CSV Mapping of an Account
+ * @apiVersion 1.5.0
+ * @apiName PutCsvMapping
+ * @apiGroup Import
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} delimiter Character between columns. Defaults to ",".
+ * @apiParam {Number} headerRows Number of rows to skip at the top of the file
+ * @apiParam {Number} dateColumn Column of the date. Columns are numbered from 1.
+ * @apiParam {String} dateFormat Format of the date using YYYY, YY, MM, M, DD and D. Defaults to "YYYY-MM-DD".
+ * @apiParam {Number} amountColumn Column of the amount, or of inflows if outflowColumn is set
+ * @apiParam {Number} outflowColumn Column of outflows. 0 if amounts are in a single column.
+ * @apiParam {String} amountSign "inflow" (default) if positive amounts are money coming into the account or "outflow" if they are money going out
+ * @apiParam {String} decimalSeparator "." (default) or ","
+ * @apiParam {Number} descriptionColumn Column of the description. 0 if not used.
+ * @apiParam {Number} referenceColumn Column of the bank's unique reference. 0 if not used.
+ * @apiParam {Number} categoryColumn Column containing the name of the Account to balance against. 0 if not used.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} accountId Id of the Account statements are imported into.
+ * @apiSuccess {Date} inserted Date CSV Mapping was first saved
+ * @apiSuccess {Date} updated Date CSV Mapping was last saved
+ * @apiSuccess {String} delimiter Character between columns
+ * @apiSuccess {Number} headerRows Number of rows to skip at the top of the file
+ * @apiSuccess {Number} dateColumn Column of the date
+ * @apiSuccess {String} dateFormat Format of the date
+ * @apiSuccess {Number} amountColumn Column of the amount
+ * @apiSuccess {Number} outflowColumn Column of outflows
+ * @apiSuccess {String} amountSign "inflow" or "outflow"
+ * @apiSuccess {String} decimalSeparator "." or ","
+ * @apiSuccess {Number} descriptionColumn Column of the description
+ * @apiSuccess {Number} referenceColumn Column of the bank's unique reference
+ * @apiSuccess {Number} categoryColumn Column containing the name of the Account to balance against
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "accountId": "22222222222222222222222222222222",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "delimiter": ",",
+ *       "headerRows": 1,
+ *       "dateColumn": 1,
+ *       "dateFormat": "MM/DD/YYYY",
+ *       "amountColumn": 3,
+ *       "outflowColumn": 0,
+ *       "amountSign": "inflow",
+ *       "decimalSeparator": ".",
+ *       "descriptionColumn": 2,
+ *       "referenceColumn": 4,
+ *       "categoryColumn": 0
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutCsvMapping(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	mapping := types.CsvMapping{}
+	err := r.DecodeJsonPayload(&mapping)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mapping.OrgId = orgId
+	mapping.AccountId = accountId
+
+	err = model.Instance.SaveCsvMapping(&mapping, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&mapping)
+}
+
+/**
+ * @api {post} /orgs/:orgId/accounts/:accountId/import/csv Import a CSV Bank Statement
+ * @apiVersion 1.5.0
+ * @apiName ImportCsv
+ * @apiGroup Import
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Turns each row of a CSV bank statement into a Transaction
+ * between the Account and the Account of the first matching Rule, or else the
+ * Account named in the category column, or else offsetAccountId, such as a
+ * suspense account. Both Accounts must be in the Org currency. Rows that were already imported into the Account are skipped.
+ * They are matched on the reference column, or on the date, amount and
+ * description if there is no reference. Transactions are returned as drafts
+ * unless create is true.
+ *
+ * @apiParam {String} data Contents of the CSV file
+ * @apiParam {Object} mapping CSV Mapping to use. Defaults to the mapping saved for the Account.
+ * @apiParam {Boolean} saveMapping true to save mapping for the Account
+ * @apiParam {String} offsetAccountId Id of the Account to balance rows without a matching category against
+ * @apiParam {Boolean} create true to create the Transactions. Nothing is created if any Transaction is invalid.
+ * @apiParam {Boolean} allowDuplicates true to import Transactions that look like existing ones
+ *
+ * @apiSuccess {Object[]} transactions Array of Transactions. The split for the Account has the externalId of the row.
+ * @apiSuccess {Object[]} possibleDuplicates Array of Transactions that were held back because they look like existing Transactions listed in their possibleDuplicates. They are only imported when allowDuplicates is true.
+ * @apiSuccess {Number} skipped Number of rows that were already imported
+ * @apiSuccess {Boolean} created true if the Transactions were created
+ * @apiSuccess {Object[]} errors Array of Transaction errors with the index and id of the Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "transactions": [
+ *         {
+ *           "id": "33333333333333333333333333333333",
+ *           "orgId": "11111111111111111111111111111111",
+ *           "userId": "44444444444444444444444444444444",
+ *           "date": "2018-06-08T00:00:00Z",
+ *           "inserted": "0001-01-01T00:00:00Z",
+ *           "updated": "0001-01-01T00:00:00Z",
+ *           "description": "Corner store",
+ *           "data": "",
+ *           "reversalOf": "",
+ *           "autoReverseDate": null,
+ *           "splits": [
+ *             {
+ *               "accountId": "22222222222222222222222222222222",
+ *               "amount": -1250,
+ *               "nativeAmount": -1250,
+ *               "externalId": "A1001"
+ *             },
+ *             {
+ *               "accountId": "55555555555555555555555555555555",
+ *               "amount": 1250,
+ *               "nativeAmount": 1250,
+ *               "externalId": ""
+ *             }
+ *           ],
+ *           "possibleDuplicates": null
+ *         }
+ *       ],
+ *       "possibleDuplicates": [],
+ *       "skipped": 12,
+ *       "created": false,
+ *       "errors": []
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ImportCsv(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	csvImport := types.CsvImport{}
+	err := r.DecodeJsonPayload(&csvImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := model.Instance.ImportCsv(orgId, accountId, user.Id, &csvImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(result)
+}
+
+/**
+ * @api {post} /orgs/:orgId/accounts/:accountId/import/ofx Import an OFX or QFX File
+ * @apiVersion 1.5.0
+ * @apiName ImportOfx
+ * @apiGroup Import
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Works like the CSV import for the bank and credit card
+ * transactions of an OFX or QFX file in either the SGML or XML format. The
+ * FITID of each transaction is saved as the externalId of the split for the
+ * Account and transactions with a FITID that was already imported into the
+ * Account are skipped.
+ *
+ * @apiParam {String} data Contents of the file
+ * @apiParam {String} offsetAccountId Id of the Account to balance the transactions against
+ * @apiParam {Boolean} create true to create the Transactions. Nothing is created if any Transaction is invalid.
+ * @apiParam {Boolean} allowDuplicates true to import Transactions that look like existing ones
+ *
+ * @apiSuccess {Object[]} transactions Array of Transactions
+ * @apiSuccess {Object[]} possibleDuplicates Array of Transactions that were held back because they look like existing Transactions listed in their possibleDuplicates. They are only imported when allowDuplicates is true.
+ * @apiSuccess {Number} skipped Number of transactions that were already imported
+ * @apiSuccess {Boolean} created true if the Transactions were created
+ * @apiSuccess {Object[]} errors Array of Transaction errors with the index and id of the Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "transactions": [],
+ *       "possibleDuplicates": [],
+ *       "skipped": 25,
+ *       "created": false,
+ *       "errors": []
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ImportOfx(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	statementImport := types.StatementImport{}
+	err := r.DecodeJsonPayload(&statementImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := model.Instance.ImportOfx(orgId, accountId, user.Id, &statementImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(result)
+}
+
+/**
+ * @api {post} /orgs/:orgId/accounts/:accountId/import/qif Import a QIF File
+ * @apiVersion 1.5.0
+ * @apiName ImportQif
+ * @apiGroup Import
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Works like the CSV import for the bank, cash and credit card
+ * sections of a QIF file. The category of a transaction is matched against
+ * Account names using the last part of the category, so "Food:Groceries" and
+ * "[Groceries]" both match an Account named Groceries. Split lines are
+ * ignored. QIF has no transaction ids so transactions are matched on their
+ * date, amount and payee when a file is imported again.
+ *
+ * @apiParam {String} data Contents of the file
+ * @apiParam {String} dateFormat Format of the dates using YYYY, YY, MM, M, DD and D. Defaults to "M/D/YYYY".
+ * @apiParam {String} offsetAccountId Id of the Account to balance transactions without a matching category against
+ * @apiParam {Boolean} create true to create the Transactions. Nothing is created if any Transaction is invalid.
+ * @apiParam {Boolean} allowDuplicates true to import Transactions that look like existing ones
+ *
+ * @apiSuccess {Object[]} transactions Array of Transactions
+ * @apiSuccess {Object[]} possibleDuplicates Array of Transactions that were held back because they look like existing Transactions listed in their possibleDuplicates. They are only imported when allowDuplicates is true.
+ * @apiSuccess {Number} skipped Number of transactions that were already imported
+ * @apiSuccess {Boolean} created true if the Transactions were created
+ * @apiSuccess {Object[]} errors Array of Transaction errors with the index and id of the Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "transactions": [],
+ *       "possibleDuplicates": [],
+ *       "skipped": 25,
+ *       "created": false,
+ *       "errors": []
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ImportQif(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	statementImport := types.StatementImport{}
+	err := r.DecodeJsonPayload(&statementImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := model.Instance.ImportQif(orgId, accountId, user.Id, &statementImport)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(result)
+}
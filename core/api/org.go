@@ -23,6 +23,8 @@ import (
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {String} timezone Timezone to use for accounting.
+ * @apiSuccess {Date} lockDate Transactions dated before the lock date cannot be changed. Null if not set.
+ * @apiSuccess {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -33,7 +35,9 @@ import (
  *       "name": "MyOrg",
  *       "currency": "USD",
  *       "precision": 2,
- *       "timezone": "America/New_York"
+ *       "timezone": "America/New_York",
+ *       "lockDate": null,
+ *       "fiscalYearStart": 1
  *     }
  *
  * @apiUse NotAuthorizedError
@@ -69,6 +73,8 @@ func GetOrg(w rest.ResponseWriter, r *rest.Request) {
 * @apiSuccess {String} currency Three letter currency code.
 * @apiSuccess {Number} precision How many digits the currency goes out to.
 @apiSuccess {String} timezone Timezone to use for accounting.
+@apiSuccess {Date} lockDate Transactions dated before the lock date cannot be changed. Null if not set.
+@apiSuccess {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
 *
 * @apiSuccessExample Success-Response:
 *     HTTP/1.1 200 OK
@@ -80,7 +86,9 @@ func GetOrg(w rest.ResponseWriter, r *rest.Request) {
 *         "name": "MyOrg",
 *         "currency": "USD",
 *         "precision": 2,
-*         "timezone": "America/New_York"
+*         "timezone": "America/New_York",
+*         "lockDate": null,
+*         "fiscalYearStart": 1
 *       }
 *     ]
 *
@@ -114,6 +122,7 @@ func GetOrgs(w rest.ResponseWriter, r *rest.Request) {
  * @apiParam {String} currency Three letter currency code.
  * @apiParam {Number} precision How many digits the currency goes out to.
  * @apiParam {String} timezone Timezone to use for accounting.
+ * @apiParam {Number} fiscalYearStart Month (1-12) the fiscal year starts in. Defaults to 1.
  *
  * @apiSuccess {String} id Id of the Org.
  * @apiSuccess {Date} inserted Date Org was created
@@ -122,6 +131,8 @@ func GetOrgs(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {String} timezone Timezone to use for accounting.
+ * @apiSuccess {Date} lockDate Transactions dated before the lock date cannot be changed. Null if not set.
+ * @apiSuccess {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -132,7 +143,9 @@ func GetOrgs(w rest.ResponseWriter, r *rest.Request) {
  *       "name": "MyOrg",
  *       "currency": "USD",
  *       "precision": 2,
- *       "timezone": "America/New_York"
+ *       "timezone": "America/New_York",
+ *       "lockDate": null,
+ *       "fiscalYearStart": 1
  *     }
  *
  * @apiUse NotAuthorizedError
@@ -167,6 +180,8 @@ func PostOrg(w rest.ResponseWriter, r *rest.Request) {
  * @apiHeader {String} Accept-Version ^1.4.0 semver versioning
  *
  * @apiParam {String} name Name of the Org.
+ * @apiParam {String} timezone Timezone to use for accounting.
+ * @apiParam {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
  *
  * @apiSuccess {String} id Id of the Org.
  * @apiSuccess {Date} inserted Date Org was created
@@ -175,6 +190,8 @@ func PostOrg(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {String} currency Three letter currency code.
  * @apiSuccess {Number} precision How many digits the currency goes out to.
  * @apiSuccess {String} timezone Timezone to use for accounting.
+ * @apiSuccess {Date} lockDate Transactions dated before the lock date cannot be changed. Null if not set.
+ * @apiSuccess {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -185,7 +202,9 @@ func PostOrg(w rest.ResponseWriter, r *rest.Request) {
  *       "name": "MyOrg",
  *       "currency": "USD",
  *       "precision": 2,
- *       "timezone": "America/New_York"
+ *       "timezone": "America/New_York",
+ *       "lockDate": null,
+ *       "fiscalYearStart": 1
  *     }
  *
  * @apiUse NotAuthorizedError
@@ -214,6 +233,65 @@ func PutOrg(w rest.ResponseWriter, r *rest.Request) {
 	w.WriteJson(&org)
 }
 
+/**
+ * @api {put} /orgs/:orgId/lock-date Set Org lock date
+ * @apiVersion 1.5.0
+ * @apiName PutLockDate
+ * @apiGroup Org
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {Date} lockDate Transactions dated before this cannot be created, modified or deleted. Null removes the lock. Org admins only.
+ *
+ * @apiSuccess {String} id Id of the Org.
+ * @apiSuccess {Date} inserted Date Org was created
+ * @apiSuccess {Date} updated Date Org was updated
+ * @apiSuccess {String} name Name of the Org.
+ * @apiSuccess {String} currency Three letter currency code.
+ * @apiSuccess {Number} precision How many digits the currency goes out to.
+ * @apiSuccess {String} timezone Timezone to use for accounting.
+ * @apiSuccess {Date} lockDate Transactions dated before the lock date cannot be changed. Null if not set.
+ * @apiSuccess {Number} fiscalYearStart Month (1-12) the fiscal year starts in.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "11111111111111111111111111111111",
+ *       "inserted": "2018-09-11T18:05:04.420Z",
+ *       "updated": "2019-02-01T15:12:44.031Z",
+ *       "name": "MyOrg",
+ *       "currency": "USD",
+ *       "precision": 2,
+ *       "timezone": "America/New_York",
+ *       "lockDate": "2019-01-01T05:00:00.000Z",
+ *       "fiscalYearStart": 1
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutLockDate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	org := types.Org{}
+	err := r.DecodeJsonPayload(&org)
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := model.Instance.UpdateLockDate(orgId, org.LockDate, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(updated)
+}
+
 /**
  * @api {post} /orgs/:orgId/invites Invite a user to an Org
  * @apiVersion 1.4.0
@@ -0,0 +1,219 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/periods Get Accounting Periods
+ * @apiVersion 1.5.0
+ * @apiName GetAccountingPeriods
+ * @apiGroup Period
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Period.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who last closed or reopened the Period.
+ * @apiSuccess {Date} inserted Date Period was created
+ * @apiSuccess {Date} updated Date Period was updated
+ * @apiSuccess {Date} start Start of the Period
+ * @apiSuccess {Date} end End of the Period (exclusive)
+ * @apiSuccess {Boolean} closed True if transactions dated within the Period cannot be changed.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "userId": "33333333333333333333333333333333",
+ *         "inserted": "2019-01-15T18:05:04.420Z",
+ *         "updated": "2019-01-15T18:05:04.420Z",
+ *         "start": "2018-01-01T05:00:00.000Z",
+ *         "end": "2019-01-01T05:00:00.000Z",
+ *         "closed": true
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetAccountingPeriods(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	periods, err := model.Instance.GetAccountingPeriods(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&periods)
+}
+
+/**
+ * @api {post} /orgs/:orgId/periods Create an Accounting Period
+ * @apiVersion 1.5.0
+ * @apiName PostAccountingPeriod
+ * @apiGroup Period
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} id 32 character hex string
+ * @apiParam {Date} start Start of the Period
+ * @apiParam {Date} end End of the Period (exclusive). Periods cannot overlap.
+ * @apiParam {Boolean} closed True to create the Period already closed.
+ *
+ * @apiSuccess {String} id Id of the Period.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who last closed or reopened the Period.
+ * @apiSuccess {Date} inserted Date Period was created
+ * @apiSuccess {Date} updated Date Period was updated
+ * @apiSuccess {Date} start Start of the Period
+ * @apiSuccess {Date} end End of the Period (exclusive)
+ * @apiSuccess {Boolean} closed True if transactions dated within the Period cannot be changed.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2019-01-15T18:05:04.420Z",
+ *       "updated": "2019-01-15T18:05:04.420Z",
+ *       "start": "2018-01-01T05:00:00.000Z",
+ *       "end": "2019-01-01T05:00:00.000Z",
+ *       "closed": false
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostAccountingPeriod(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	period := types.AccountingPeriod{}
+	err := r.DecodeJsonPayload(&period)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	period.OrgId = orgId
+
+	err = model.Instance.CreateAccountingPeriod(&period, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&period)
+}
+
+/**
+ * @api {post} /orgs/:orgId/periods/:periodId/close Close an Accounting Period
+ * @apiVersion 1.5.0
+ * @apiName CloseAccountingPeriod
+ * @apiGroup Period
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Period.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who closed the Period.
+ * @apiSuccess {Date} inserted Date Period was created
+ * @apiSuccess {Date} updated Date Period was closed
+ * @apiSuccess {Date} start Start of the Period
+ * @apiSuccess {Date} end End of the Period (exclusive)
+ * @apiSuccess {Boolean} closed True
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2019-01-15T18:05:04.420Z",
+ *       "updated": "2019-03-01T14:22:31.006Z",
+ *       "start": "2018-01-01T05:00:00.000Z",
+ *       "end": "2019-01-01T05:00:00.000Z",
+ *       "closed": true
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func CloseAccountingPeriod(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	periodId := r.PathParam("periodId")
+
+	period, err := model.Instance.CloseAccountingPeriod(orgId, periodId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(period)
+}
+
+/**
+ * @api {post} /orgs/:orgId/periods/:periodId/reopen Reopen an Accounting Period
+ * @apiVersion 1.5.0
+ * @apiName ReopenAccountingPeriod
+ * @apiGroup Period
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Period.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who reopened the Period.
+ * @apiSuccess {Date} inserted Date Period was created
+ * @apiSuccess {Date} updated Date Period was reopened
+ * @apiSuccess {Date} start Start of the Period
+ * @apiSuccess {Date} end End of the Period (exclusive)
+ * @apiSuccess {Boolean} closed False
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2019-01-15T18:05:04.420Z",
+ *       "updated": "2019-03-02T09:10:11.512Z",
+ *       "start": "2018-01-01T05:00:00.000Z",
+ *       "end": "2019-01-01T05:00:00.000Z",
+ *       "closed": false
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ReopenAccountingPeriod(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	periodId := r.PathParam("periodId")
+
+	period, err := model.Instance.ReopenAccountingPeriod(orgId, periodId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(period)
+}
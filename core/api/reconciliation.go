@@ -0,0 +1,440 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/reconciliations Get Reconciliations
+ * @apiVersion 1.5.0
+ * @apiName GetReconciliations
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "userId": "33333333333333333333333333333333",
+ *         "accountId": "44444444444444444444444444444444",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "statementDate": "2018-05-31T07:00:00.000Z",
+ *         "statementBalance": 152034,
+ *         "finished": false,
+ *         "clearedBalance": 149534,
+ *         "difference": 2500,
+ *         "transactionIds": ["55555555555555555555555555555555"]
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetReconciliations(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reconciliations, err := model.Instance.GetReconciliations(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&reconciliations)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reconciliations/:reconciliationId Get a Reconciliation
+ * @apiVersion 1.5.0
+ * @apiName GetReconciliation
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "accountId": "44444444444444444444444444444444",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "statementDate": "2018-05-31T07:00:00.000Z",
+ *       "statementBalance": 152034,
+ *       "finished": false,
+ *       "clearedBalance": 149534,
+ *       "difference": 2500,
+ *       "transactionIds": ["55555555555555555555555555555555"]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetReconciliation(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	reconciliationId := r.PathParam("reconciliationId")
+
+	reconciliation, err := model.Instance.GetReconciliation(orgId, reconciliationId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(reconciliation)
+}
+
+/**
+ * @api {post} /orgs/:orgId/reconciliations Start a Reconciliation
+ * @apiVersion 1.5.0
+ * @apiName PostReconciliation
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Starts reconciling an Account against a bank statement.
+ * Splits are marked cleared with `PUT /orgs/:orgId/reconciliations/:reconciliationId/splits`
+ * and the Reconciliation is finished once the cleared balance matches the
+ * statement. An Account can only have one open Reconciliation at a time.
+ *
+ * @apiParam {String} id Id 32 character hex string
+ * @apiParam {String} accountId Id of the Account to reconcile
+ * @apiParam {Date} statementDate Date of the statement
+ * @apiParam {Number} statementBalance Ending balance of the statement in Account currency, signed like the Account balance
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "accountId": "44444444444444444444444444444444",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "statementDate": "2018-05-31T07:00:00.000Z",
+ *       "statementBalance": 152034,
+ *       "finished": false,
+ *       "clearedBalance": 149534,
+ *       "difference": 2500,
+ *       "transactionIds": ["55555555555555555555555555555555"]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostReconciliation(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reconciliation := types.Reconciliation{}
+	err := r.DecodeJsonPayload(&reconciliation)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reconciliation.OrgId = orgId
+	reconciliation.UserId = user.Id
+
+	err = model.Instance.CreateReconciliation(&reconciliation)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&reconciliation)
+}
+
+/**
+ * @api {put} /orgs/:orgId/reconciliations/:reconciliationId Modify a Reconciliation
+ * @apiVersion 1.5.0
+ * @apiName PutReconciliation
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Changes the statement of an open Reconciliation.
+ *
+ * @apiParam {Date} statementDate Date of the statement
+ * @apiParam {Number} statementBalance Ending balance of the statement in Account currency, signed like the Account balance
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "accountId": "44444444444444444444444444444444",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "statementDate": "2018-05-31T07:00:00.000Z",
+ *       "statementBalance": 152034,
+ *       "finished": false,
+ *       "clearedBalance": 149534,
+ *       "difference": 2500,
+ *       "transactionIds": ["55555555555555555555555555555555"]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutReconciliation(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	reconciliationId := r.PathParam("reconciliationId")
+
+	reconciliation := types.Reconciliation{}
+	err := r.DecodeJsonPayload(&reconciliation)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reconciliation.Id = reconciliationId
+	reconciliation.OrgId = orgId
+	reconciliation.UserId = user.Id
+
+	err = model.Instance.UpdateReconciliation(&reconciliation)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&reconciliation)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/reconciliations/:reconciliationId Delete a Reconciliation
+ * @apiVersion 1.5.0
+ * @apiName DeleteReconciliation
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Abandons an open Reconciliation. Cleared splits stay cleared.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteReconciliation(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	reconciliationId := r.PathParam("reconciliationId")
+
+	err := model.Instance.DeleteReconciliation(orgId, reconciliationId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ * @api {put} /orgs/:orgId/reconciliations/:reconciliationId/splits Clear Reconciliation Splits
+ * @apiVersion 1.5.0
+ * @apiName PutReconciliationSplits
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Marks the splits of Transactions in the Account of an open
+ * Reconciliation as cleared or uncleared. Transactions keep their ids.
+ *
+ * @apiParam {String[]} transactionIds Ids of Transactions with a split in the Account
+ * @apiParam {String} status cleared or uncleared
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "accountId": "44444444444444444444444444444444",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "statementDate": "2018-05-31T07:00:00.000Z",
+ *       "statementBalance": 152034,
+ *       "finished": false,
+ *       "clearedBalance": 149534,
+ *       "difference": 2500,
+ *       "transactionIds": ["55555555555555555555555555555555"]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutReconciliationSplits(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	reconciliationId := r.PathParam("reconciliationId")
+
+	splits := types.ReconciliationSplits{}
+	err := r.DecodeJsonPayload(&splits)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	reconciliation, err := model.Instance.SetReconciliationSplits(orgId, reconciliationId, user.Id, &splits)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(reconciliation)
+}
+
+/**
+ * @api {post} /orgs/:orgId/reconciliations/:reconciliationId/finish Finish a Reconciliation
+ * @apiVersion 1.5.0
+ * @apiName FinishReconciliation
+ * @apiGroup Reconciliation
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Marks the cleared splits of the Account dated before
+ * statementDate reconciled. Later cleared splits stay cleared. The
+ * difference between the statement balance and the cleared balance must be 0.
+ * Reconciled splits cannot be changed and their Transactions cannot be
+ * deleted or moved to another date.
+ *
+ * @apiSuccess {String} id Id of the Reconciliation.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who started the Reconciliation.
+ * @apiSuccess {String} accountId Id of the Account being reconciled
+ * @apiSuccess {Date} inserted Date Reconciliation was created
+ * @apiSuccess {Date} updated Date Reconciliation was updated
+ * @apiSuccess {Date} statementDate Date of the statement
+ * @apiSuccess {Number} statementBalance Ending balance of the statement in Account currency
+ * @apiSuccess {Boolean} finished True once the cleared splits have been reconciled
+ * @apiSuccess {Number} clearedBalance Sum of the cleared and reconciled splits of the Account dated before statementDate
+ * @apiSuccess {Number} difference statementBalance minus clearedBalance. Must be 0 to finish.
+ * @apiSuccess {String[]} transactionIds Ids of the Transactions with a cleared split in the Account dated before statementDate. Empty once finished.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "accountId": "44444444444444444444444444444444",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "statementDate": "2018-05-31T07:00:00.000Z",
+ *       "statementBalance": 152034,
+ *       "finished": true,
+ *       "clearedBalance": 152034,
+ *       "difference": 0,
+ *       "transactionIds": []
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func FinishReconciliation(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	reconciliationId := r.PathParam("reconciliationId")
+
+	reconciliation, err := model.Instance.FinishReconciliation(orgId, reconciliationId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(reconciliation)
+}
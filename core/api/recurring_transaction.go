@@ -0,0 +1,390 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/recurring-transactions Get Recurring Transactions
+ * @apiVersion 1.5.0
+ * @apiName GetRecurringTransactions
+ * @apiGroup RecurringTransaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Recurring Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User whose permissions are used to create Transactions.
+ * @apiSuccess {Date} inserted Date Recurring Transaction was created
+ * @apiSuccess {Date} updated Date Recurring Transaction was last updated
+ * @apiSuccess {String} description Description of the Transactions
+ * @apiSuccess {String} data Extra data field of the Transactions
+ * @apiSuccess {String} rule Schedule in a subset of iCalendar RRULE syntax
+ * @apiSuccess {Date} start Date of the first possible occurrence
+ * @apiSuccess {Date} end Date of the last possible occurrence. Null if never ending.
+ * @apiSuccess {Date} lastRun Date of the last Transaction created. Null if none yet.
+ * @apiSuccess {Date} nextRun Date of the next Transaction. Null if finished.
+ * @apiSuccess {String} lastError Why the last skipped occurrence could not be created. An occurrence
+ *   that still fails a day after its date is skipped. Cleared when the Recurring Transaction is updated.
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "userId": "33333333333333333333333333333333",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "description": "Rent",
+ *         "data": "",
+ *         "rule": "FREQ=MONTHLY;BYMONTHDAY=1",
+ *         "start": "2018-07-01T04:00:00.000Z",
+ *         "end": null,
+ *         "lastRun": null,
+ *         "nextRun": "2018-07-01T04:00:00.000Z",
+ *         "lastError": "",
+ *         "splits": [
+ *           {
+ *             "accountId": "44444444444444444444444444444444",
+ *             "amount": 150000,
+ *             "nativeAmount": 150000
+ *           },
+ *           {
+ *             "accountId": "55555555555555555555555555555555",
+ *             "amount": -150000,
+ *             "nativeAmount": -150000
+ *           }
+ *         ]
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetRecurringTransactions(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	recurrings, err := model.Instance.GetRecurringTransactions(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&recurrings)
+}
+
+/**
+ * @api {get} /orgs/:orgId/recurring-transactions/:recurringTransactionId Get a Recurring Transaction
+ * @apiVersion 1.5.0
+ * @apiName GetRecurringTransaction
+ * @apiGroup RecurringTransaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Recurring Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User whose permissions are used to create Transactions.
+ * @apiSuccess {Date} inserted Date Recurring Transaction was created
+ * @apiSuccess {Date} updated Date Recurring Transaction was last updated
+ * @apiSuccess {String} description Description of the Transactions
+ * @apiSuccess {String} data Extra data field of the Transactions
+ * @apiSuccess {String} rule Schedule in a subset of iCalendar RRULE syntax
+ * @apiSuccess {Date} start Date of the first possible occurrence
+ * @apiSuccess {Date} end Date of the last possible occurrence. Null if never ending.
+ * @apiSuccess {Date} lastRun Date of the last Transaction created. Null if none yet.
+ * @apiSuccess {Date} nextRun Date of the next Transaction. Null if finished.
+ * @apiSuccess {String} lastError Why the last skipped occurrence could not be created. An occurrence
+ *   that still fails a day after its date is skipped. Cleared when the Recurring Transaction is updated.
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "description": "Rent",
+ *       "data": "",
+ *       "rule": "FREQ=MONTHLY;BYMONTHDAY=1",
+ *       "start": "2018-07-01T04:00:00.000Z",
+ *       "end": null,
+ *       "lastRun": null,
+ *       "nextRun": "2018-07-01T04:00:00.000Z",
+ *       "lastError": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": 150000,
+ *           "nativeAmount": 150000
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": -150000,
+ *           "nativeAmount": -150000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetRecurringTransaction(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	recurringTransactionId := r.PathParam("recurringTransactionId")
+
+	recurring, err := model.Instance.GetRecurringTransaction(orgId, recurringTransactionId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(recurring)
+}
+
+/**
+ * @api {post} /orgs/:orgId/recurring-transactions Create a Recurring Transaction
+ * @apiVersion 1.5.0
+ * @apiName PostRecurringTransaction
+ * @apiGroup RecurringTransaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Transactions are created in the background for every
+ * occurrence that is due, including occurrences between start and now.
+ *
+ * @apiParam {String} id 32 character hex string
+ * @apiParam {String} description Description of the Transactions
+ * @apiParam {String} data Extra data field of the Transactions
+ * @apiParam {String} rule Schedule in a subset of iCalendar RRULE syntax. FREQ is
+ *   DAILY, WEEKLY, MONTHLY or YEARLY. INTERVAL repeats every n periods. BYDAY
+ *   (e.g. MO,FR) picks days of the week for WEEKLY. BYMONTHDAY picks the day of
+ *   the month for MONTHLY, -1 for the last day. Examples: "FREQ=DAILY",
+ *   "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR", "FREQ=MONTHLY;BYMONTHDAY=1".
+ * @apiParam {Date} start Date of the first possible occurrence. Also sets the time of day and default day of the week or month.
+ * @apiParam {Date} end Optional date of the last possible occurrence
+ * @apiParam {Object[]} splits Array of Transaction Splits. nativeAmounts must add up to 0.
+ * @apiParam {String} splits.accountId Id of Account
+ * @apiParam {Number} splits.amount Amount of split in Account currency
+ * @apiParam {Number} splits.nativeAmount Amount of split in Org currency
+ *
+ * @apiSuccess {String} id Id of the Recurring Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User whose permissions are used to create Transactions.
+ * @apiSuccess {Date} inserted Date Recurring Transaction was created
+ * @apiSuccess {Date} updated Date Recurring Transaction was last updated
+ * @apiSuccess {String} description Description of the Transactions
+ * @apiSuccess {String} data Extra data field of the Transactions
+ * @apiSuccess {String} rule Schedule in a subset of iCalendar RRULE syntax
+ * @apiSuccess {Date} start Date of the first possible occurrence
+ * @apiSuccess {Date} end Date of the last possible occurrence. Null if never ending.
+ * @apiSuccess {Date} lastRun Date of the last Transaction created. Null if none yet.
+ * @apiSuccess {Date} nextRun Date of the next Transaction. Null if finished.
+ * @apiSuccess {String} lastError Why the last skipped occurrence could not be created. An occurrence
+ *   that still fails a day after its date is skipped. Cleared when the Recurring Transaction is updated.
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "description": "Rent",
+ *       "data": "",
+ *       "rule": "FREQ=MONTHLY;BYMONTHDAY=1",
+ *       "start": "2018-07-01T04:00:00.000Z",
+ *       "end": null,
+ *       "lastRun": null,
+ *       "nextRun": "2018-07-01T04:00:00.000Z",
+ *       "lastError": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": 150000,
+ *           "nativeAmount": 150000
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": -150000,
+ *           "nativeAmount": -150000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostRecurringTransaction(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	recurring := types.RecurringTransaction{}
+	err := r.DecodeJsonPayload(&recurring)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recurring.OrgId = orgId
+	recurring.UserId = user.Id
+
+	err = model.Instance.CreateRecurringTransaction(&recurring)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&recurring)
+}
+
+/**
+ * @api {put} /orgs/:orgId/recurring-transactions/:recurringTransactionId Modify a Recurring Transaction
+ * @apiVersion 1.5.0
+ * @apiName PutRecurringTransaction
+ * @apiGroup RecurringTransaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Transactions that were already created are not changed. The
+ * next run is the first occurrence after the day of the last run.
+ *
+ * @apiParam {String} description Description of the Transactions
+ * @apiParam {String} data Extra data field of the Transactions
+ * @apiParam {String} rule Schedule in a subset of iCalendar RRULE syntax. FREQ is
+ *   DAILY, WEEKLY, MONTHLY or YEARLY. INTERVAL repeats every n periods. BYDAY
+ *   (e.g. MO,FR) picks days of the week for WEEKLY. BYMONTHDAY picks the day of
+ *   the month for MONTHLY, -1 for the last day. Examples: "FREQ=DAILY",
+ *   "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR", "FREQ=MONTHLY;BYMONTHDAY=1".
+ * @apiParam {Date} start Date of the first possible occurrence. Also sets the time of day and default day of the week or month.
+ * @apiParam {Date} end Optional date of the last possible occurrence
+ * @apiParam {Object[]} splits Array of Transaction Splits. nativeAmounts must add up to 0.
+ * @apiParam {String} splits.accountId Id of Account
+ * @apiParam {Number} splits.amount Amount of split in Account currency
+ * @apiParam {Number} splits.nativeAmount Amount of split in Org currency
+ *
+ * @apiSuccess {String} id Id of the Recurring Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User whose permissions are used to create Transactions.
+ * @apiSuccess {Date} inserted Date Recurring Transaction was created
+ * @apiSuccess {Date} updated Date Recurring Transaction was last updated
+ * @apiSuccess {String} description Description of the Transactions
+ * @apiSuccess {String} data Extra data field of the Transactions
+ * @apiSuccess {String} rule Schedule in a subset of iCalendar RRULE syntax
+ * @apiSuccess {Date} start Date of the first possible occurrence
+ * @apiSuccess {Date} end Date of the last possible occurrence. Null if never ending.
+ * @apiSuccess {Date} lastRun Date of the last Transaction created. Null if none yet.
+ * @apiSuccess {Date} nextRun Date of the next Transaction. Null if finished.
+ * @apiSuccess {String} lastError Why the last skipped occurrence could not be created. An occurrence
+ *   that still fails a day after its date is skipped. Cleared when the Recurring Transaction is updated.
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "33333333333333333333333333333333",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "description": "Rent",
+ *       "data": "",
+ *       "rule": "FREQ=MONTHLY;BYMONTHDAY=1",
+ *       "start": "2018-07-01T04:00:00.000Z",
+ *       "end": null,
+ *       "lastRun": null,
+ *       "nextRun": "2018-07-01T04:00:00.000Z",
+ *       "lastError": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": 150000,
+ *           "nativeAmount": 150000
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": -150000,
+ *           "nativeAmount": -150000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutRecurringTransaction(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	recurringTransactionId := r.PathParam("recurringTransactionId")
+
+	recurring := types.RecurringTransaction{}
+	err := r.DecodeJsonPayload(&recurring)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recurring.Id = recurringTransactionId
+	recurring.OrgId = orgId
+	recurring.UserId = user.Id
+
+	err = model.Instance.UpdateRecurringTransaction(&recurring)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&recurring)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/recurring-transactions/:recurringTransactionId Delete a Recurring Transaction
+ * @apiVersion 1.5.0
+ * @apiName DeleteRecurringTransaction
+ * @apiGroup RecurringTransaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Transactions that were already created are kept.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteRecurringTransaction(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	recurringTransactionId := r.PathParam("recurringTransactionId")
+
+	err := model.Instance.DeleteRecurringTransaction(orgId, recurringTransactionId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,751 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+)
+
+/**
+ * @api {get} /orgs/:orgId/reports/trial-balance Get Trial Balance
+ * @apiVersion 1.5.0
+ * @apiName GetTrialBalance
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {Number} date Milliseconds since epoch. Balances include transactions before this date.
+ * @apiUse ReportPeriodParams
+ * @apiUse ReportDimensionParams
+ * @apiDescription Returns one column per period with balances as of the end of the period. Use date instead for a single column.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date[]} dates Date of each column
+ * @apiSuccess {Object[]} lines Array of Accounts in tree order. Parent Accounts include their children.
+ * @apiSuccess {String} lines.accountId Id of the Account.
+ * @apiSuccess {String} lines.name Name of the Account.
+ * @apiSuccess {String} lines.parent Id of the parent Account.
+ * @apiSuccess {Number} lines.depth Depth of the Account in the tree.
+ * @apiSuccess {String} lines.currency Three letter currency code.
+ * @apiSuccess {Number} lines.precision How many digits the currency goes out to.
+ * @apiSuccess {Boolean} lines.debitBalance True if Account has a debit balance.
+ * @apiSuccess {Boolean} lines.leaf True if Account has no children.
+ * @apiSuccess {Object[]} lines.columns Balances for each date
+ * @apiSuccess {Number} lines.columns.debit Debit balance in this Account's currency
+ * @apiSuccess {Number} lines.columns.credit Credit balance in this Account's currency
+ * @apiSuccess {Number} lines.columns.nativeDebit Debit balance in the Org's currency
+ * @apiSuccess {Number} lines.columns.nativeCredit Credit balance in the Org's currency
+ * @apiSuccess {Number[]} nativeDebitTotals Total of leaf Account debits in the Org's currency for each date
+ * @apiSuccess {Number[]} nativeCreditTotals Total of leaf Account credits in the Org's currency for each date
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "dates": ["2018-09-11T18:05:04.420Z"],
+ *       "lines": [
+ *         {
+ *           "accountId": "22222222222222222222222222222222",
+ *           "name": "Assets",
+ *           "parent": "11111111111111111111111111111111",
+ *           "depth": 1,
+ *           "currency": "USD",
+ *           "precision": 2,
+ *           "debitBalance": true,
+ *           "leaf": false,
+ *           "columns": [
+ *             {
+ *               "debit": 10000,
+ *               "credit": 0,
+ *               "nativeDebit": 10000,
+ *               "nativeCredit": 0
+ *             }
+ *           ]
+ *         }
+ *       ],
+ *       "nativeDebitTotals": [10000],
+ *       "nativeCreditTotals": [10000]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetTrialBalance(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	trialBalance, err := model.Instance.GetTrialBalance(orgId, user.Id, reportOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(trialBalance)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reports/income-statement Get Income Statement
+ * @apiVersion 1.5.0
+ * @apiName GetIncomeStatement
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiUse ReportPeriodParams
+ * @apiUse ReportDimensionParams
+ * @apiDescription Returns one column per period. For example interval=month with a year from start to end gives monthly columns and periods=2018-01-01..2018-12-31,2017-01-01..2017-12-31 compares this year with last year.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Object[]} periods Period of each column
+ * @apiSuccess {Date} periods.start Start of the period
+ * @apiSuccess {Date} periods.end End of the period (exclusive)
+ * @apiSuccess {Object} income Income section
+ * @apiSuccess {Object[]} income.lines Income Accounts in tree order. Parent Accounts include their children.
+ * @apiSuccess {Number[]} income.lines.amounts Amount for each period in the Account's currency
+ * @apiSuccess {Number[]} income.lines.nativeAmounts Amount for each period in the Org's currency
+ * @apiSuccess {Number[]} income.totals Total income for each period in the Org's currency
+ * @apiSuccess {Object} expenses Expenses section
+ * @apiSuccess {Object[]} expenses.lines Expense Accounts in tree order. Parent Accounts include their children.
+ * @apiSuccess {Number[]} expenses.totals Total expenses for each period in the Org's currency
+ * @apiSuccess {Number[]} netIncome Income minus expenses for each period in the Org's currency
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "periods": [
+ *         {
+ *           "start": "2018-01-01T00:00:00Z",
+ *           "end": "2019-01-01T00:00:00Z"
+ *         }
+ *       ],
+ *       "income": {
+ *         "lines": [
+ *           {
+ *             "accountId": "22222222222222222222222222222222",
+ *             "name": "Income",
+ *             "parent": "11111111111111111111111111111111",
+ *             "depth": 0,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": false,
+ *             "amounts": [50000],
+ *             "nativeAmounts": [50000]
+ *           }
+ *         ],
+ *         "totals": [50000]
+ *       },
+ *       "expenses": {
+ *         "lines": [
+ *           {
+ *             "accountId": "33333333333333333333333333333333",
+ *             "name": "Expenses",
+ *             "parent": "11111111111111111111111111111111",
+ *             "depth": 0,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": true,
+ *             "amounts": [20000],
+ *             "nativeAmounts": [20000]
+ *           }
+ *         ],
+ *         "totals": [20000]
+ *       },
+ *       "netIncome": [30000]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetIncomeStatement(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	incomeStatement, err := model.Instance.GetIncomeStatement(orgId, user.Id, reportOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(incomeStatement)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reports/balance-sheet Get Balance Sheet
+ * @apiVersion 1.5.0
+ * @apiName GetBalanceSheet
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {Number} date Milliseconds since epoch. Defaults to now.
+ * @apiUse ReportPeriodParams
+ * @apiParam {String} valuation "cost" (default) or "market" to value other currencies at the price nearest to each date
+ * @apiUse ReportDimensionParams
+ * @apiDescription Returns one column per period with balances as of the end of the period. Use date instead for a single column.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date[]} dates Date of each column
+ * @apiSuccess {String} valuation Valuation used for native amounts
+ * @apiSuccess {Object} assets Assets section
+ * @apiSuccess {Object} liabilities Liabilities section
+ * @apiSuccess {Object} equity Equity section. Includes computed Retained Earnings, Current Year Earnings and, for market valuation, Unrealized Gains lines without an accountId.
+ * @apiSuccess {Number[]} totalLiabilitiesAndEquity Liabilities plus Equity in the Org's currency for each date
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "dates": ["2018-09-11T18:05:04.420Z"],
+ *       "valuation": "cost",
+ *       "assets": {
+ *         "lines": [
+ *           {
+ *             "accountId": "22222222222222222222222222222222",
+ *             "name": "Assets",
+ *             "parent": "11111111111111111111111111111111",
+ *             "depth": 0,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": true,
+ *             "amounts": [30000],
+ *             "nativeAmounts": [30000]
+ *           }
+ *         ],
+ *         "totals": [30000]
+ *       },
+ *       "liabilities": {
+ *         "lines": [
+ *           {
+ *             "accountId": "33333333333333333333333333333333",
+ *             "name": "Liabilities",
+ *             "parent": "11111111111111111111111111111111",
+ *             "depth": 0,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": false,
+ *             "amounts": [0],
+ *             "nativeAmounts": [0]
+ *           }
+ *         ],
+ *         "totals": [0]
+ *       },
+ *       "equity": {
+ *         "lines": [
+ *           {
+ *             "accountId": "44444444444444444444444444444444",
+ *             "name": "Equity",
+ *             "parent": "11111111111111111111111111111111",
+ *             "depth": 0,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": false,
+ *             "amounts": [30000],
+ *             "nativeAmounts": [30000]
+ *           },
+ *           {
+ *             "accountId": "",
+ *             "name": "Retained Earnings",
+ *             "parent": "44444444444444444444444444444444",
+ *             "depth": 1,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": false,
+ *             "amounts": [10000],
+ *             "nativeAmounts": [10000]
+ *           },
+ *           {
+ *             "accountId": "",
+ *             "name": "Current Year Earnings",
+ *             "parent": "44444444444444444444444444444444",
+ *             "depth": 1,
+ *             "currency": "USD",
+ *             "precision": 2,
+ *             "debitBalance": false,
+ *             "amounts": [20000],
+ *             "nativeAmounts": [20000]
+ *           }
+ *         ],
+ *         "totals": [30000]
+ *       },
+ *       "totalLiabilitiesAndEquity": [30000]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetBalanceSheet(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	valuation := r.URL.Query().Get("valuation")
+
+	balanceSheet, err := model.Instance.GetBalanceSheet(orgId, user.Id, reportOptions, valuation)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(balanceSheet)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reports/cash-flow Get Cash Flow Statement
+ * @apiVersion 1.5.0
+ * @apiName GetCashFlowStatement
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiUse ReportPeriodParams
+ * @apiDescription Direct method cash flow statement. Cash accounts are those marked cash along with their descendants. Cash received or paid is listed by the other Accounts in each transaction. Income and Expenses are operating activities, Assets are investing activities and Liabilities and Equity are financing activities. Accounts the user cannot access are combined under other.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Object[]} periods Period of each column
+ * @apiSuccess {Date} periods.start Start of the period
+ * @apiSuccess {Date} periods.end End of the period (exclusive)
+ * @apiSuccess {Number[]} openingCash Cash at the start of each period in the Org's currency
+ * @apiSuccess {Object} operating Operating activities
+ * @apiSuccess {Object[]} operating.lines Cash received (positive) or paid (negative) per Account
+ * @apiSuccess {String} operating.lines.accountId Id of the Account.
+ * @apiSuccess {String} operating.lines.name Name of the Account.
+ * @apiSuccess {Number[]} operating.lines.nativeAmounts Amount for each period in the Org's currency
+ * @apiSuccess {Number[]} operating.totals Total for each period in the Org's currency
+ * @apiSuccess {Object} investing Investing activities
+ * @apiSuccess {Object} financing Financing activities
+ * @apiSuccess {Object} other Cash flows involving Accounts the user cannot access
+ * @apiSuccess {Number[]} netChange Net change in cash for each period in the Org's currency
+ * @apiSuccess {Number[]} closingCash Cash at the end of each period in the Org's currency
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "periods": [
+ *         {
+ *           "start": "2018-01-01T00:00:00Z",
+ *           "end": "2019-01-01T00:00:00Z"
+ *         }
+ *       ],
+ *       "openingCash": [10000],
+ *       "operating": {
+ *         "lines": [
+ *           {
+ *             "accountId": "22222222222222222222222222222222",
+ *             "name": "Groceries",
+ *             "nativeAmounts": [-20000]
+ *           },
+ *           {
+ *             "accountId": "33333333333333333333333333333333",
+ *             "name": "Salary",
+ *             "nativeAmounts": [50000]
+ *           }
+ *         ],
+ *         "totals": [30000]
+ *       },
+ *       "investing": {
+ *         "lines": [],
+ *         "totals": [0]
+ *       },
+ *       "financing": {
+ *         "lines": [],
+ *         "totals": [0]
+ *       },
+ *       "other": {
+ *         "lines": [],
+ *         "totals": [0]
+ *       },
+ *       "netChange": [30000],
+ *       "closingCash": [40000]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetCashFlowStatement(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	cashFlowStatement, err := model.Instance.GetCashFlowStatement(orgId, user.Id, reportOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(cashFlowStatement)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reports/tags Get Tag Report
+ * @apiVersion 1.5.0
+ * @apiName GetTagReport
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiUse ReportPeriodParams
+ * @apiDescription Income and expenses of tagged Transactions, such as the costs of a trip or an event. A Transaction with several tags counts towards each of them. Only Accounts the user can access are included.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Object[]} periods Period of each column
+ * @apiSuccess {Date} periods.start Start of the period
+ * @apiSuccess {Date} periods.end End of the period (exclusive)
+ * @apiSuccess {Object[]} lines One line per tag sorted by tag
+ * @apiSuccess {String} lines.tag The tag
+ * @apiSuccess {Number[]} lines.income Income for each period in the Org's currency
+ * @apiSuccess {Number[]} lines.expenses Expenses for each period in the Org's currency
+ * @apiSuccess {Number[]} lines.netIncome Income minus expenses for each period in the Org's currency
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "periods": [
+ *         {
+ *           "start": "2018-01-01T00:00:00Z",
+ *           "end": "2019-01-01T00:00:00Z"
+ *         }
+ *       ],
+ *       "lines": [
+ *         {
+ *           "tag": "conference 2018",
+ *           "income": [150000],
+ *           "expenses": [98000],
+ *           "netIncome": [52000]
+ *         },
+ *         {
+ *           "tag": "paris trip",
+ *           "income": [0],
+ *           "expenses": [240000],
+ *           "netIncome": [-240000]
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetTagReport(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	tagReport, err := model.Instance.GetTagReport(orgId, user.Id, reportOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(tagReport)
+}
+
+/**
+ * @api {get} /orgs/:orgId/reports/budget-variance Get Budget Variance
+ * @apiVersion 1.5.0
+ * @apiName GetBudgetVariance
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} budgetId Id of the named Budget to compare against
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} budgetId Id of the Budget.
+ * @apiSuccess {String} name Name of the Budget.
+ * @apiSuccess {Object[]} periods One entry per Budget period
+ * @apiSuccess {Date} periods.start Start of the period
+ * @apiSuccess {Date} periods.end End of the period (exclusive)
+ * @apiSuccess {Object[]} lines Budgeted Accounts and their ancestors in tree order
+ * @apiSuccess {String} lines.accountId Id of the Account.
+ * @apiSuccess {String} lines.name Name of the Account.
+ * @apiSuccess {String} lines.parent Id of the parent Account.
+ * @apiSuccess {Number} lines.depth Depth below the top level Account
+ * @apiSuccess {Boolean} lines.debitBalance True if Account has a debit balance.
+ * @apiSuccess {Object[]} lines.columns One column per period in the Org's currency including descendants
+ * @apiSuccess {Number} lines.columns.actual Actual amount, positive on the Account's normal side
+ * @apiSuccess {Number} lines.columns.budget Budgeted amount
+ * @apiSuccess {Number} lines.columns.variance Actual minus budget
+ * @apiSuccess {Number} lines.columns.percent Variance as a percentage of budget or null if nothing was budgeted
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "budgetId": "44444444444444444444444444444444",
+ *       "name": "FY2026",
+ *       "periods": [
+ *         {
+ *           "start": "2026-01-01T05:00:00Z",
+ *           "end": "2026-02-01T05:00:00Z"
+ *         }
+ *       ],
+ *       "lines": [
+ *         {
+ *           "accountId": "22222222222222222222222222222222",
+ *           "name": "Expenses",
+ *           "parent": "11111111111111111111111111111111",
+ *           "depth": 0,
+ *           "debitBalance": true,
+ *           "columns": [
+ *             {
+ *               "actual": 50000,
+ *               "budget": 40000,
+ *               "variance": 10000,
+ *               "percent": 25
+ *             }
+ *           ]
+ *         },
+ *         {
+ *           "accountId": "33333333333333333333333333333333",
+ *           "name": "Groceries",
+ *           "parent": "22222222222222222222222222222222",
+ *           "depth": 1,
+ *           "debitBalance": true,
+ *           "columns": [
+ *             {
+ *               "actual": 50000,
+ *               "budget": 40000,
+ *               "variance": 10000,
+ *               "percent": 25
+ *             }
+ *           ]
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetBudgetVariance(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	budgetId := r.URL.Query().Get("budgetId")
+
+	if budgetId == "" {
+		rest.Error(w, "budgetId required", 400)
+		return
+	}
+
+	budgetVariance, err := model.Instance.GetBudgetVariance(orgId, user.Id, budgetId)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(budgetVariance)
+}
+
+/**
+ * @api {get} /orgs/:orgId/accounts/:accountId/general-ledger Get General Ledger
+ * @apiVersion 1.5.0
+ * @apiName GetLedger
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} start First day (YYYY-MM-DD) in the Org's timezone. Defaults to the beginning.
+ * @apiParam {String} end Last day (YYYY-MM-DD) in the Org's timezone. Defaults to all future dates.
+ * @apiParam {Number} skip Number of lines to skip
+ * @apiParam {Number} limit Max number of lines to return
+ * @apiDescription Lists every split on the Account and its descendants in date order with a running balance. Balances stay correct when paging with skip and limit. Balances only include descendants in the Account's currency. Native balances include all descendants.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} accountId Id of the Account.
+ * @apiSuccess {String} currency Three letter currency code of the Account.
+ * @apiSuccess {Number} precision How many digits the currency goes out to.
+ * @apiSuccess {Date} start Start of the ledger
+ * @apiSuccess {Date} end End of the ledger (exclusive)
+ * @apiSuccess {Number} openingBalance Balance at start in the Account's currency
+ * @apiSuccess {Number} openingNativeBalance Balance at start in the Org's currency
+ * @apiSuccess {Object[]} lines Splits in date order
+ * @apiSuccess {String} lines.transactionId Id of the Transaction.
+ * @apiSuccess {String} lines.accountId Id of the Account of the split.
+ * @apiSuccess {Date} lines.date Date of the Transaction
+ * @apiSuccess {Date} lines.inserted Date Transaction was created
+ * @apiSuccess {String} lines.description Description of Transaction
+ * @apiSuccess {Number} lines.amount Amount of the split in its Account's currency
+ * @apiSuccess {Number} lines.nativeAmount Amount of the split in the Org's currency
+ * @apiSuccess {Number} lines.balance Running balance after this line in the Account's currency
+ * @apiSuccess {Number} lines.nativeBalance Running balance after this line in the Org's currency
+ * @apiSuccess {Number} closingBalance Balance at end in the Account's currency
+ * @apiSuccess {Number} closingNativeBalance Balance at end in the Org's currency
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "accountId": "22222222222222222222222222222222",
+ *       "currency": "USD",
+ *       "precision": 2,
+ *       "start": "2018-01-01T00:00:00Z",
+ *       "end": "2019-01-01T00:00:00Z",
+ *       "openingBalance": 10000,
+ *       "openingNativeBalance": 10000,
+ *       "lines": [
+ *         {
+ *           "transactionId": "33333333333333333333333333333333",
+ *           "accountId": "22222222222222222222222222222222",
+ *           "date": "2018-06-08T20:12:29.720Z",
+ *           "inserted": "2018-06-08T20:12:29.720Z",
+ *           "description": "Treat friend to lunch",
+ *           "amount": -2000,
+ *           "nativeAmount": -2000,
+ *           "balance": 8000,
+ *           "nativeBalance": 8000
+ *         }
+ *       ],
+ *       "closingBalance": 8000,
+ *       "closingNativeBalance": 8000
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetLedger(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	queryOptions, err := types.QueryOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid query options", 400)
+		return
+	}
+
+	ledger, err := model.Instance.GetLedger(orgId, user.Id, accountId, reportOptions, queryOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(ledger)
+}
+
+/**
+ * @api {get} /orgs/:orgId/accounts/:accountId/balance-history Get Balance History
+ * @apiVersion 1.5.0
+ * @apiName GetBalanceHistory
+ * @apiGroup Report
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} start First day (YYYY-MM-DD) in the Org's timezone
+ * @apiParam {String} end Last day (YYYY-MM-DD) in the Org's timezone
+ * @apiParam {String} interval "day" (default), "week" or "month"
+ * @apiDescription Balance of the Account and its descendants at the end of each interval. Balances only include descendants in the Account's currency. Native balances include all descendants.
+ *
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} accountId Id of the Account.
+ * @apiSuccess {String} currency Three letter currency code of the Account.
+ * @apiSuccess {Number} precision How many digits the currency goes out to.
+ * @apiSuccess {String} interval Interval between points
+ * @apiSuccess {Object[]} points Balances in date order
+ * @apiSuccess {Date} points.date Balance includes transactions before this date
+ * @apiSuccess {Number} points.balance Balance in the Account's currency
+ * @apiSuccess {Number} points.nativeBalance Balance in the Org's currency
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "orgId": "11111111111111111111111111111111",
+ *       "accountId": "22222222222222222222222222222222",
+ *       "currency": "USD",
+ *       "precision": 2,
+ *       "interval": "day",
+ *       "points": [
+ *         {
+ *           "date": "2018-01-02T05:00:00Z",
+ *           "balance": 10000,
+ *           "nativeBalance": 10000
+ *         },
+ *         {
+ *           "date": "2018-01-03T05:00:00Z",
+ *           "balance": 8000,
+ *           "nativeBalance": 8000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetBalanceHistory(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	accountId := r.PathParam("accountId")
+
+	reportOptions, err := types.ReportOptionsFromURLQuery(r.URL.Query())
+
+	if err != nil {
+		rest.Error(w, "invalid report options", 400)
+		return
+	}
+
+	balanceHistory, err := model.Instance.GetBalanceHistory(orgId, user.Id, accountId, reportOptions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(balanceHistory)
+}
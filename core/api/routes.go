@@ -16,6 +16,14 @@ func GetRouter(auth *AuthMiddleware, prefix string) (rest.App, error) {
 		rest.Get(prefix+"/orgs", auth.RequireAuth(GetOrgs)),
 		rest.Get(prefix+"/orgs/:orgId", auth.RequireAuth(GetOrg)),
 		rest.Put(prefix+"/orgs/:orgId", auth.RequireAuth(PutOrg)),
+		rest.Put(prefix+"/orgs/:orgId/lock-date", auth.RequireAuth(PutLockDate)),
+		rest.Get(prefix+"/orgs/:orgId/periods", auth.RequireAuth(GetAccountingPeriods)),
+		rest.Post(prefix+"/orgs/:orgId/periods", auth.RequireAuth(PostAccountingPeriod)),
+		rest.Post(prefix+"/orgs/:orgId/periods/:periodId/close", auth.RequireAuth(CloseAccountingPeriod)),
+		rest.Post(prefix+"/orgs/:orgId/periods/:periodId/reopen", auth.RequireAuth(ReopenAccountingPeriod)),
+		rest.Get(prefix+"/orgs/:orgId/closings", auth.RequireAuth(GetClosings)),
+		rest.Post(prefix+"/orgs/:orgId/closings", auth.RequireAuth(PostClosing)),
+		rest.Delete(prefix+"/orgs/:orgId/closings/:closingId", auth.RequireAuth(DeleteClosing)),
 		rest.Get(prefix+"/orgs/:orgId/ledgers", auth.RequireAuth(GetOrgAccounts)),
 		rest.Post(prefix+"/orgs/:orgId/ledgers", auth.RequireAuth(PostAccount)),
 		rest.Put(prefix+"/orgs/:orgId/ledgers/:accountId", auth.RequireAuth(PutAccount)),
@@ -27,10 +35,53 @@ func GetRouter(auth *AuthMiddleware, prefix string) (rest.App, error) {
 		rest.Put(prefix+"/orgs/:orgId/accounts/:accountId", auth.RequireAuth(PutAccount)),
 		rest.Delete(prefix+"/orgs/:orgId/accounts/:accountId", auth.RequireAuth(DeleteAccount)),
 		rest.Get(prefix+"/orgs/:orgId/accounts/:accountId/transactions", auth.RequireAuth(GetTransactionsByAccount)),
+		rest.Get(prefix+"/orgs/:orgId/accounts/:accountId/general-ledger", auth.RequireAuth(GetLedger)),
+		rest.Get(prefix+"/orgs/:orgId/accounts/:accountId/balance-history", auth.RequireAuth(GetBalanceHistory)),
+		rest.Get(prefix+"/orgs/:orgId/accounts/:accountId/csv-mapping", auth.RequireAuth(GetCsvMapping)),
+		rest.Put(prefix+"/orgs/:orgId/accounts/:accountId/csv-mapping", auth.RequireAuth(PutCsvMapping)),
+		rest.Post(prefix+"/orgs/:orgId/accounts/:accountId/import/csv", auth.RequireAuth(ImportCsv)),
+		rest.Post(prefix+"/orgs/:orgId/accounts/:accountId/import/ofx", auth.RequireAuth(ImportOfx)),
+		rest.Post(prefix+"/orgs/:orgId/accounts/:accountId/import/qif", auth.RequireAuth(ImportQif)),
 		rest.Get(prefix+"/orgs/:orgId/transactions", auth.RequireAuth(GetTransactionsByOrg)),
 		rest.Post(prefix+"/orgs/:orgId/transactions", auth.RequireAuth(PostTransaction)),
+		rest.Post(prefix+"/orgs/:orgId/transactions/batch", auth.RequireAuth(PostTransactions)),
 		rest.Put(prefix+"/orgs/:orgId/transactions/:transactionId", auth.RequireAuth(PutTransaction)),
 		rest.Delete(prefix+"/orgs/:orgId/transactions/:transactionId", auth.RequireAuth(DeleteTransaction)),
+		rest.Post(prefix+"/orgs/:orgId/transactions/:transactionId/reverse", auth.RequireAuth(ReverseTransaction)),
+		rest.Get(prefix+"/orgs/:orgId/recurring-transactions", auth.RequireAuth(GetRecurringTransactions)),
+		rest.Post(prefix+"/orgs/:orgId/recurring-transactions", auth.RequireAuth(PostRecurringTransaction)),
+		rest.Get(prefix+"/orgs/:orgId/recurring-transactions/:recurringTransactionId", auth.RequireAuth(GetRecurringTransaction)),
+		rest.Put(prefix+"/orgs/:orgId/recurring-transactions/:recurringTransactionId", auth.RequireAuth(PutRecurringTransaction)),
+		rest.Delete(prefix+"/orgs/:orgId/recurring-transactions/:recurringTransactionId", auth.RequireAuth(DeleteRecurringTransaction)),
+		rest.Get(prefix+"/orgs/:orgId/templates", auth.RequireAuth(GetTemplates)),
+		rest.Post(prefix+"/orgs/:orgId/templates", auth.RequireAuth(PostTemplate)),
+		rest.Get(prefix+"/orgs/:orgId/templates/:templateId", auth.RequireAuth(GetTemplate)),
+		rest.Put(prefix+"/orgs/:orgId/templates/:templateId", auth.RequireAuth(PutTemplate)),
+		rest.Delete(prefix+"/orgs/:orgId/templates/:templateId", auth.RequireAuth(DeleteTemplate)),
+		rest.Post(prefix+"/orgs/:orgId/templates/:templateId/instantiate", auth.RequireAuth(InstantiateTemplate)),
+		rest.Get(prefix+"/orgs/:orgId/rules", auth.RequireAuth(GetRules)),
+		rest.Post(prefix+"/orgs/:orgId/rules", auth.RequireAuth(PostRule)),
+		rest.Post(prefix+"/orgs/:orgId/rules/apply", auth.RequireAuth(ApplyRules)),
+		rest.Get(prefix+"/orgs/:orgId/rules/:ruleId", auth.RequireAuth(GetRule)),
+		rest.Put(prefix+"/orgs/:orgId/rules/:ruleId", auth.RequireAuth(PutRule)),
+		rest.Delete(prefix+"/orgs/:orgId/rules/:ruleId", auth.RequireAuth(DeleteRule)),
+		rest.Get(prefix+"/orgs/:orgId/reconciliations", auth.RequireAuth(GetReconciliations)),
+		rest.Post(prefix+"/orgs/:orgId/reconciliations", auth.RequireAuth(PostReconciliation)),
+		rest.Get(prefix+"/orgs/:orgId/reconciliations/:reconciliationId", auth.RequireAuth(GetReconciliation)),
+		rest.Put(prefix+"/orgs/:orgId/reconciliations/:reconciliationId", auth.RequireAuth(PutReconciliation)),
+		rest.Delete(prefix+"/orgs/:orgId/reconciliations/:reconciliationId", auth.RequireAuth(DeleteReconciliation)),
+		rest.Put(prefix+"/orgs/:orgId/reconciliations/:reconciliationId/splits", auth.RequireAuth(PutReconciliationSplits)),
+		rest.Post(prefix+"/orgs/:orgId/reconciliations/:reconciliationId/finish", auth.RequireAuth(FinishReconciliation)),
+		rest.Get(prefix+"/orgs/:orgId/contacts", auth.RequireAuth(GetContacts)),
+		rest.Post(prefix+"/orgs/:orgId/contacts", auth.RequireAuth(PostContact)),
+		rest.Get(prefix+"/orgs/:orgId/contacts/:contactId", auth.RequireAuth(GetContact)),
+		rest.Put(prefix+"/orgs/:orgId/contacts/:contactId", auth.RequireAuth(PutContact)),
+		rest.Delete(prefix+"/orgs/:orgId/contacts/:contactId", auth.RequireAuth(DeleteContact)),
+		rest.Get(prefix+"/orgs/:orgId/dimensions", auth.RequireAuth(GetDimensions)),
+		rest.Post(prefix+"/orgs/:orgId/dimensions", auth.RequireAuth(PostDimension)),
+		rest.Get(prefix+"/orgs/:orgId/dimensions/:dimensionId", auth.RequireAuth(GetDimension)),
+		rest.Put(prefix+"/orgs/:orgId/dimensions/:dimensionId", auth.RequireAuth(PutDimension)),
+		rest.Delete(prefix+"/orgs/:orgId/dimensions/:dimensionId", auth.RequireAuth(DeleteDimension)),
 		rest.Get(prefix+"/orgs/:orgId/prices", auth.RequireAuth(GetPrices)),
 		rest.Post(prefix+"/orgs/:orgId/prices", auth.RequireAuth(PostPrice)),
 		rest.Delete(prefix+"/orgs/:orgId/prices/:priceId", auth.RequireAuth(DeletePrice)),
@@ -49,5 +100,16 @@ func GetRouter(auth *AuthMiddleware, prefix string) (rest.App, error) {
 		rest.Get(prefix+"/orgs/:orgId/budget", auth.RequireAuth(GetBudget)),
 		rest.Post(prefix+"/orgs/:orgId/budget", auth.RequireAuth(PostBudget)),
 		rest.Delete(prefix+"/orgs/:orgId/budget", auth.RequireAuth(DeleteBudget)),
+		rest.Get(prefix+"/orgs/:orgId/budgets", auth.RequireAuth(GetNamedBudgets)),
+		rest.Post(prefix+"/orgs/:orgId/budgets", auth.RequireAuth(PostNamedBudget)),
+		rest.Get(prefix+"/orgs/:orgId/budgets/:budgetId", auth.RequireAuth(GetNamedBudget)),
+		rest.Put(prefix+"/orgs/:orgId/budgets/:budgetId", auth.RequireAuth(PutNamedBudget)),
+		rest.Delete(prefix+"/orgs/:orgId/budgets/:budgetId", auth.RequireAuth(DeleteNamedBudget)),
+		rest.Get(prefix+"/orgs/:orgId/reports/trial-balance", auth.RequireAuth(GetTrialBalance)),
+		rest.Get(prefix+"/orgs/:orgId/reports/income-statement", auth.RequireAuth(GetIncomeStatement)),
+		rest.Get(prefix+"/orgs/:orgId/reports/balance-sheet", auth.RequireAuth(GetBalanceSheet)),
+		rest.Get(prefix+"/orgs/:orgId/reports/cash-flow", auth.RequireAuth(GetCashFlowStatement)),
+		rest.Get(prefix+"/orgs/:orgId/reports/tags", auth.RequireAuth(GetTagReport)),
+		rest.Get(prefix+"/orgs/:orgId/reports/budget-variance", auth.RequireAuth(GetBudgetVariance)),
 	)
 }
@@ -0,0 +1,355 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/rules Get Categorization Rules
+ * @apiVersion 1.5.0
+ * @apiName GetRules
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Rule.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Rule was created
+ * @apiSuccess {Date} updated Date Rule was last updated
+ * @apiSuccess {String} name Name of the Rule
+ * @apiSuccess {Number} priority Rules with a lower priority are tried first
+ * @apiSuccess {String} descriptionPattern Case insensitive regular expression the description must match
+ * @apiSuccess {Number} minAmount Smallest matching amount. Null for no minimum.
+ * @apiSuccess {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiSuccess {String} accountId Id of the Account to categorize matching Transactions as
+ * @apiSuccess {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "name": "Groceries",
+ *         "priority": 10,
+ *         "descriptionPattern": "^pos .*corner store",
+ *         "minAmount": null,
+ *         "maxAmount": -1,
+ *         "accountId": "33333333333333333333333333333333",
+ *         "payee": "Corner Store"
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetRules(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	rules, err := model.Instance.GetRules(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&rules)
+}
+
+/**
+ * @api {get} /orgs/:orgId/rules/:ruleId Get a Categorization Rule
+ * @apiVersion 1.5.0
+ * @apiName GetRule
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Rule.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Rule was created
+ * @apiSuccess {Date} updated Date Rule was last updated
+ * @apiSuccess {String} name Name of the Rule
+ * @apiSuccess {Number} priority Rules with a lower priority are tried first
+ * @apiSuccess {String} descriptionPattern Case insensitive regular expression the description must match
+ * @apiSuccess {Number} minAmount Smallest matching amount. Null for no minimum.
+ * @apiSuccess {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiSuccess {String} accountId Id of the Account to categorize matching Transactions as
+ * @apiSuccess {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Groceries",
+ *       "priority": 10,
+ *       "descriptionPattern": "^pos .*corner store",
+ *       "minAmount": null,
+ *       "maxAmount": -1,
+ *       "accountId": "33333333333333333333333333333333",
+ *       "payee": "Corner Store"
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetRule(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	ruleId := r.PathParam("ruleId")
+
+	rule, err := model.Instance.GetRule(orgId, ruleId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(rule)
+}
+
+/**
+ * @api {post} /orgs/:orgId/rules Create a Categorization Rule
+ * @apiVersion 1.5.0
+ * @apiName PostRule
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} id Id 32 character hex string
+ * @apiParam {String} name Name of the Rule
+ * @apiParam {Number} priority Rules with a lower priority are tried first
+ * @apiParam {String} descriptionPattern Case insensitive regular expression the description must match. Empty matches every description.
+ * @apiParam {Number} minAmount Smallest matching amount. Amounts are those of the split for the bank Account, so outflows are negative. Null for no minimum.
+ * @apiParam {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiParam {String} accountId Id of the Account to categorize matching Transactions as. Must be in the Org currency.
+ * @apiParam {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccess {String} id Id of the Rule.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Rule was created
+ * @apiSuccess {Date} updated Date Rule was last updated
+ * @apiSuccess {String} name Name of the Rule
+ * @apiSuccess {Number} priority Rules with a lower priority are tried first
+ * @apiSuccess {String} descriptionPattern Case insensitive regular expression the description must match
+ * @apiSuccess {Number} minAmount Smallest matching amount. Null for no minimum.
+ * @apiSuccess {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiSuccess {String} accountId Id of the Account to categorize matching Transactions as
+ * @apiSuccess {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Groceries",
+ *       "priority": 10,
+ *       "descriptionPattern": "^pos .*corner store",
+ *       "minAmount": null,
+ *       "maxAmount": -1,
+ *       "accountId": "33333333333333333333333333333333",
+ *       "payee": "Corner Store"
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostRule(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	rule := types.Rule{}
+	err := r.DecodeJsonPayload(&rule)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rule.OrgId = orgId
+
+	err = model.Instance.CreateRule(&rule, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&rule)
+}
+
+/**
+ * @api {put} /orgs/:orgId/rules/:ruleId Modify a Categorization Rule
+ * @apiVersion 1.5.0
+ * @apiName PutRule
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} name Name of the Rule
+ * @apiParam {Number} priority Rules with a lower priority are tried first
+ * @apiParam {String} descriptionPattern Case insensitive regular expression the description must match. Empty matches every description.
+ * @apiParam {Number} minAmount Smallest matching amount. Amounts are those of the split for the bank Account, so outflows are negative. Null for no minimum.
+ * @apiParam {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiParam {String} accountId Id of the Account to categorize matching Transactions as. Must be in the Org currency.
+ * @apiParam {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccess {String} id Id of the Rule.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Rule was created
+ * @apiSuccess {Date} updated Date Rule was last updated
+ * @apiSuccess {String} name Name of the Rule
+ * @apiSuccess {Number} priority Rules with a lower priority are tried first
+ * @apiSuccess {String} descriptionPattern Case insensitive regular expression the description must match
+ * @apiSuccess {Number} minAmount Smallest matching amount. Null for no minimum.
+ * @apiSuccess {Number} maxAmount Largest matching amount. Null for no maximum.
+ * @apiSuccess {String} accountId Id of the Account to categorize matching Transactions as
+ * @apiSuccess {String} payee Description to give matching Transactions. Empty to keep the description.
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Groceries",
+ *       "priority": 10,
+ *       "descriptionPattern": "^pos .*corner store",
+ *       "minAmount": null,
+ *       "maxAmount": -1,
+ *       "accountId": "33333333333333333333333333333333",
+ *       "payee": "Corner Store"
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutRule(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	ruleId := r.PathParam("ruleId")
+
+	rule := types.Rule{}
+	err := r.DecodeJsonPayload(&rule)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rule.Id = ruleId
+	rule.OrgId = orgId
+
+	err = model.Instance.UpdateRule(&rule, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&rule)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/rules/:ruleId Delete a Categorization Rule
+ * @apiVersion 1.5.0
+ * @apiName DeleteRule
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteRule(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	ruleId := r.PathParam("ruleId")
+
+	err := model.Instance.DeleteRule(orgId, ruleId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ * @api {post} /orgs/:orgId/rules/apply Apply Categorization Rules
+ * @apiVersion 1.5.0
+ * @apiName ApplyRules
+ * @apiGroup Rule
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Recategorizes existing Transactions that have one split in
+ * accountId, such as a suspense account, and one other split. The first Rule
+ * that matches the description and the amount of the other split moves the
+ * split for accountId to the Account of the Rule. Each recategorized
+ * Transaction is replaced by a copy with a new id, like an edit.
+ * Rules are also applied automatically when statements are imported.
+ *
+ * @apiParam {String} accountId Id of the Account holding uncategorized Transactions
+ *
+ * @apiSuccess {Object[]} transactions Array of recategorized Transactions
+ * @apiSuccess {Object[]} errors Array of errors for matching Transactions that could not be changed, with the id of the Transaction
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "transactions": [],
+ *       "errors": [
+ *         {
+ *           "index": 0,
+ *           "id": "44444444444444444444444444444444",
+ *           "error": "transaction date is in a closed period"
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ApplyRules(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	application := types.RuleApplication{}
+	err := r.DecodeJsonPayload(&application)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := model.Instance.ApplyRules(orgId, application.AccountId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(result)
+}
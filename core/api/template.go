@@ -0,0 +1,427 @@
+package api
+
+import (
+	"github.com/ant0ine/go-json-rest/rest"
+	"github.com/openaccounting/oa-server/core/model"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"net/http"
+)
+
+/**
+ * @api {get} /orgs/:orgId/templates Get Transaction Templates
+ * @apiVersion 1.5.0
+ * @apiName GetTemplates
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Template.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Template was created
+ * @apiSuccess {Date} updated Date Template was last updated
+ * @apiSuccess {String} name Name of the Template
+ * @apiSuccess {String} description Default description of Transactions
+ * @apiSuccess {String} data Extra data field of Transactions
+ * @apiSuccess {Object[]} splits Array of Template Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "22222222222222222222222222222222",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "name": "Split phone bill",
+ *         "description": "Phone bill",
+ *         "data": "",
+ *         "splits": [
+ *           {
+ *             "accountId": "33333333333333333333333333333333",
+ *             "amount": null,
+ *             "percent": 60
+ *           },
+ *           {
+ *             "accountId": "44444444444444444444444444444444",
+ *             "amount": null,
+ *             "percent": 40
+ *           },
+ *           {
+ *             "accountId": "55555555555555555555555555555555",
+ *             "amount": null,
+ *             "percent": -100
+ *           }
+ *         ]
+ *       }
+ *     ]
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetTemplates(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	templates, err := model.Instance.GetTemplates(orgId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&templates)
+}
+
+/**
+ * @api {get} /orgs/:orgId/templates/:templateId Get a Transaction Template
+ * @apiVersion 1.5.0
+ * @apiName GetTemplate
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccess {String} id Id of the Template.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Template was created
+ * @apiSuccess {Date} updated Date Template was last updated
+ * @apiSuccess {String} name Name of the Template
+ * @apiSuccess {String} description Default description of Transactions
+ * @apiSuccess {String} data Extra data field of Transactions
+ * @apiSuccess {Object[]} splits Array of Template Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Split phone bill",
+ *       "description": "Phone bill",
+ *       "data": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "33333333333333333333333333333333",
+ *           "amount": null,
+ *           "percent": 60
+ *         },
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": null,
+ *           "percent": 40
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": null,
+ *           "percent": -100
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func GetTemplate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	templateId := r.PathParam("templateId")
+
+	template, err := model.Instance.GetTemplate(orgId, templateId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(template)
+}
+
+/**
+ * @api {post} /orgs/:orgId/templates Create a Transaction Template
+ * @apiVersion 1.5.0
+ * @apiName PostTemplate
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} id 32 character hex string
+ * @apiParam {String} name Name of the Template
+ * @apiParam {String} description Default description of Transactions
+ * @apiParam {String} data Extra data field of Transactions
+ * @apiParam {Object[]} splits Array of Template Splits. Accounts must be in the Org currency.
+ * @apiParam {String} splits.accountId Id of Account
+ * @apiParam {Number} splits.amount Fixed amount of the split
+ * @apiParam {Number} splits.percent Percent of the total amount, e.g. 60 or -100. Positive is a debit.
+ *   One split may have neither amount nor percent and balances the Transaction. Otherwise
+ *   amounts and percents must each add up to 0.
+ *
+ * @apiSuccess {String} id Id of the Template.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Template was created
+ * @apiSuccess {Date} updated Date Template was last updated
+ * @apiSuccess {String} name Name of the Template
+ * @apiSuccess {String} description Default description of Transactions
+ * @apiSuccess {String} data Extra data field of Transactions
+ * @apiSuccess {Object[]} splits Array of Template Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Split phone bill",
+ *       "description": "Phone bill",
+ *       "data": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "33333333333333333333333333333333",
+ *           "amount": null,
+ *           "percent": 60
+ *         },
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": null,
+ *           "percent": 40
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": null,
+ *           "percent": -100
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostTemplate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	template := types.Template{}
+	err := r.DecodeJsonPayload(&template)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	template.OrgId = orgId
+
+	err = model.Instance.CreateTemplate(&template, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&template)
+}
+
+/**
+ * @api {put} /orgs/:orgId/templates/:templateId Modify a Transaction Template
+ * @apiVersion 1.5.0
+ * @apiName PutTemplate
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiParam {String} name Name of the Template
+ * @apiParam {String} description Default description of Transactions
+ * @apiParam {String} data Extra data field of Transactions
+ * @apiParam {Object[]} splits Array of Template Splits. Replaces all existing splits. Accounts must be in the Org currency.
+ * @apiParam {String} splits.accountId Id of Account
+ * @apiParam {Number} splits.amount Fixed amount of the split
+ * @apiParam {Number} splits.percent Percent of the total amount, e.g. 60 or -100. Positive is a debit.
+ *   One split may have neither amount nor percent and balances the Transaction. Otherwise
+ *   amounts and percents must each add up to 0.
+ *
+ * @apiSuccess {String} id Id of the Template.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {Date} inserted Date Template was created
+ * @apiSuccess {Date} updated Date Template was last updated
+ * @apiSuccess {String} name Name of the Template
+ * @apiSuccess {String} description Default description of Transactions
+ * @apiSuccess {String} data Extra data field of Transactions
+ * @apiSuccess {Object[]} splits Array of Template Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "name": "Split phone bill",
+ *       "description": "Phone bill",
+ *       "data": "",
+ *       "splits": [
+ *         {
+ *           "accountId": "33333333333333333333333333333333",
+ *           "amount": null,
+ *           "percent": 60
+ *         },
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": null,
+ *           "percent": 40
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": null,
+ *           "percent": -100
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PutTemplate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	templateId := r.PathParam("templateId")
+
+	template := types.Template{}
+	err := r.DecodeJsonPayload(&template)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	template.Id = templateId
+	template.OrgId = orgId
+
+	err = model.Instance.UpdateTemplate(&template, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(&template)
+}
+
+/**
+ * @api {delete} /orgs/:orgId/templates/:templateId Delete a Transaction Template
+ * @apiVersion 1.5.0
+ * @apiName DeleteTemplate
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func DeleteTemplate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	templateId := r.PathParam("templateId")
+
+	err := model.Instance.DeleteTemplate(orgId, templateId, user.Id)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+/**
+ * @api {post} /orgs/:orgId/templates/:templateId/instantiate Create a Transaction from a Template
+ * @apiVersion 1.5.0
+ * @apiName InstantiateTemplate
+ * @apiGroup Template
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Creates a Transaction with one split per Template Split.
+ * Percent splits are a share of amount rounded to the nearest unit. The blank
+ * split, or if there is none the percent split with the largest rounding error,
+ * absorbs any difference so the splits add up to 0.
+ *
+ * @apiParam {String} id Id of the Transaction. 32 character hex string
+ * @apiParam {Date} date Date of the Transaction. Defaults to now.
+ * @apiParam {Number} amount Total amount that percents apply to
+ * @apiParam {String} description Description of the Transaction. Defaults to the Template description.
+ *
+ * @apiSuccess {String} id Id of the Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who created the Transaction.
+ * @apiSuccess {Date} date Date of the Transaction
+ * @apiSuccess {Date} inserted Date Transaction was created
+ * @apiSuccess {Date} updated Date Transaction was updated
+ * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} data Extra data field
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "66666666666666666666666666666666",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "77777777777777777777777777777777",
+ *       "date": "2018-06-08T20:12:29.720Z",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "description": "Phone bill",
+ *       "data": "",
+ *       "reversalOf": "",
+ *       "autoReverseDate": null,
+ *       "splits": [
+ *         {
+ *           "accountId": "33333333333333333333333333333333",
+ *           "amount": 6000,
+ *           "nativeAmount": 6000
+ *         },
+ *         {
+ *           "accountId": "44444444444444444444444444444444",
+ *           "amount": 4000,
+ *           "nativeAmount": 4000
+ *         },
+ *         {
+ *           "accountId": "55555555555555555555555555555555",
+ *           "amount": -10000,
+ *           "nativeAmount": -10000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func InstantiateTemplate(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	templateId := r.PathParam("templateId")
+
+	instance := types.TemplateInstance{}
+	err := r.DecodeJsonPayload(&instance)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	transaction, err := model.Instance.InstantiateTemplate(orgId, templateId, user.Id, &instance)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(transaction)
+}
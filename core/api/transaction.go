@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"github.com/ant0ine/go-json-rest/rest"
 	"github.com/openaccounting/oa-server/core/model"
 	"github.com/openaccounting/oa-server/core/model/types"
@@ -16,6 +17,9 @@ import (
  * @apiHeader {String} Authorization HTTP Basic Auth
  * @apiHeader {String} Accept-Version ^1.4.0 semver versioning
  *
+ * @apiParam {String} contactId Optional query parameter. Only return Transactions with this Contact.
+ * @apiParam {String} tag Optional query parameter. Only return Transactions with this tag. Can be repeated to require several tags.
+ *
  * @apiSuccess {String} id Id of the Transaction.
  * @apiSuccess {String} orgId Id of the Org.
  * @apiSuccess {String} userId Id of the User who created the Transaction.
@@ -23,7 +27,11 @@ import (
  * @apiSuccess {Date} inserted Date Transaction was created
  * @apiSuccess {Date} updated Date Transaction was updated
  * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} contactId Id of the Contact the Transaction is with. Empty if not set.
+ * @apiSuccess {String[]} tags Tags of the Transaction
  * @apiSuccess {String} data Extra data field
+ * @apiSuccess {String} reversalOf Id of the Transaction this one reverses. Empty if not a reversal.
+ * @apiSuccess {Date} autoReverseDate Date the Transaction is automatically reversed on. Null if not set.
  * @apiSuccess {Object[]} splits Array of Transaction Splits
  *
  * @apiSuccessExample Success-Response:
@@ -38,6 +46,8 @@ import (
  *         "updated": "2018-06-08T20:12:29.720Z",
  *         "description": "Treat friend to lunch",
  *         "data:": "{\"key\": \"value\"}",
+ *         "reversalOf": "",
+ *         "autoReverseDate": null,
  *         "splits": [
  *           {
  *             "accountId": "11111111111111111111111111111111",
@@ -92,6 +102,9 @@ func GetTransactionsByAccount(w rest.ResponseWriter, r *rest.Request) {
  * @apiHeader {String} Authorization HTTP Basic Auth
  * @apiHeader {String} Accept-Version ^1.4.0 semver versioning
  *
+ * @apiParam {String} contactId Optional query parameter. Only return Transactions with this Contact.
+ * @apiParam {String} tag Optional query parameter. Only return Transactions with this tag. Can be repeated to require several tags.
+ *
  * @apiSuccess {String} id Id of the Transaction.
  * @apiSuccess {String} orgId Id of the Org.
  * @apiSuccess {String} userId Id of the User who created the Transaction.
@@ -99,7 +112,11 @@ func GetTransactionsByAccount(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {Date} inserted Date Transaction was created
  * @apiSuccess {Date} updated Date Transaction was updated
  * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} contactId Id of the Contact the Transaction is with. Empty if not set.
+ * @apiSuccess {String[]} tags Tags of the Transaction
  * @apiSuccess {String} data Extra data field
+ * @apiSuccess {String} reversalOf Id of the Transaction this one reverses. Empty if not a reversal.
+ * @apiSuccess {Date} autoReverseDate Date the Transaction is automatically reversed on. Null if not set.
  * @apiSuccess {Object[]} splits Array of Transaction Splits
  *
  * @apiSuccessExample Success-Response:
@@ -114,6 +131,8 @@ func GetTransactionsByAccount(w rest.ResponseWriter, r *rest.Request) {
  *         "updated": "2018-06-08T20:12:29.720Z",
  *         "description": "Treat friend to lunch",
  *         "data:": "{\"key\": \"value\"}",
+ *         "reversalOf": "",
+ *         "autoReverseDate": null,
  *         "splits": [
  *           {
  *             "accountId": "11111111111111111111111111111111",
@@ -170,11 +189,21 @@ func GetTransactionsByOrg(w rest.ResponseWriter, r *rest.Request) {
  * @apiParam {String} id Id 32 character hex string
  * @apiParam {Date} date Date of the Transaction
  * @apiParam {String} description Description of Transaction
+ * @apiParam {String} contactId Optional id of the Contact the Transaction is with
+ * @apiParam {String[]} tags Optional free-form tags. Case insensitive and at most 100 characters each.
  * @apiParam {String} data Extra data field
+ * @apiParam {Date} autoReverseDate Optional date to automatically reverse the Transaction on. Must be after date.
+ *   If the reversal still fails a day after this date, autoReverseDate is cleared.
  * @apiParam {Object[]} splits Array of Transaction Splits. nativeAmounts must add up to 0.
  * @apiParam {String} splits.accountId Id of Account
  * @apiParam {Number} splits.amount Amount of split in Account currency
  * @apiParam {Number} splits.nativeAmount Amount of split in Org currency
+ * @apiParam {String} splits.externalId Id of the split in a bank statement it was imported from
+ * @apiParam {String} splits.status uncleared (default), cleared or reconciled. Only Reconciliations can reconcile a split and reconciled splits cannot be changed.
+ * @apiParam {String} splits.memo Optional note describing the split
+ * @apiParam {Number} splits.quantity Optional number of units, such as hours worked. Null if not set.
+ * @apiParam {Number} splits.unitPrice Optional price per unit in the smallest unit of Account currency. Null if not set.
+ * @apiParam {Object} splits.dimensions Optional Dimension values of the split keyed by Dimension id. Inactive values are rejected.
  *
  * @apiSuccess {String} id Id of the Transaction.
  * @apiSuccess {String} orgId Id of the Org.
@@ -183,8 +212,13 @@ func GetTransactionsByOrg(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {Date} inserted Date Transaction was created
  * @apiSuccess {Date} updated Date Transaction was updated
  * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} contactId Id of the Contact the Transaction is with. Empty if not set.
+ * @apiSuccess {String[]} tags Tags of the Transaction
  * @apiSuccess {String} data Extra data field
+ * @apiSuccess {String} reversalOf Id of the Transaction this one reverses. Empty if not a reversal.
+ * @apiSuccess {Date} autoReverseDate Date the Transaction is automatically reversed on. Null if not set.
  * @apiSuccess {Object[]} splits Array of Transaction Splits
+ * @apiSuccess {String[]} possibleDuplicates Ids of existing Transactions dated within 3 days with the same splits and a similar description. The Transaction is created either way.
  *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
@@ -197,6 +231,8 @@ func GetTransactionsByOrg(w rest.ResponseWriter, r *rest.Request) {
  *       "updated": "2018-06-08T20:12:29.720Z",
  *       "description": "Treat friend to lunch",
  *       "data:": "{\"key\": \"value\"}",
+ *       "reversalOf": "",
+ *       "autoReverseDate": null,
  *       "splits": [
  *         {
  *           "accountId": "11111111111111111111111111111111",
@@ -213,7 +249,8 @@ func GetTransactionsByOrg(w rest.ResponseWriter, r *rest.Request) {
  *           "amount": 1000,
  *           "nativeAmount": 1000
  *         }
- *       ]
+ *       ],
+ *       "possibleDuplicates": null
  *     }
  *
  * @apiUse NotAuthorizedError
@@ -244,6 +281,100 @@ func PostTransaction(w rest.ResponseWriter, r *rest.Request) {
 	w.WriteJson(sTx)
 }
 
+/**
+ * @api {post} /orgs/:orgId/transactions/batch Create many Transactions
+ * @apiVersion 1.5.0
+ * @apiName PostTransactions
+ * @apiGroup Transaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Creates up to 5000 Transactions at once. The body is an array
+ * of Transactions with the same fields as Create a new Transaction. Either all
+ * of them are created or none are. If any are invalid the response is a 400
+ * listing one error per invalid Transaction.
+ *
+ * @apiSuccess {Object[]} transactions Array of the created Transactions
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     [
+ *       {
+ *         "id": "11111111111111111111111111111111",
+ *         "orgId": "11111111111111111111111111111111",
+ *         "userId": "11111111111111111111111111111111",
+ *         "date": "2018-06-08T20:12:29.720Z",
+ *         "inserted": "2018-06-08T20:12:29.720Z",
+ *         "updated": "2018-06-08T20:12:29.720Z",
+ *         "description": "Treat friend to lunch",
+ *         "data:": "{\"key\": \"value\"}",
+ *         "reversalOf": "",
+ *         "autoReverseDate": null,
+ *         "splits": [
+ *           {
+ *             "accountId": "11111111111111111111111111111111",
+ *             "amount": -2000,
+ *             "nativeAmount": -2000
+ *           },
+ *           {
+ *             "accountId": "22222222222222222222222222222222",
+ *             "amount": 2000,
+ *             "nativeAmount": 2000
+ *           }
+ *         ]
+ *       }
+ *     ]
+ *
+ * @apiError InvalidTransactions One or more Transactions are invalid. Nothing was created.
+ *
+ * @apiErrorExample InvalidTransactions:
+ *     HTTP/1.1 400 Bad Request
+ *     {
+ *       "error": "1 of 2 transactions are invalid",
+ *       "errors": [
+ *         {
+ *           "index": 1,
+ *           "id": "22222222222222222222222222222222",
+ *           "error": "splits must add up to 0"
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func PostTransactions(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+
+	transactions := make([]*types.Transaction, 0)
+	err := r.DecodeJsonPayload(&transactions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	transactionErrors, err := model.Instance.CreateTransactions(orgId, user.Id, transactions)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(transactionErrors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.WriteJson(map[string]interface{}{
+			"error":  fmt.Sprintf("%d of %d transactions are invalid", len(transactionErrors), len(transactions)),
+			"errors": transactionErrors,
+		})
+		return
+	}
+
+	w.WriteJson(&transactions)
+}
+
 /**
  * @api {put} /orgs/:orgId/transactions/:transactionId Modify a Transaction
  * @apiVersion 1.4.0
@@ -253,14 +384,28 @@ func PostTransaction(w rest.ResponseWriter, r *rest.Request) {
  * @apiHeader {String} Authorization HTTP Basic Auth
  * @apiHeader {String} Accept-Version ^1.4.0 semver versioning
  *
+ * @apiDescription Closing Transactions cannot be modified. Delete the Closing instead.
+ * A reversal must keep reversing the original Transaction account by account,
+ * and a Transaction that has been reversed must keep matching its reversal.
+ *
  * @apiParam {String} id 32 character hex string
  * @apiParam {Date} date Date of the Transaction
  * @apiParam {String} description Description of Transaction
+ * @apiParam {String} contactId Optional id of the Contact the Transaction is with
+ * @apiParam {String[]} tags Optional free-form tags. Case insensitive and at most 100 characters each.
  * @apiParam {String} data Extra data field
+ * @apiParam {Date} autoReverseDate Optional date to automatically reverse the Transaction on. Must be after date.
+ *   If the reversal still fails a day after this date, autoReverseDate is cleared.
  * @apiParam {Object[]} splits Array of Transaction Splits. nativeAmounts must add up to 0.
  * @apiParam {String} splits.accountId Id of Account
  * @apiParam {Number} splits.amount Amount of split in Account currency
  * @apiParam {Number} splits.nativeAmount Amount of split in Org currency
+ * @apiParam {String} splits.externalId Id of the split in a bank statement it was imported from
+ * @apiParam {String} splits.status uncleared (default), cleared or reconciled. Only Reconciliations can reconcile a split and reconciled splits cannot be changed.
+ * @apiParam {String} splits.memo Optional note describing the split
+ * @apiParam {Number} splits.quantity Optional number of units, such as hours worked. Null if not set.
+ * @apiParam {Number} splits.unitPrice Optional price per unit in the smallest unit of Account currency. Null if not set.
+ * @apiParam {Object} splits.dimensions Optional Dimension values of the split keyed by Dimension id. Inactive values are rejected unless the Transaction already uses them.
  *
  * @apiSuccess {String} id Id of the Transaction.
  * @apiSuccess {String} orgId Id of the Org.
@@ -269,7 +414,11 @@ func PostTransaction(w rest.ResponseWriter, r *rest.Request) {
  * @apiSuccess {Date} inserted Date Transaction was created
  * @apiSuccess {Date} updated Date Transaction was updated
  * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} contactId Id of the Contact the Transaction is with. Empty if not set.
+ * @apiSuccess {String[]} tags Tags of the Transaction
  * @apiSuccess {String} data Extra data field
+ * @apiSuccess {String} reversalOf Id of the Transaction this one reverses. Empty if not a reversal.
+ * @apiSuccess {Date} autoReverseDate Date the Transaction is automatically reversed on. Null if not set.
  * @apiSuccess {Object[]} splits Array of Transaction Splits
  *
  * @apiSuccessExample Success-Response:
@@ -283,6 +432,8 @@ func PostTransaction(w rest.ResponseWriter, r *rest.Request) {
  *       "updated": "2018-06-08T20:12:29.720Z",
  *       "description": "Treat friend to lunch",
  *       "data:": "{\"key\": \"value\"}",
+ *       "reversalOf": "",
+ *       "autoReverseDate": null,
  *       "splits": [
  *         {
  *           "accountId": "11111111111111111111111111111111",
@@ -340,6 +491,8 @@ func PutTransaction(w rest.ResponseWriter, r *rest.Request) {
  * @apiHeader {String} Authorization HTTP Basic Auth
  * @apiHeader {String} Accept-Version ^1.4.0 semver versioning
  *
+ * @apiDescription Closing Transactions cannot be deleted. Delete the Closing instead.
+ *
  * @apiSuccessExample Success-Response:
  *     HTTP/1.1 200 OK
  *
@@ -360,3 +513,87 @@ func DeleteTransaction(w rest.ResponseWriter, r *rest.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+/**
+ * @api {post} /orgs/:orgId/transactions/:transactionId/reverse Reverse a Transaction
+ * @apiVersion 1.5.0
+ * @apiName ReverseTransaction
+ * @apiGroup Transaction
+ *
+ * @apiHeader {String} Authorization HTTP Basic Auth
+ * @apiHeader {String} Accept-Version ^1.5.0 semver versioning
+ *
+ * @apiDescription Creates the mirror image of a Transaction with every split
+ * amount negated and links it to the original. A Transaction can only be
+ * reversed once.
+ *
+ * @apiParam {String} id Id of the reversing Transaction. 32 character hex string
+ * @apiParam {Date} date Date of the reversing Transaction. Defaults to now.
+ * @apiParam {String} description Description of the reversing Transaction. Defaults to "Reversal of " and the original description.
+ *
+ * @apiSuccess {String} id Id of the Transaction.
+ * @apiSuccess {String} orgId Id of the Org.
+ * @apiSuccess {String} userId Id of the User who created the Transaction.
+ * @apiSuccess {Date} date Date of the Transaction
+ * @apiSuccess {Date} inserted Date Transaction was created
+ * @apiSuccess {Date} updated Date Transaction was updated
+ * @apiSuccess {String} description Description of Transaction
+ * @apiSuccess {String} contactId Id of the Contact the Transaction is with. Empty if not set.
+ * @apiSuccess {String[]} tags Tags of the Transaction
+ * @apiSuccess {String} data Extra data field
+ * @apiSuccess {String} reversalOf Id of the Transaction this one reverses.
+ * @apiSuccess {Date} autoReverseDate Always null for a reversal.
+ * @apiSuccess {Object[]} splits Array of Transaction Splits
+ *
+ * @apiSuccessExample Success-Response:
+ *     HTTP/1.1 200 OK
+ *     {
+ *       "id": "22222222222222222222222222222222",
+ *       "orgId": "11111111111111111111111111111111",
+ *       "userId": "11111111111111111111111111111111",
+ *       "date": "2018-07-01T04:00:00.000Z",
+ *       "inserted": "2018-06-08T20:12:29.720Z",
+ *       "updated": "2018-06-08T20:12:29.720Z",
+ *       "description": "Reversal of Accrued wages",
+ *       "data:": "",
+ *       "reversalOf": "11111111111111111111111111111111",
+ *       "autoReverseDate": null,
+ *       "splits": [
+ *         {
+ *           "accountId": "11111111111111111111111111111111",
+ *           "amount": 2000,
+ *           "nativeAmount": 2000
+ *         },
+ *         {
+ *           "accountId": "22222222222222222222222222222222",
+ *           "amount": -2000,
+ *           "nativeAmount": -2000
+ *         }
+ *       ]
+ *     }
+ *
+ * @apiUse NotAuthorizedError
+ * @apiUse InternalServerError
+ */
+func ReverseTransaction(w rest.ResponseWriter, r *rest.Request) {
+	user := r.Env["USER"].(*types.User)
+	orgId := r.PathParam("orgId")
+	transactionId := r.PathParam("transactionId")
+
+	reversal := types.Transaction{}
+	err := r.DecodeJsonPayload(&reversal)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = model.Instance.ReverseTransaction(orgId, user.Id, transactionId, &reversal)
+
+	if err != nil {
+		rest.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteJson(reversal)
+}
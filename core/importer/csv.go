@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CheckCsvMapping validates mapping and fills in defaults for the optional
+// settings
+func CheckCsvMapping(mapping *types.CsvMapping) error {
+	if mapping.Delimiter == "" {
+		mapping.Delimiter = ","
+	}
+
+	if mapping.DateFormat == "" {
+		mapping.DateFormat = "YYYY-MM-DD"
+	}
+
+	if mapping.AmountSign == "" {
+		mapping.AmountSign = "inflow"
+	}
+
+	if mapping.DecimalSeparator == "" {
+		mapping.DecimalSeparator = "."
+	}
+
+	if utf8.RuneCountInString(mapping.Delimiter) != 1 || mapping.Delimiter == "\"" || mapping.Delimiter == "\n" {
+		return errors.New("delimiter must be a single character")
+	}
+
+	if mapping.HeaderRows < 0 {
+		return errors.New("headerRows must not be negative")
+	}
+
+	if mapping.DateColumn < 1 {
+		return errors.New("dateColumn required")
+	}
+
+	if mapping.AmountColumn < 1 {
+		return errors.New("amountColumn required")
+	}
+
+	if mapping.OutflowColumn < 0 || mapping.DescriptionColumn < 0 || mapping.ReferenceColumn < 0 || mapping.CategoryColumn < 0 {
+		return errors.New("columns must not be negative")
+	}
+
+	if mapping.AmountSign != "inflow" && mapping.AmountSign != "outflow" {
+		return errors.New("amountSign must be inflow or outflow")
+	}
+
+	if mapping.DecimalSeparator != "." && mapping.DecimalSeparator != "," {
+		return errors.New("decimalSeparator must be . or ,")
+	}
+
+	if mapping.DecimalSeparator == mapping.Delimiter {
+		return errors.New("decimalSeparator and delimiter must be different")
+	}
+
+	return nil
+}
+
+// ParseCsv reads the entries of a CSV bank statement. Columns in mapping are
+// numbered from 1 and 0 means the column is not used. Dates without a time
+// zone are read in location.
+func ParseCsv(data string, mapping *types.CsvMapping, precision int, location *time.Location) ([]*Entry, error) {
+	err := CheckCsvMapping(mapping)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(strings.NewReader(strings.TrimPrefix(data, "\ufeff")))
+	reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	layout := dateLayout(mapping.DateFormat)
+	entries := make([]*Entry, 0)
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("row %d: %s", row, err.Error()))
+		}
+
+		if row <= mapping.HeaderRows {
+			continue
+		}
+
+		entry, err := parseCsvRecord(record, mapping, layout, precision, location)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("row %d: %s", row, err.Error()))
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no entries found")
+	}
+
+	setExternalIds(entries)
+
+	return entries, nil
+}
+
+func parseCsvRecord(record []string, mapping *types.CsvMapping, layout string, precision int, location *time.Location) (*Entry, error) {
+	column := func(n int) (string, error) {
+		if n == 0 {
+			return "", nil
+		}
+
+		if n > len(record) {
+			return "", errors.New(fmt.Sprintf("column %d not found", n))
+		}
+
+		return strings.TrimSpace(record[n-1]), nil
+	}
+
+	entry := &Entry{}
+
+	date, err := column(mapping.DateColumn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Date, err = time.ParseInLocation(layout, date, location)
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("date %s does not match format %s", date, mapping.DateFormat))
+	}
+
+	entry.Amount, err = parseCsvAmount(column, mapping, precision)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Description, err = column(mapping.DescriptionColumn); err != nil {
+		return nil, err
+	}
+
+	if entry.Reference, err = column(mapping.ReferenceColumn); err != nil {
+		return nil, err
+	}
+
+	if entry.Category, err = column(mapping.CategoryColumn); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// parseCsvAmount reads the amount column, or the difference between the
+// inflow (amount) and outflow columns when the statement has both
+func parseCsvAmount(column func(int) (string, error), mapping *types.CsvMapping, precision int) (int64, error) {
+	var amount int64
+
+	columns := []int{mapping.AmountColumn}
+
+	if mapping.OutflowColumn != 0 {
+		columns = append(columns, mapping.OutflowColumn)
+	}
+
+	for _, n := range columns {
+		value, err := column(n)
+
+		if err != nil {
+			return 0, err
+		}
+
+		// one of the inflow and outflow columns is usually blank
+		if value == "" && mapping.OutflowColumn != 0 {
+			continue
+		}
+
+		parsed, err := parseAmount(value, precision, mapping.DecimalSeparator)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if n == mapping.OutflowColumn {
+			// some banks show outflows as negative numbers
+			if parsed < 0 {
+				parsed = -parsed
+			}
+
+			parsed = -parsed
+		}
+
+		amount += parsed
+	}
+
+	if mapping.AmountSign == "outflow" {
+		amount = -amount
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxExternalIdLength is the longest bank reference used as is. Longer ones
+// are hashed to fit in split.externalId.
+const maxExternalIdLength = 100
+
+// Entry is a single line of a bank statement. A positive amount is money
+// coming into the account.
+type Entry struct {
+	Date        time.Time
+	Amount      int64
+	Description string
+	Reference   string
+	Category    string
+	ExternalId  string
+}
+
+// setExternalIds gives every entry an id that stays the same when the same
+// statement is imported again. The bank's reference is used when there is one.
+// Otherwise the id is a hash of the entry and how many identical entries came
+// before it so that two identical purchases on the same day are both kept.
+func setExternalIds(entries []*Entry) {
+	seen := make(map[string]int)
+
+	for _, entry := range entries {
+		if entry.Reference != "" && len(entry.Reference) <= maxExternalIdLength {
+			entry.ExternalId = entry.Reference
+			continue
+		}
+
+		key := strings.Join([]string{
+			strconv.FormatInt(entry.Date.Unix(), 10),
+			strconv.FormatInt(entry.Amount, 10),
+			entry.Description,
+			entry.Reference,
+		}, "|")
+
+		hash := md5.Sum([]byte(key + "|" + strconv.Itoa(seen[key])))
+		entry.ExternalId = hex.EncodeToString(hash[:])
+		seen[key]++
+	}
+}
+
+// parseAmount converts an amount like "1,234.56", "-12.00", "(12.00)" or
+// "$12" to the smallest unit of a currency with precision decimal places
+func parseAmount(value string, precision int, decimalSeparator string) (int64, error) {
+	value = strings.TrimSpace(value)
+	negative := false
+
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	if strings.HasPrefix(value, "-") {
+		negative = !negative
+		value = value[1:]
+	} else if strings.HasSuffix(value, "-") {
+		negative = !negative
+		value = value[:len(value)-1]
+	}
+
+	var whole, fraction strings.Builder
+	decimal := false
+
+	for _, r := range value {
+		switch {
+		case unicode.IsDigit(r) && decimal:
+			fraction.WriteRune(r)
+		case unicode.IsDigit(r):
+			whole.WriteRune(r)
+		case string(r) == decimalSeparator && !decimal:
+			decimal = true
+		case r == ',' || r == '.' || r == '\'' || unicode.IsSpace(r) || unicode.IsSymbol(r):
+			// thousands separators and currency symbols
+		default:
+			return 0, errors.New(fmt.Sprintf("invalid amount %s", value))
+		}
+	}
+
+	if whole.Len() == 0 && fraction.Len() == 0 {
+		return 0, errors.New(fmt.Sprintf("invalid amount %s", value))
+	}
+
+	if fraction.Len() > precision {
+		return 0, errors.New(fmt.Sprintf("amount %s has more than %d decimal places", value, precision))
+	}
+
+	digits := whole.String() + fraction.String() + strings.Repeat("0", precision-fraction.Len())
+	amount, err := strconv.ParseInt(digits, 10, 64)
+
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("invalid amount %s", value))
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+var dateTokens = []struct {
+	token  string
+	layout string
+}{
+	{"YYYY", "2006"},
+	{"YY", "06"},
+	{"MM", "01"},
+	{"M", "1"},
+	{"DD", "02"},
+	{"D", "2"},
+}
+
+// dateLayout converts a format like "DD/MM/YYYY" to a layout for time.Parse
+func dateLayout(format string) string {
+	var layout strings.Builder
+
+	for len(format) > 0 {
+		matched := false
+
+		for _, t := range dateTokens {
+			if strings.HasPrefix(format, t.token) {
+				layout.WriteString(t.layout)
+				format = format[len(t.token):]
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			layout.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+
+	return layout.String()
+}
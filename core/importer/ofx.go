@@ -0,0 +1,98 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ofxTransactionRegexp = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+var ofxValueRegexps = make(map[string]*regexp.Regexp)
+
+func init() {
+	for _, name := range []string{"DTPOSTED", "TRNAMT", "FITID", "NAME", "MEMO"} {
+		ofxValueRegexps[name] = regexp.MustCompile(`(?i)<` + name + `>([^<\r\n]*)`)
+	}
+}
+
+// ParseOfx reads the bank and credit card transactions of an OFX or QFX file.
+// Both the SGML (OFX 1.x) and XML (OFX 2.x) variants are supported. The FITID
+// of each transaction becomes the external id of its entry.
+func ParseOfx(data string, precision int, location *time.Location) ([]*Entry, error) {
+	matches := ofxTransactionRegexp.FindAllStringSubmatch(data, -1)
+	entries := make([]*Entry, len(matches))
+
+	for i, match := range matches {
+		entry, err := parseOfxTransaction(match[1], precision, location)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("transaction %d: %s", i+1, err.Error()))
+		}
+
+		entries[i] = entry
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no entries found")
+	}
+
+	setExternalIds(entries)
+
+	return entries, nil
+}
+
+func parseOfxTransaction(transaction string, precision int, location *time.Location) (*Entry, error) {
+	entry := &Entry{
+		Description: getOfxValue(transaction, "NAME"),
+		Reference:   getOfxValue(transaction, "FITID"),
+	}
+
+	if entry.Description == "" {
+		entry.Description = getOfxValue(transaction, "MEMO")
+	}
+
+	// dates look like 20180203[120000[.000][[-5:EST]]] and only the day is used
+	date := getOfxValue(transaction, "DTPOSTED")
+
+	if len(date) < 8 {
+		return nil, errors.New(fmt.Sprintf("invalid DTPOSTED %s", date))
+	}
+
+	var err error
+	entry.Date, err = time.ParseInLocation("20060102", date[:8], location)
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid DTPOSTED %s", date))
+	}
+
+	amount := getOfxValue(transaction, "TRNAMT")
+
+	// some banks use a comma as the decimal separator
+	if !strings.Contains(amount, ".") {
+		amount = strings.Replace(amount, ",", ".", 1)
+	}
+
+	entry.Amount, err = parseAmount(amount, precision, ".")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// getOfxValue returns the value of element name. SGML elements have no end
+// tag so the value runs until the next tag or line break.
+func getOfxValue(aggregate string, name string) string {
+	match := ofxValueRegexps[name].FindStringSubmatch(aggregate)
+
+	if match == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(html.UnescapeString(match[1]))
+}
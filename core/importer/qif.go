@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// qifTypes are the QIF sections that hold bank style transactions
+var qifTypes = map[string]bool{
+	"BANK":  true,
+	"CASH":  true,
+	"CCARD": true,
+	"OTH A": true,
+	"OTH L": true,
+}
+
+// ParseQif reads the transactions of the bank, cash and credit card sections
+// of a QIF file. QIF dates have no standard format so dateFormat says how to
+// read them, e.g. "M/D/YYYY". Two digit years and the "M/D'YY" style are read
+// as well. Split lines are ignored and the category of the transaction is
+// used. QIF has no transaction ids so entries are matched on their contents
+// when the file is imported again.
+func ParseQif(data string, dateFormat string, precision int, location *time.Location) ([]*Entry, error) {
+	if dateFormat == "" {
+		dateFormat = "M/D/YYYY"
+	}
+
+	layout := dateLayout(dateFormat)
+	entries := make([]*Entry, 0)
+	inTransactions := false
+	var entry *Entry
+
+	lines := strings.Split(strings.Replace(data, "\r\n", "\n", -1), "\n")
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToUpper(line)
+			inTransactions = strings.HasPrefix(header, "!TYPE:") && qifTypes[strings.TrimSpace(header[6:])]
+			entry = nil
+			continue
+		}
+
+		if !inTransactions {
+			continue
+		}
+
+		if line == "^" {
+			if entry != nil {
+				if entry.Date.IsZero() {
+					return nil, errors.New(fmt.Sprintf("line %d: date required", i+1))
+				}
+
+				entries = append(entries, entry)
+			}
+
+			entry = nil
+			continue
+		}
+
+		if entry == nil {
+			entry = &Entry{}
+		}
+
+		value := strings.TrimSpace(line[1:])
+		var err error
+
+		switch line[0] {
+		case 'D':
+			entry.Date, err = parseQifDate(value, layout, location)
+
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d: date %s does not match format %s", i+1, value, dateFormat))
+			}
+		case 'T', 'U':
+			entry.Amount, err = parseAmount(value, precision, ".")
+
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d: %s", i+1, err.Error()))
+			}
+		case 'P':
+			entry.Description = value
+		case 'M':
+			if entry.Description == "" {
+				entry.Description = value
+			}
+		case 'L':
+			entry.Category = getQifCategory(value)
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no entries found")
+	}
+
+	setExternalIds(entries)
+
+	return entries, nil
+}
+
+// parseQifDate reads dates like "3/2/2018", "03/02/18" or "3/ 2'18"
+func parseQifDate(value string, layout string, location *time.Location) (time.Time, error) {
+	value = strings.Replace(strings.Replace(value, "'", "/", -1), " ", "", -1)
+
+	date, err := time.ParseInLocation(layout, value, location)
+
+	if err != nil && strings.Contains(layout, "2006") {
+		date, err = time.ParseInLocation(strings.Replace(layout, "2006", "06", 1), value, location)
+	}
+
+	return date, err
+}
+
+// getQifCategory returns the account name of a category like "[Savings]" for
+// a transfer or "Food:Groceries/Class" for an expense
+func getQifCategory(category string) string {
+	if end := strings.Index(category, "/"); end != -1 {
+		category = category[:end]
+	}
+
+	category = strings.TrimSuffix(strings.TrimPrefix(category, "["), "]")
+
+	if start := strings.LastIndex(category, ":"); start != -1 {
+		category = category[start+1:]
+	}
+
+	return strings.TrimSpace(category)
+}
@@ -330,13 +330,7 @@ func (model *Model) getAllAccountsWithBalances(orgId string, date time.Time) ([]
 		return nil, err
 	}
 
-	err = model.db.AddBalances(accounts, date)
-
-	if err != nil {
-		return nil, err
-	}
-
-	err = model.db.AddNativeBalancesCost(accounts, date)
+	err = model.db.AddBalancesAndNativeBalancesCost(accounts, date)
 
 	if err != nil {
 		return nil, err
@@ -72,6 +72,16 @@ func (td *TdAccount) AddNativeBalancesCost(accounts []*types.Account, date time.
 	return nil
 }
 
+func (td *TdAccount) AddBalancesAndNativeBalancesCost(accounts []*types.Account, date time.Time) error {
+	balance := int64(1000)
+	for _, account := range accounts {
+		account.Balance = &balance
+		account.NativeBalance = &balance
+	}
+
+	return nil
+}
+
 func (td *TdAccount) GetSplitCountByAccountId(id string) (int64, error) {
 	args := td.Called(id)
 	return args.Get(0).(int64), args.Error(1)
@@ -0,0 +1,129 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+)
+
+// Enough for a couple of years of daily balances
+const maxBalanceHistoryPoints = 1000
+
+type BalanceHistoryInterface interface {
+	GetBalanceHistory(string, string, string, *types.ReportOptions) (*types.BalanceHistory, error)
+}
+
+// GetBalanceHistory returns the balance of an account and its descendants at
+// the end of each interval from start to end. Balances only include
+// descendants sharing the account's currency. Native balances include every
+// descendant.
+func (model *Model) GetBalanceHistory(orgId string, userId string, accountId string, options *types.ReportOptions) (*types.BalanceHistory, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	interval := options.Interval
+
+	if interval == "" {
+		interval = "day"
+	}
+
+	if interval != "day" && interval != "week" && interval != "month" {
+		return nil, errors.New("interval must be day, week or month")
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := getReportPeriod(&types.DateRange{Start: options.Start, End: options.End}, location, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(userAccounts)
+	node := accountMap[accountId]
+
+	if node == nil {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", accountId))
+	}
+
+	dates := make([]time.Time, 0)
+
+	for i := 1; ; i++ {
+		date, _ := addReportInterval(period.Start, interval, i)
+
+		if date.After(period.End) {
+			date = period.End
+		}
+
+		dates = append(dates, date)
+
+		if !date.Before(period.End) {
+			break
+		}
+
+		if len(dates) >= maxBalanceHistoryPoints {
+			return nil, errors.New(fmt.Sprintf("balance history is limited to %d points", maxBalanceHistoryPoints))
+		}
+	}
+
+	accounts := append([]*types.Account{node.Account}, model.getChildren(accountId, accountMap)...)
+
+	// dates are sorted so bucket i holds the splits between point i-1 and point i
+	balances, err := model.db.GetPeriodBalances(accounts, dates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	history := &types.BalanceHistory{
+		OrgId:     orgId,
+		AccountId: accountId,
+		Currency:  node.Account.Currency,
+		Precision: node.Account.Precision,
+		Interval:  interval,
+		Points:    make([]*types.BalancePoint, len(dates)),
+	}
+
+	var balance int64
+	var nativeBalance int64
+
+	for i, date := range dates {
+		for _, account := range accounts {
+			buckets, ok := balances[account.Id]
+
+			if !ok {
+				continue
+			}
+
+			if account.Currency == node.Account.Currency {
+				balance += buckets[i].Balance
+			}
+
+			nativeBalance += buckets[i].NativeBalance
+		}
+
+		history.Points[i] = &types.BalancePoint{
+			Date:          date,
+			Balance:       balance,
+			NativeBalance: nativeBalance,
+		}
+	}
+
+	return history, nil
+}
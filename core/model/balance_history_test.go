@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBalanceHistory(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	tests := map[string]struct {
+		options  *types.ReportOptions
+		dates    []time.Time
+		balances []int64
+	}{
+		"monthly": {
+			options: &types.ReportOptions{Start: "2018-01-01", End: "2018-04-30", Interval: "month"},
+			dates: []time.Time{
+				time.Date(2018, time.February, 1, 0, 0, 0, 0, location),
+				time.Date(2018, time.March, 1, 0, 0, 0, 0, location),
+				time.Date(2018, time.April, 1, 0, 0, 0, 0, location),
+				time.Date(2018, time.May, 1, 0, 0, 0, 0, location),
+			},
+			balances: []int64{2000, 2000, 4000, 4000},
+		},
+		"weekly with a partial week": {
+			options: &types.ReportOptions{Start: "2018-03-10", End: "2018-03-20", Interval: "week"},
+			dates: []time.Time{
+				time.Date(2018, time.March, 17, 0, 0, 0, 0, location),
+				time.Date(2018, time.March, 21, 0, 0, 0, 0, location),
+			},
+			balances: []int64{4000, 4000},
+		},
+		"daily by default": {
+			options: &types.ReportOptions{Start: "2018-03-14", End: "2018-03-15"},
+			dates: []time.Time{
+				time.Date(2018, time.March, 15, 0, 0, 0, 0, location),
+				time.Date(2018, time.March, 16, 0, 0, 0, 0, location),
+			},
+			balances: []int64{2000, 4000},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+		model := NewModel(td, nil, types.Config{})
+
+		// Assets rolls up Checking and Savings
+		history, err := model.GetBalanceHistory("1", "1", "2", test.options)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(test.dates), len(history.Points))
+
+		for i, point := range history.Points {
+			assert.True(t, test.dates[i].Equal(point.Date))
+			assert.Equal(t, test.balances[i], point.Balance)
+			assert.Equal(t, test.balances[i], point.NativeBalance)
+		}
+
+		assert.Equal(t, 1, td.queries)
+	}
+}
+
+func TestGetBalanceHistoryErrors(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		accountId string
+		options   *types.ReportOptions
+	}{
+		"bad interval": {
+			err:       errors.New("interval must be day, week or month"),
+			accountId: "2",
+			options:   &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31", Interval: "year"},
+		},
+		"missing dates": {
+			err:       errors.New("start and end required"),
+			accountId: "2",
+			options:   &types.ReportOptions{End: "2018-12-31"},
+		},
+		"too many points": {
+			err:       errors.New("balance history is limited to 1000 points"),
+			accountId: "2",
+			options:   &types.ReportOptions{Start: "2010-01-01", End: "2018-12-31"},
+		},
+		"unknown account": {
+			err:       errors.New("user does not have permission to access account 99"),
+			accountId: "99",
+			options:   &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+		model := NewModel(td, nil, types.Config{})
+
+		_, err := model.GetBalanceHistory("1", "1", test.accountId, test.options)
+		assert.Equal(t, test.err, err)
+	}
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"github.com/openaccounting/oa-server/core/model/types"
 )
 
@@ -9,8 +10,17 @@ type BudgetInterface interface {
 	GetBudget(string, string) (*types.Budget, error)
 	CreateBudget(*types.Budget, string) error
 	DeleteBudget(string, string) error
+	GetNamedBudgets(string, string) ([]*types.Budget, error)
+	GetNamedBudget(string, string, string) (*types.Budget, error)
+	CreateNamedBudget(*types.Budget, string) error
+	UpdateNamedBudget(*types.Budget, string) error
+	DeleteNamedBudget(string, string, string) error
 }
 
+// maxBudgetPeriods matches the column limit on reports so a budget can always
+// be compared against actuals in a single request
+const maxBudgetPeriods = maxReportPeriods
+
 func (model *Model) GetBudget(orgId string, userId string) (*types.Budget, error) {
 	belongs, err := model.UserBelongsToOrg(userId, orgId)
 
@@ -56,3 +66,215 @@ func (model *Model) DeleteBudget(orgId string, userId string) error {
 
 	return model.db.DeleteBudget(orgId)
 }
+
+func (model *Model) GetNamedBudgets(orgId string, userId string) ([]*types.Budget, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	budgets, err := model.db.GetNamedBudgets(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap, err := model.getUserAccountMap(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, budget := range budgets {
+		budget.Items, _ = splitBudgetItems(budget.Items, accountMap)
+	}
+
+	return budgets, nil
+}
+
+func (model *Model) GetNamedBudget(orgId string, id string, userId string) (*types.Budget, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	budget, err := model.db.GetNamedBudget(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if budget.OrgId != orgId {
+		return nil, errors.New("Budget not found")
+	}
+
+	accountMap, err := model.getUserAccountMap(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	budget.Items, _ = splitBudgetItems(budget.Items, accountMap)
+
+	return budget, nil
+}
+
+func (model *Model) CreateNamedBudget(budget *types.Budget, userId string) error {
+	err := model.checkNamedBudget(budget, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertNamedBudget(budget)
+}
+
+func (model *Model) UpdateNamedBudget(budget *types.Budget, userId string) error {
+	err := model.checkNamedBudget(budget, userId)
+
+	if err != nil {
+		return err
+	}
+
+	original, err := model.db.GetNamedBudget(budget.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if original.OrgId != budget.OrgId {
+		return errors.New("Budget not found")
+	}
+
+	budget.Inserted = original.Inserted
+
+	// The user only sees items for accounts they have access to, so keep the
+	// rest as they were instead of deleting them
+	accountMap, err := model.getUserAccountMap(budget.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	_, hidden := splitBudgetItems(original.Items, accountMap)
+
+	for _, item := range hidden {
+		if item.Period < budget.Periods {
+			budget.Items = append(budget.Items, item)
+		}
+	}
+
+	return model.db.UpdateNamedBudget(budget)
+}
+
+func (model *Model) DeleteNamedBudget(orgId string, id string, userId string) error {
+	// GetNamedBudget checks that the budget belongs to the org
+	_, err := model.GetNamedBudget(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.DeleteNamedBudget(id)
+}
+
+func (model *Model) checkNamedBudget(budget *types.Budget, userId string) error {
+	if budget.Id == "" {
+		return errors.New("id required")
+	}
+
+	if budget.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if budget.Name == "" {
+		return errors.New("name required")
+	}
+
+	if budget.Interval != "month" && budget.Interval != "quarter" {
+		return errors.New("interval must be month or quarter")
+	}
+
+	if budget.Start.IsZero() {
+		return errors.New("start required")
+	}
+
+	if budget.Periods < 1 || budget.Periods > maxBudgetPeriods {
+		return errors.New(fmt.Sprintf("periods must be between 1 and %d", maxBudgetPeriods))
+	}
+
+	belongs, err := model.UserBelongsToOrg(userId, budget.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	if belongs == false {
+		return errors.New("User does not belong to org")
+	}
+
+	accountMap, err := model.getUserAccountMap(budget.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+
+	for _, item := range budget.Items {
+		if _, ok := accountMap[item.AccountId]; !ok {
+			return errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", item.AccountId))
+		}
+
+		if item.Period < 0 || item.Period >= budget.Periods {
+			return errors.New(fmt.Sprintf("invalid period %d for account %s", item.Period, item.AccountId))
+		}
+
+		key := fmt.Sprintf("%s-%d", item.AccountId, item.Period)
+
+		if seen[key] {
+			return errors.New(fmt.Sprintf("duplicate period %d for account %s", item.Period, item.AccountId))
+		}
+
+		seen[key] = true
+	}
+
+	return nil
+}
+
+func (model *Model) getUserAccountMap(orgId string, userId string) (map[string]*types.AccountNode, error) {
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model.makeAccountMap(accounts), nil
+}
+
+// splitBudgetItems separates items for accounts the user has access to from
+// the ones they don't
+func splitBudgetItems(items []*types.BudgetItem, accountMap map[string]*types.AccountNode) ([]*types.BudgetItem, []*types.BudgetItem) {
+	visible := make([]*types.BudgetItem, 0, len(items))
+	hidden := []*types.BudgetItem{}
+
+	for _, item := range items {
+		if _, ok := accountMap[item.AccountId]; ok {
+			visible = append(visible, item)
+		} else {
+			hidden = append(hidden, item)
+		}
+	}
+
+	return visible, hidden
+}
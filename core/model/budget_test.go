@@ -0,0 +1,249 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdBudget struct {
+	*TdReport
+	budgets map[string]*types.Budget
+}
+
+func (td *TdBudget) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "1"}}, nil
+}
+
+func (td *TdBudget) GetNamedBudgets(orgId string) ([]*types.Budget, error) {
+	budgets := []*types.Budget{}
+
+	for _, budget := range td.budgets {
+		if budget.OrgId == orgId {
+			budgets = append(budgets, budget)
+		}
+	}
+
+	return budgets, nil
+}
+
+func (td *TdBudget) GetNamedBudget(id string) (*types.Budget, error) {
+	budget, ok := td.budgets[id]
+
+	if !ok {
+		return nil, errors.New("Budget not found")
+	}
+
+	return budget, nil
+}
+
+func (td *TdBudget) InsertNamedBudget(budget *types.Budget) error {
+	td.budgets[budget.Id] = budget
+	return nil
+}
+
+func (td *TdBudget) UpdateNamedBudget(budget *types.Budget) error {
+	td.budgets[budget.Id] = budget
+	return nil
+}
+
+func (td *TdBudget) DeleteNamedBudget(id string) error {
+	delete(td.budgets, id)
+	return nil
+}
+
+func newTdBudget() *TdBudget {
+	return &TdBudget{
+		&TdReport{permissioned: []string{"1"}},
+		map[string]*types.Budget{
+			"2": &types.Budget{Id: "2", OrgId: "2", Name: "Other Org"},
+		},
+	}
+}
+
+func getTestBudget() *types.Budget {
+	return &types.Budget{
+		Id:       "1",
+		OrgId:    "1",
+		Name:     "FY2018",
+		Interval: "month",
+		Start:    time.Date(2018, time.January, 1, 5, 0, 0, 0, time.UTC),
+		Periods:  12,
+		Items: []*types.BudgetItem{
+			&types.BudgetItem{AccountId: "11", Period: 0, Amount: 30000},
+			&types.BudgetItem{AccountId: "11", Period: 1, Amount: 25000},
+			&types.BudgetItem{AccountId: "10", Period: 0, Amount: 500000},
+		},
+	}
+}
+
+func TestCreateNamedBudget(t *testing.T) {
+	tests := map[string]struct {
+		update func(*types.Budget)
+		err    error
+	}{
+		"successful": {
+			update: func(b *types.Budget) {},
+			err:    nil,
+		},
+		"quarterly": {
+			update: func(b *types.Budget) { b.Interval = "quarter"; b.Periods = 4 },
+			err:    nil,
+		},
+		"missing name": {
+			update: func(b *types.Budget) { b.Name = "" },
+			err:    errors.New("name required"),
+		},
+		"bad interval": {
+			update: func(b *types.Budget) { b.Interval = "week" },
+			err:    errors.New("interval must be month or quarter"),
+		},
+		"missing start": {
+			update: func(b *types.Budget) { b.Start = time.Time{} },
+			err:    errors.New("start required"),
+		},
+		"no periods": {
+			update: func(b *types.Budget) { b.Periods = 0 },
+			err:    errors.New("periods must be between 1 and 120"),
+		},
+		"period out of range": {
+			update: func(b *types.Budget) { b.Items[1].Period = 12 },
+			err:    errors.New("invalid period 12 for account 11"),
+		},
+		"duplicate period": {
+			update: func(b *types.Budget) { b.Items[1].Period = 0 },
+			err:    errors.New("duplicate period 0 for account 11"),
+		},
+		"unknown account": {
+			update: func(b *types.Budget) { b.Items[0].AccountId = "99" },
+			err:    errors.New("user does not have permission to access account 99"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdBudget()
+		model := NewModel(td, nil, types.Config{})
+
+		budget := getTestBudget()
+		test.update(budget)
+
+		err := model.CreateNamedBudget(budget, "1")
+
+		assert.Equal(t, test.err, err)
+
+		if test.err == nil {
+			assert.Equal(t, budget, td.budgets["1"])
+		}
+	}
+}
+
+func TestUpdateNamedBudget(t *testing.T) {
+	td := newTdBudget()
+	model := NewModel(td, nil, types.Config{})
+
+	original := getTestBudget()
+	original.Inserted = time.Date(2018, time.January, 2, 0, 0, 0, 0, time.UTC)
+	td.budgets["1"] = original
+
+	budget := getTestBudget()
+	budget.Name = "FY2018 Revised"
+
+	err := model.UpdateNamedBudget(budget, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "FY2018 Revised", td.budgets["1"].Name)
+	assert.Equal(t, original.Inserted, td.budgets["1"].Inserted)
+
+	// budget belonging to another org
+	budget = getTestBudget()
+	budget.Id = "2"
+
+	err = model.UpdateNamedBudget(budget, "1")
+
+	assert.Equal(t, errors.New("Budget not found"), err)
+	assert.Equal(t, "Other Org", td.budgets["2"].Name)
+}
+
+func TestGetNamedBudgetPermissions(t *testing.T) {
+	td := newTdBudget()
+	model := NewModel(td, nil, types.Config{})
+
+	td.budgets["1"] = getTestBudget()
+
+	// only has access to Expenses
+	td.permissioned = []string{"6"}
+
+	budget, err := model.GetNamedBudget("1", "1", "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.BudgetItem{
+		&types.BudgetItem{AccountId: "11", Period: 0, Amount: 30000},
+		&types.BudgetItem{AccountId: "11", Period: 1, Amount: 25000},
+	}, budget.Items)
+
+	td.budgets["1"] = getTestBudget()
+
+	budgets, err := model.GetNamedBudgets("1", "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(budgets))
+	assert.Equal(t, 2, len(budgets[0].Items))
+}
+
+func TestUpdateNamedBudgetPermissions(t *testing.T) {
+	td := newTdBudget()
+	model := NewModel(td, nil, types.Config{})
+
+	td.budgets["1"] = getTestBudget()
+
+	// only has access to Expenses
+	td.permissioned = []string{"6"}
+
+	budget := getTestBudget()
+	budget.Items = []*types.BudgetItem{
+		&types.BudgetItem{AccountId: "11", Period: 0, Amount: 35000},
+	}
+
+	err := model.UpdateNamedBudget(budget, "1")
+
+	assert.Nil(t, err)
+
+	// Salary is kept even though the user can't see it
+	assert.Equal(t, []*types.BudgetItem{
+		&types.BudgetItem{AccountId: "11", Period: 0, Amount: 35000},
+		&types.BudgetItem{AccountId: "10", Period: 0, Amount: 500000},
+	}, td.budgets["1"].Items)
+
+	// hidden items past the last period are dropped
+	td.budgets["1"].Items[1].Period = 6
+	budget = getTestBudget()
+	budget.Periods = 6
+	budget.Items = []*types.BudgetItem{}
+
+	err = model.UpdateNamedBudget(budget, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.BudgetItem{}, td.budgets["1"].Items)
+}
+
+func TestDeleteNamedBudget(t *testing.T) {
+	td := newTdBudget()
+	model := NewModel(td, nil, types.Config{})
+
+	td.budgets["1"] = getTestBudget()
+
+	err := model.DeleteNamedBudget("1", "2", "1")
+
+	assert.Equal(t, errors.New("Budget not found"), err)
+	assert.Contains(t, td.budgets, "2")
+
+	err = model.DeleteNamedBudget("1", "1", "1")
+
+	assert.Nil(t, err)
+	assert.NotContains(t, td.budgets, "1")
+}
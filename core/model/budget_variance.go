@@ -0,0 +1,167 @@
+package model
+
+import (
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+)
+
+type BudgetVarianceInterface interface {
+	GetBudgetVariance(string, string, string) (*types.BudgetVariance, error)
+}
+
+// GetBudgetVariance compares a named budget against actual split sums for each
+// of its periods. Every budgeted account gets a line along with its ancestors
+// so that budgets and actuals roll up the account tree. Amounts are in the
+// Org's currency and shown as positive on the account's normal side.
+func (model *Model) GetBudgetVariance(orgId string, userId string, budgetId string) (*types.BudgetVariance, error) {
+	budget, err := model.GetNamedBudget(orgId, budgetId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := getBudgetPeriods(budget, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+
+	// budgeted amounts rolled up to every ancestor below the root
+	budgeted := make(map[string][]int64)
+
+	for _, item := range budget.Items {
+		for node := accountMap[item.AccountId]; node != nil && node.Parent != nil; node = node.Parent {
+			if budgeted[node.Account.Id] == nil {
+				budgeted[node.Account.Id] = make([]int64, len(periods))
+			}
+
+			budgeted[node.Account.Id][item.Period] += item.Amount
+		}
+	}
+
+	times := make([]time.Time, 0, len(periods)+1)
+
+	for _, period := range periods {
+		times = append(times, period.Start)
+	}
+
+	times = append(times, periods[len(periods)-1].End)
+
+	balances, err := model.getPeriodBalances(accounts, times)
+
+	if err != nil {
+		return nil, err
+	}
+
+	closingTransactions, err := model.getClosingTransactions(orgId, periods)
+
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]map[string]*reportBalance, len(periods))
+
+	for i, period := range periods {
+		leafBalances := balances.between(period.Start, period.End)
+		model.removeClosingEntries(leafBalances, closingTransactions, period)
+		columns[i] = model.rollUpBalances(accounts, accountMap, leafBalances)
+	}
+
+	variance := &types.BudgetVariance{
+		OrgId:    orgId,
+		BudgetId: budget.Id,
+		Name:     budget.Name,
+		Periods:  periods,
+		Lines:    make([]*types.BudgetVarianceLine, 0),
+	}
+
+	model.walkAccounts(accountMap, func(account *types.Account, depth int) {
+		amounts, ok := budgeted[account.Id]
+
+		if !ok {
+			return
+		}
+
+		sign := int64(1)
+
+		if !account.DebitBalance {
+			sign = -1
+		}
+
+		line := &types.BudgetVarianceLine{
+			AccountId:    account.Id,
+			Name:         account.Name,
+			Parent:       account.Parent,
+			Depth:        depth - 1,
+			DebitBalance: account.DebitBalance,
+			Columns:      make([]*types.BudgetVarianceColumn, len(periods)),
+		}
+
+		for i, totals := range columns {
+			column := &types.BudgetVarianceColumn{
+				Actual: sign * totals[account.Id].nativeBalance,
+				Budget: amounts[i],
+			}
+
+			column.Variance = column.Actual - column.Budget
+
+			if column.Budget != 0 {
+				percent := float64(column.Variance) / float64(column.Budget) * 100
+				column.Percent = &percent
+			}
+
+			line.Columns[i] = column
+		}
+
+		variance.Lines = append(variance.Lines, line)
+	})
+
+	return variance, nil
+}
+
+// getBudgetPeriods splits a budget into its periods starting at the budget's
+// start in the Org's timezone
+func getBudgetPeriods(budget *types.Budget, location *time.Location) ([]*types.ReportPeriod, error) {
+	start := budget.Start.In(location)
+	periods := make([]*types.ReportPeriod, budget.Periods)
+
+	for i := range periods {
+		periodStart, err := addReportInterval(start, budget.Interval, i)
+
+		if err != nil {
+			return nil, err
+		}
+
+		periodEnd, err := addReportInterval(start, budget.Interval, i+1)
+
+		if err != nil {
+			return nil, err
+		}
+
+		periods[i] = &types.ReportPeriod{Start: periodStart, End: periodEnd}
+	}
+
+	return periods, nil
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBudgetVariance(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	td := newTdBudget()
+	td.splits = getReportTestSplits()
+	td.budgets["1"] = &types.Budget{
+		Id:       "1",
+		OrgId:    "1",
+		Name:     "Q1 2018",
+		Interval: "month",
+		Start:    time.Date(2018, time.January, 1, 0, 0, 0, 0, location),
+		Periods:  3,
+		Items: []*types.BudgetItem{
+			&types.BudgetItem{AccountId: "11", Period: 0, Amount: 100},
+			&types.BudgetItem{AccountId: "11", Period: 2, Amount: 400},
+			&types.BudgetItem{AccountId: "10", Period: 2, Amount: 2500},
+		},
+	}
+
+	model := NewModel(td, nil, types.Config{})
+
+	variance, err := model.GetBudgetVariance("1", "1", "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Q1 2018", variance.Name)
+	assert.Equal(t, 3, len(variance.Periods))
+	assert.Equal(t, time.Date(2018, time.March, 1, 0, 0, 0, 0, location), variance.Periods[2].Start)
+	assert.Equal(t, time.Date(2018, time.April, 1, 0, 0, 0, 0, location), variance.Periods[2].End)
+
+	// budgeted accounts and their ancestors below root
+	names := make([]string, 0)
+
+	for _, line := range variance.Lines {
+		names = append(names, line.Name)
+	}
+
+	assert.Equal(t, []string{"Expenses", "Groceries", "Income", "Salary"}, names)
+
+	expenses := variance.Lines[0]
+	groceries := variance.Lines[1]
+	salary := variance.Lines[3]
+
+	assert.Equal(t, 0, expenses.Depth)
+	assert.Equal(t, 1, groceries.Depth)
+
+	// January: budgeted but nothing spent
+	assert.Equal(t, int64(0), groceries.Columns[0].Actual)
+	assert.Equal(t, int64(100), groceries.Columns[0].Budget)
+	assert.Equal(t, int64(-100), groceries.Columns[0].Variance)
+	assert.Equal(t, float64(-100), *groceries.Columns[0].Percent)
+
+	// February: nothing budgeted
+	assert.Equal(t, int64(0), groceries.Columns[1].Budget)
+	assert.Nil(t, groceries.Columns[1].Percent)
+
+	// March: over budget on groceries, rolled up to expenses
+	assert.Equal(t, int64(500), groceries.Columns[2].Actual)
+	assert.Equal(t, int64(100), groceries.Columns[2].Variance)
+	assert.Equal(t, float64(25), *groceries.Columns[2].Percent)
+	assert.Equal(t, int64(500), expenses.Columns[2].Actual)
+	assert.Equal(t, int64(400), expenses.Columns[2].Budget)
+
+	// March: salary is a credit balance account shown as positive
+	assert.Equal(t, int64(2000), salary.Columns[2].Actual)
+	assert.Equal(t, int64(2500), salary.Columns[2].Budget)
+	assert.Equal(t, int64(-500), salary.Columns[2].Variance)
+	assert.Equal(t, float64(-20), *salary.Columns[2].Percent)
+}
+
+func TestGetBudgetVarianceNotFound(t *testing.T) {
+	td := newTdBudget()
+	model := NewModel(td, nil, types.Config{})
+
+	// budget 2 belongs to another org
+	_, err := model.GetBudgetVariance("1", "1", "2")
+
+	assert.Equal(t, errors.New("Budget not found"), err)
+}
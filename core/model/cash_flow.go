@@ -0,0 +1,209 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+)
+
+type CashFlowInterface interface {
+	GetCashFlowStatement(string, string, *types.ReportOptions) (*types.CashFlowStatement, error)
+}
+
+// GetCashFlowStatement reports cash movements using the direct method. Every
+// split on a cash account is explained by the other splits of its
+// transaction. Each of those counter-accounts gets a line in a section
+// according to the top level account it falls under: Income and Expenses are
+// operating, Assets are investing and Liabilities and Equity are financing.
+// Transfers between cash accounts cancel out and are not shown.
+func (model *Model) GetCashFlowStatement(orgId string, userId string, options *types.ReportOptions) (*types.CashFlowStatement, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := model.getReportPeriods(org, options, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+	cashAccounts := model.getCashAccounts(accounts, accountMap)
+
+	statement := &types.CashFlowStatement{
+		OrgId:       orgId,
+		Periods:     periods,
+		OpeningCash: make([]int64, len(periods)),
+		Operating:   newCashFlowSection(len(periods)),
+		Investing:   newCashFlowSection(len(periods)),
+		Financing:   newCashFlowSection(len(periods)),
+		Other:       newCashFlowSection(len(periods)),
+		NetChange:   make([]int64, len(periods)),
+		ClosingCash: make([]int64, len(periods)),
+	}
+
+	times := make([]time.Time, 0, len(periods)*2)
+	start := periods[0].Start
+	end := periods[0].End
+
+	for _, period := range periods {
+		times = append(times, period.Start, period.End)
+
+		if period.Start.Before(start) {
+			start = period.Start
+		}
+
+		if period.End.After(end) {
+			end = period.End
+		}
+	}
+
+	balances, err := model.getPeriodBalances(cashAccounts, times)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, period := range periods {
+		opening := balances.asOf(period.Start)
+		closing := balances.asOf(period.End)
+
+		for _, account := range cashAccounts {
+			if balance, ok := opening[account.Id]; ok {
+				statement.OpeningCash[i] += balance.nativeBalance
+			}
+
+			if balance, ok := closing[account.Id]; ok {
+				statement.ClosingCash[i] += balance.nativeBalance
+			}
+		}
+	}
+
+	cashAccountIds := make([]string, len(cashAccounts))
+	isCash := make(map[string]bool)
+
+	for i, account := range cashAccounts {
+		cashAccountIds[i] = account.Id
+		isCash[account.Id] = true
+	}
+
+	// Fetch every period's transactions at once and sort them into periods below
+	transactions, err := model.db.GetTransactionsByOrg(orgId, &types.QueryOptions{
+		StartDate: int(util.TimeToMs(start)),
+		EndDate:   int(util.TimeToMs(end)),
+	}, cashAccountIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]*types.CashFlowLine)
+
+	for _, transaction := range transactions {
+		for i, period := range periods {
+			if transaction.Date.Before(period.Start) || !transaction.Date.Before(period.End) {
+				continue
+			}
+
+			for _, split := range transaction.Splits {
+				if isCash[split.AccountId] {
+					continue
+				}
+
+				// cash moves opposite to the counter-account
+				amount := -split.NativeAmount
+				section, line := model.getCashFlowLine(statement, lines, accountMap, split.AccountId, len(periods))
+
+				line.NativeAmounts[i] += amount
+				section.Totals[i] += amount
+				statement.NetChange[i] += amount
+			}
+		}
+	}
+
+	for _, section := range []*types.CashFlowSection{statement.Operating, statement.Investing, statement.Financing, statement.Other} {
+		sort.Slice(section.Lines, func(i, j int) bool {
+			return section.Lines[i].Name < section.Lines[j].Name
+		})
+	}
+
+	return statement, nil
+}
+
+// getCashAccounts returns accounts marked as cash along with their descendants
+func (model *Model) getCashAccounts(accounts []*types.Account, accountMap map[string]*types.AccountNode) []*types.Account {
+	cashAccounts := make([]*types.Account, 0)
+
+	for _, account := range accounts {
+		for node := accountMap[account.Id]; node != nil; node = node.Parent {
+			if node.Account.Cash {
+				cashAccounts = append(cashAccounts, account)
+				break
+			}
+		}
+	}
+
+	return cashAccounts
+}
+
+// getCashFlowLine finds or creates the line for a counter-account. Accounts
+// the user cannot see are combined into a single line in the other section.
+func (model *Model) getCashFlowLine(statement *types.CashFlowStatement, lines map[string]*types.CashFlowLine, accountMap map[string]*types.AccountNode, accountId string, periods int) (*types.CashFlowSection, *types.CashFlowLine) {
+	section := statement.Other
+	name := "Other"
+	node := accountMap[accountId]
+
+	if node == nil {
+		accountId = ""
+	} else {
+		name = node.Account.Name
+
+		top := node
+
+		for top.Parent != nil && top.Parent.Parent != nil {
+			top = top.Parent
+		}
+
+		switch top.Account.Name {
+		case "Income", "Expenses":
+			section = statement.Operating
+		case "Assets":
+			section = statement.Investing
+		case "Liabilities", "Equity":
+			section = statement.Financing
+		}
+	}
+
+	line, ok := lines[accountId]
+
+	if !ok {
+		line = &types.CashFlowLine{
+			AccountId:     accountId,
+			Name:          name,
+			NativeAmounts: make([]int64, periods),
+		}
+
+		lines[accountId] = line
+		section.Lines = append(section.Lines, line)
+	}
+
+	return section, line
+}
+
+func newCashFlowSection(periods int) *types.CashFlowSection {
+	return &types.CashFlowSection{
+		Lines:  make([]*types.CashFlowLine, 0),
+		Totals: make([]int64, periods),
+	}
+}
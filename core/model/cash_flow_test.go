@@ -0,0 +1,130 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdCashFlow struct {
+	*TdReport
+	transactions []*types.Transaction
+}
+
+func (td *TdCashFlow) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
+	accounts := getReportTestAccounts()
+
+	for _, account := range accounts {
+		// Checking and Savings
+		if account.Id == "7" || account.Id == "8" {
+			account.Cash = true
+		}
+	}
+
+	return accounts, nil
+}
+
+func (td *TdCashFlow) GetTransactionsByOrg(orgId string, options *types.QueryOptions, accountIds []string) ([]*types.Transaction, error) {
+	transactions := make([]*types.Transaction, 0)
+
+	for _, transaction := range td.transactions {
+		date := int(util.TimeToMs(transaction.Date))
+
+		if date < options.StartDate || date >= options.EndDate {
+			continue
+		}
+
+		for _, split := range transaction.Splits {
+			if contains(accountIds, split.AccountId) {
+				transactions = append(transactions, transaction)
+				break
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+func contains(list []string, item string) bool {
+	for _, value := range list {
+		if value == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getCashFlowTestTransactions() []*types.Transaction {
+	location, _ := time.LoadLocation("America/New_York")
+
+	transaction := func(year int, month time.Month, day int, description string, splits ...*types.Split) *types.Transaction {
+		return &types.Transaction{
+			Id:          description,
+			OrgId:       "1",
+			Date:        time.Date(year, month, day, 0, 0, 0, 0, location),
+			Description: description,
+			Splits:      splits,
+		}
+	}
+
+	split := func(accountId string, amount int64) *types.Split {
+		return &types.Split{AccountId: accountId, Amount: amount, NativeAmount: amount}
+	}
+
+	return []*types.Transaction{
+		transaction(2017, time.December, 15, "owner contribution", split("7", 1000), split("4", -1000)),
+		transaction(2018, time.January, 10, "paycheck", split("7", 3000), split("10", -3000)),
+		transaction(2018, time.February, 5, "groceries", split("7", -500), split("11", 500)),
+		transaction(2018, time.February, 10, "transfer", split("7", -1000), split("8", 1000)),
+		transaction(2018, time.February, 20, "cash advance", split("7", 400), split("9", -400)),
+		// account 99 is not visible to the user
+		transaction(2018, time.February, 25, "hidden", split("7", -200), split("99", 200)),
+	}
+}
+
+func TestGetCashFlowStatement(t *testing.T) {
+	transactions := getCashFlowTestTransactions()
+	splits := make([]*reportTestSplit, 0)
+
+	for _, transaction := range transactions {
+		for _, split := range transaction.Splits {
+			splits = append(splits, &reportTestSplit{split.AccountId, transaction.Date, split.Amount})
+		}
+	}
+
+	td := &TdCashFlow{
+		TdReport:     &TdReport{permissioned: []string{"1"}, splits: splits},
+		transactions: transactions,
+	}
+
+	model := NewModel(td, nil, types.Config{})
+
+	statement, err := model.GetCashFlowStatement("1", "1", &types.ReportOptions{Start: "2018-01-01", End: "2018-02-28", Interval: "month"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(statement.Periods))
+
+	assert.Equal(t, []int64{1000, 4000}, statement.OpeningCash)
+	assert.Equal(t, []int64{3000, -500}, statement.Operating.Totals)
+	assert.Equal(t, []int64{0, 0}, statement.Investing.Totals)
+	assert.Equal(t, []int64{0, 400}, statement.Financing.Totals)
+	assert.Equal(t, []int64{0, -200}, statement.Other.Totals)
+	assert.Equal(t, []int64{3000, -300}, statement.NetChange)
+	assert.Equal(t, []int64{4000, 3700}, statement.ClosingCash)
+
+	// lines are sorted by name and transfers between cash accounts are left out
+	assert.Equal(t, 2, len(statement.Operating.Lines))
+	assert.Equal(t, "Groceries", statement.Operating.Lines[0].Name)
+	assert.Equal(t, []int64{0, -500}, statement.Operating.Lines[0].NativeAmounts)
+	assert.Equal(t, "Salary", statement.Operating.Lines[1].Name)
+	assert.Equal(t, "Credit Card", statement.Financing.Lines[0].Name)
+	assert.Equal(t, "", statement.Other.Lines[0].AccountId)
+
+	for i := range statement.Periods {
+		assert.Equal(t, statement.ClosingCash[i], statement.OpeningCash[i]+statement.NetChange[i])
+	}
+}
@@ -0,0 +1,347 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"github.com/openaccounting/oa-server/core/ws"
+)
+
+type ClosingInterface interface {
+	GetClosings(string, string) ([]*types.Closing, error)
+	CreateClosing(*types.Closing, string) error
+	DeleteClosing(string, string, string) error
+}
+
+func (model *Model) GetClosings(orgId string, userId string) ([]*types.Closing, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	return model.db.GetClosings(orgId)
+}
+
+// CreateClosing closes a fiscal year by moving the balance of every Income
+// and Expense account for the year into closing.EquityAccountId. The closing
+// transaction is dated on the last millisecond of the fiscal year. Fiscal
+// years are named after the calendar year they end in.
+func (model *Model) CreateClosing(closing *types.Closing, userId string) error {
+	if closing.Id == "" {
+		return errors.New("id required")
+	}
+
+	if closing.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if closing.Year == 0 {
+		return errors.New("year required")
+	}
+
+	if closing.EquityAccountId == "" {
+		return errors.New("equityAccountId required")
+	}
+
+	isAdmin, err := model.isOrgAdmin(closing.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if isAdmin == false {
+		return errors.New("Must be org admin to close a fiscal year")
+	}
+
+	org, err := model.GetOrg(closing.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return err
+	}
+
+	closings, err := model.db.GetClosings(closing.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range closings {
+		if existing.Year == closing.Year {
+			return errors.New(fmt.Sprintf("fiscal year %d is already closed", closing.Year))
+		}
+	}
+
+	// the fiscal year ending in closing.Year
+	month := getFiscalYearStartMonth(org)
+	closing.Start = time.Date(closing.Year-1, month, 1, 0, 0, 0, 0, location)
+
+	if month == time.January {
+		closing.Start = time.Date(closing.Year, month, 1, 0, 0, 0, 0, location)
+	}
+
+	closing.End = closing.Start.AddDate(1, 0, 0)
+	closing.UserId = userId
+
+	// closing covers every account in the org regardless of permissions
+	accounts, err := model.db.GetAccountsByOrgId(closing.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+
+	equityNode := model.getTopLevelAccountByName(accountMap, "Equity")
+	incomeNode := model.getTopLevelAccountByName(accountMap, "Income")
+	expensesNode := model.getTopLevelAccountByName(accountMap, "Expenses")
+
+	if equityNode == nil || incomeNode == nil || expensesNode == nil {
+		return errors.New("Equity, Income and Expenses accounts are required")
+	}
+
+	equityAccount := model.getAccountFromList(model.getChildren(equityNode.Account.Id, accountMap), closing.EquityAccountId)
+
+	if equityAccount == nil {
+		return errors.New("equityAccountId must be an Equity account")
+	}
+
+	if equityAccount.HasChildren == true {
+		return errors.New("Cannot use parent account for split")
+	}
+
+	if equityAccount.Currency != org.Currency {
+		return errors.New("equity account must be in the org currency")
+	}
+
+	transaction, err := model.getClosingTransaction(closing, model.getSubtreeAccounts(accountMap, incomeNode, expensesNode))
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkLocked(closing.OrgId, userId, transaction.Date)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.db.InsertClosing(closing, transaction)
+
+	if err != nil {
+		return err
+	}
+
+	// Notify web socket subscribers
+	// TODO only get user ids that have permission to access transaction
+	userIds, err2 := model.db.GetOrgUserIds(closing.OrgId)
+
+	if err2 == nil {
+		ws.PushTransaction(transaction, userIds, "create")
+	}
+
+	return nil
+}
+
+// DeleteClosing reverses a closing by deleting its closing transaction
+func (model *Model) DeleteClosing(orgId string, id string, userId string) error {
+	isAdmin, err := model.isOrgAdmin(orgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if isAdmin == false {
+		return errors.New("Must be org admin to reverse a closing")
+	}
+
+	closing, err := model.db.GetClosing(id)
+
+	if err != nil {
+		return err
+	}
+
+	if closing.OrgId != orgId {
+		return errors.New("Closing not found")
+	}
+
+	transaction, err := model.getTransactionById(closing.TransactionId)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkLocked(orgId, userId, transaction.Date)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.db.DeleteClosing(closing)
+
+	if err != nil {
+		return err
+	}
+
+	// Notify web socket subscribers
+	// TODO only get user ids that have permission to access transaction
+	userIds, err2 := model.db.GetOrgUserIds(orgId)
+
+	if err2 == nil {
+		ws.PushTransaction(transaction, userIds, "delete")
+	}
+
+	return nil
+}
+
+// getClosingTransaction builds a transaction that zeroes the balance of each
+// of earningsAccounts for the fiscal year of closing
+func (model *Model) getClosingTransaction(closing *types.Closing, earningsAccounts []*types.Account) (*types.Transaction, error) {
+	balances, err := model.getPeriodBalances(earningsAccounts, []time.Time{closing.Start, closing.End})
+
+	if err != nil {
+		return nil, err
+	}
+
+	yearBalances := balances.between(closing.Start, closing.End)
+
+	id, err := util.NewGuid()
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	transaction := &types.Transaction{
+		Id:          id,
+		OrgId:       closing.OrgId,
+		UserId:      closing.UserId,
+		Date:        closing.End.Add(-time.Millisecond),
+		Inserted:    now,
+		Updated:     now,
+		Description: fmt.Sprintf("Closing entry for fiscal year %d", closing.Year),
+		Splits:      make([]*types.Split, 0),
+	}
+
+	var total int64
+
+	for _, account := range earningsAccounts {
+		balance, ok := yearBalances[account.Id]
+
+		if !ok || (balance.balance == 0 && balance.nativeBalance == 0) {
+			continue
+		}
+
+		transaction.Splits = append(transaction.Splits, &types.Split{
+			TransactionId: transaction.Id,
+			AccountId:     account.Id,
+			Amount:        -balance.balance,
+			NativeAmount:  -balance.nativeBalance,
+		})
+
+		total += balance.nativeBalance
+	}
+
+	if len(transaction.Splits) == 0 {
+		return nil, errors.New(fmt.Sprintf("nothing to close for fiscal year %d", closing.Year))
+	}
+
+	sort.Slice(transaction.Splits, func(i, j int) bool {
+		return transaction.Splits[i].AccountId < transaction.Splits[j].AccountId
+	})
+
+	transaction.Splits = append(transaction.Splits, &types.Split{
+		TransactionId: transaction.Id,
+		AccountId:     closing.EquityAccountId,
+		Amount:        total,
+		NativeAmount:  total,
+	})
+
+	return transaction, nil
+}
+
+// getClosingTransactions returns the closing transactions dated within any
+// of periods
+func (model *Model) getClosingTransactions(orgId string, periods []*types.ReportPeriod) ([]*types.Transaction, error) {
+	closings, err := model.db.GetClosings(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*types.Transaction, 0)
+
+	for _, closing := range closings {
+		date := closing.End.Add(-time.Millisecond)
+
+		for _, period := range periods {
+			if !date.Before(period.Start) && date.Before(period.End) {
+				transaction, err := model.getTransactionById(closing.TransactionId)
+
+				if err != nil {
+					return nil, err
+				}
+
+				if transaction.Deleted == false {
+					transactions = append(transactions, transaction)
+				}
+
+				break
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+// checkClosingTransaction stops a closing transaction from being edited or
+// deleted on its own, which would leave its closing pointing at the wrong
+// transaction. Closings are undone with DeleteClosing instead.
+func (model *Model) checkClosingTransaction(transaction *types.Transaction) error {
+	closings, err := model.db.GetClosings(transaction.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, closing := range closings {
+		if closing.TransactionId == transaction.Id {
+			return errors.New("closing transactions can only be removed by deleting the closing")
+		}
+	}
+
+	return nil
+}
+
+// removeClosingEntries takes the splits of closing transactions dated within
+// period back out of balances so that a closed year still reports its income
+// and expenses
+func (model *Model) removeClosingEntries(balances map[string]*reportBalance, transactions []*types.Transaction, period *types.ReportPeriod) {
+	for _, transaction := range transactions {
+		if transaction.Date.Before(period.Start) || !transaction.Date.Before(period.End) {
+			continue
+		}
+
+		for _, split := range transaction.Splits {
+			if balance, ok := balances[split.AccountId]; ok {
+				balance.balance -= split.Amount
+				balance.nativeBalance -= split.NativeAmount
+			}
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdClosing struct {
+	*TdReport
+	fiscalYearStart int
+	closings        []*types.Closing
+	transactions    map[string]*types.Transaction
+}
+
+func newTdClosing() *TdClosing {
+	return &TdClosing{
+		TdReport:     &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()},
+		closings:     make([]*types.Closing, 0),
+		transactions: make(map[string]*types.Transaction),
+	}
+}
+
+func (td *TdClosing) GetOrg(orgId string, userId string) (*types.Org, error) {
+	org, err := td.TdReport.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	org.FiscalYearStart = td.fiscalYearStart
+	return org, nil
+}
+
+func (td *TdClosing) GetOrgAdmins(orgId string) ([]*types.User, error) {
+	return []*types.User{&types.User{Id: "1"}}, nil
+}
+
+func (td *TdClosing) GetOrgUserIds(orgId string) ([]string, error) {
+	return []string{"1"}, nil
+}
+
+func (td *TdClosing) GetAccountingPeriods(orgId string) ([]*types.AccountingPeriod, error) {
+	return []*types.AccountingPeriod{}, nil
+}
+
+func (td *TdClosing) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
+	return append(getReportTestAccounts(), &types.Account{Id: "12", Name: "Closed Earnings", Parent: "4", Currency: "USD", DebitBalance: false}), nil
+}
+
+func (td *TdClosing) GetClosings(orgId string) ([]*types.Closing, error) {
+	return td.closings, nil
+}
+
+func (td *TdClosing) GetClosing(id string) (*types.Closing, error) {
+	for _, closing := range td.closings {
+		if closing.Id == id {
+			return closing, nil
+		}
+	}
+
+	return nil, errors.New("Closing not found")
+}
+
+func (td *TdClosing) GetTransactionById(id string) (*types.Transaction, error) {
+	return td.transactions[id], nil
+}
+
+func (td *TdClosing) InsertClosing(closing *types.Closing, transaction *types.Transaction) error {
+	closing.TransactionId = transaction.Id
+	td.closings = append(td.closings, closing)
+	td.transactions[transaction.Id] = transaction
+
+	for _, split := range transaction.Splits {
+		td.splits = append(td.splits, &reportTestSplit{split.AccountId, transaction.Date, split.NativeAmount})
+	}
+
+	return nil
+}
+
+func (td *TdClosing) DeleteClosing(closing *types.Closing) error {
+	td.closings = td.closings[:0]
+	return nil
+}
+
+func TestCreateClosing(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	td := newTdClosing()
+	model := NewModel(td, nil, types.Config{})
+
+	closing := &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "12"}
+
+	err := model.CreateClosing(closing, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2018, time.January, 1, 0, 0, 0, 0, location), closing.Start)
+	assert.Equal(t, time.Date(2019, time.January, 1, 0, 0, 0, 0, location), closing.End)
+
+	transaction := td.transactions[closing.TransactionId]
+
+	assert.Equal(t, closing.End.Add(-time.Millisecond), transaction.Date)
+
+	amounts := make(map[string]int64)
+
+	for _, split := range transaction.Splits {
+		amounts[split.AccountId] = split.NativeAmount
+	}
+
+	// salary and groceries are zeroed into the equity account
+	assert.Equal(t, map[string]int64{"10": 2000, "11": -500, "12": -1500}, amounts)
+
+	// the income statement for the closed year is unchanged
+	incomeStatement, err := model.GetIncomeStatement("1", "1", &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int64{2000}, incomeStatement.Income.Totals)
+	assert.Equal(t, []int64{500}, incomeStatement.Expenses.Totals)
+
+	// and the balance sheet still balances
+	balanceSheet, err := model.GetBalanceSheet("1", "1", &types.ReportOptions{Date: util.TimeToMs(closing.End)}, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, balanceSheet.Assets.Totals, balanceSheet.TotalLiabilitiesAndEquity)
+
+	err = model.CreateClosing(&types.Closing{Id: "2", OrgId: "1", Year: 2018, EquityAccountId: "12"}, "1")
+
+	assert.Equal(t, errors.New("fiscal year 2018 is already closed"), err)
+}
+
+func TestCreateClosingErrors(t *testing.T) {
+	tests := map[string]struct {
+		closing *types.Closing
+		userId  string
+		err     error
+	}{
+		"not admin": {
+			closing: &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "12"},
+			userId:  "2",
+			err:     errors.New("Must be org admin to close a fiscal year"),
+		},
+		"not an equity account": {
+			closing: &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "7"},
+			userId:  "1",
+			err:     errors.New("equityAccountId must be an Equity account"),
+		},
+		"nothing to close": {
+			closing: &types.Closing{Id: "1", OrgId: "1", Year: 2016, EquityAccountId: "12"},
+			userId:  "1",
+			err:     errors.New("nothing to close for fiscal year 2016"),
+		},
+		"missing year": {
+			closing: &types.Closing{Id: "1", OrgId: "1", EquityAccountId: "12"},
+			userId:  "1",
+			err:     errors.New("year required"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdClosing()
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateClosing(test.closing, test.userId)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, 0, len(td.closings))
+	}
+}
+
+func TestCreateClosingFiscalYear(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	td := newTdClosing()
+	td.fiscalYearStart = 7
+	model := NewModel(td, nil, types.Config{})
+
+	closing := &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "12"}
+
+	err := model.CreateClosing(closing, "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2017, time.July, 1, 0, 0, 0, 0, location), closing.Start)
+	assert.Equal(t, time.Date(2018, time.July, 1, 0, 0, 0, 0, location), closing.End)
+
+	// both years of test splits fall within fiscal 2018 so the equity account
+	// is credited with all earnings
+	splits := td.transactions[closing.TransactionId].Splits
+	assert.Equal(t, "12", splits[len(splits)-1].AccountId)
+	assert.Equal(t, int64(-3500), splits[len(splits)-1].NativeAmount)
+}
+
+func TestGetBalanceSheetFiscalYear(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	date := util.TimeToMs(time.Date(2018, time.June, 1, 0, 0, 0, 0, location))
+
+	td := newTdClosing()
+	td.fiscalYearStart = 7
+	model := NewModel(td, nil, types.Config{})
+
+	balanceSheet, err := model.GetBalanceSheet("1", "1", &types.ReportOptions{Date: date}, "")
+
+	assert.Nil(t, err)
+
+	equity := make(map[string]*types.ReportLine)
+
+	for _, line := range balanceSheet.Equity.Lines {
+		equity[line.Name] = line
+	}
+
+	// the fiscal year started July 1st 2017 so all earnings are current
+	assert.Equal(t, []int64{0}, equity["Retained Earnings"].NativeAmounts)
+	assert.Equal(t, []int64{3500}, equity["Current Year Earnings"].NativeAmounts)
+}
+
+func TestDeleteClosing(t *testing.T) {
+	td := newTdClosing()
+	model := NewModel(td, nil, types.Config{})
+
+	closing := &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "12"}
+
+	err := model.CreateClosing(closing, "1")
+	assert.Nil(t, err)
+
+	err = model.DeleteClosing("2", "1", "1")
+	assert.Equal(t, errors.New("Closing not found"), err)
+
+	err = model.DeleteClosing("1", "1", "2")
+	assert.Equal(t, errors.New("Must be org admin to reverse a closing"), err)
+
+	err = model.DeleteClosing("1", "1", "1")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(td.closings))
+}
+
+func TestClosingTransactionProtected(t *testing.T) {
+	td := newTdClosing()
+	model := NewModel(td, nil, types.Config{})
+
+	closing := &types.Closing{Id: "1", OrgId: "1", Year: 2018, EquityAccountId: "12"}
+
+	err := model.CreateClosing(closing, "1")
+	assert.Nil(t, err)
+
+	original := td.transactions[closing.TransactionId]
+
+	transaction := &types.Transaction{
+		Id:     "2",
+		OrgId:  "1",
+		UserId: "1",
+		Date:   original.Date,
+		Splits: original.Splits,
+	}
+
+	err = model.UpdateTransaction(closing.TransactionId, transaction)
+	assert.Equal(t, errors.New("closing transactions can only be removed by deleting the closing"), err)
+
+	err = model.DeleteTransaction(closing.TransactionId, "1", "1")
+	assert.Equal(t, errors.New("closing transactions can only be removed by deleting the closing"), err)
+
+	periods := []*types.ReportPeriod{&types.ReportPeriod{Start: closing.Start, End: closing.End}}
+
+	transactions, err := model.getClosingTransactions("1", periods)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(transactions))
+
+	// deleted closing transactions are not taken back out of reports
+	original.Deleted = true
+
+	transactions, err = model.getClosingTransactions("1", periods)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(transactions))
+}
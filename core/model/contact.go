@@ -0,0 +1,173 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+)
+
+type ContactInterface interface {
+	GetContacts(string, string) ([]*types.Contact, error)
+	GetContact(string, string, string) (*types.Contact, error)
+	CreateContact(*types.Contact, string) error
+	UpdateContact(*types.Contact, string) error
+	DeleteContact(string, string, string) error
+}
+
+func (model *Model) GetContacts(orgId string, userId string) ([]*types.Contact, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	return model.db.GetContacts(orgId)
+}
+
+func (model *Model) GetContact(orgId string, id string, userId string) (*types.Contact, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	contact, err := model.db.GetContact(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if contact.OrgId != orgId {
+		return nil, errors.New("Contact not found")
+	}
+
+	return contact, nil
+}
+
+func (model *Model) CreateContact(contact *types.Contact, userId string) error {
+	err := model.checkContact(contact, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertContact(contact)
+}
+
+func (model *Model) UpdateContact(contact *types.Contact, userId string) error {
+	// GetContact checks that the contact belongs to the org
+	original, err := model.GetContact(contact.OrgId, contact.Id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkContact(contact, userId)
+
+	if err != nil {
+		return err
+	}
+
+	contact.Inserted = original.Inserted
+
+	return model.db.UpdateContact(contact)
+}
+
+// DeleteContact deletes a contact that no transaction refers to. Contacts that
+// have been used can be made inactive instead.
+func (model *Model) DeleteContact(orgId string, id string, userId string) error {
+	_, err := model.GetContact(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	used, err := model.db.ContactHasTransactions(id)
+
+	if err != nil {
+		return err
+	}
+
+	if used == true {
+		return errors.New("Cannot delete a contact that has transactions")
+	}
+
+	return model.db.DeleteContact(id)
+}
+
+func (model *Model) checkContact(contact *types.Contact, userId string) error {
+	if contact.Id == "" {
+		return errors.New("id required")
+	}
+
+	if contact.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if contact.Name == "" {
+		return errors.New("name required")
+	}
+
+	if contact.Type != "customer" && contact.Type != "vendor" && contact.Type != "employee" {
+		return errors.New("type must be customer, vendor or employee")
+	}
+
+	belongs, err := model.UserBelongsToOrg(userId, contact.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	if belongs == false {
+		return errors.New("User does not belong to org")
+	}
+
+	return nil
+}
+
+// checkTransactionContact makes sure the contact of a transaction, if it has
+// one, belongs to the org of the transaction. contactIds are the ids of the
+// contacts of the org if they are already loaded, otherwise nil and the contact
+// is looked up.
+func (model *Model) checkTransactionContact(transaction *types.Transaction, contactIds map[string]bool) error {
+	if transaction.ContactId == "" {
+		return nil
+	}
+
+	if contactIds != nil {
+		if contactIds[transaction.ContactId] == false {
+			return errors.New("Contact not found")
+		}
+
+		return nil
+	}
+
+	contact, err := model.db.GetContact(transaction.ContactId)
+
+	if err != nil {
+		return err
+	}
+
+	if contact.OrgId != transaction.OrgId {
+		return errors.New("Contact not found")
+	}
+
+	return nil
+}
+
+func getContactIds(contacts []*types.Contact) map[string]bool {
+	contactIds := make(map[string]bool)
+
+	for _, contact := range contacts {
+		contactIds[contact.Id] = true
+	}
+
+	return contactIds
+}
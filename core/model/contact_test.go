@@ -0,0 +1,240 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdContact struct {
+	*TdTransaction
+	contacts []*types.Contact
+	used     map[string]bool
+	lookups  int
+}
+
+func (td *TdContact) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "2"}}, nil
+}
+
+func (td *TdContact) GetContacts(orgId string) ([]*types.Contact, error) {
+	contacts := make([]*types.Contact, 0)
+
+	for _, contact := range td.contacts {
+		if contact.OrgId == orgId {
+			contacts = append(contacts, contact)
+		}
+	}
+
+	return contacts, nil
+}
+
+func (td *TdContact) GetContact(id string) (*types.Contact, error) {
+	td.lookups++
+
+	for _, contact := range td.contacts {
+		if contact.Id == id {
+			return contact, nil
+		}
+	}
+
+	return nil, errors.New("Contact not found")
+}
+
+func (td *TdContact) InsertContact(contact *types.Contact) error {
+	td.contacts = append(td.contacts, contact)
+	return nil
+}
+
+func (td *TdContact) DeleteContact(id string) error {
+	for i, contact := range td.contacts {
+		if contact.Id == id {
+			td.contacts = append(td.contacts[:i], td.contacts[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (td *TdContact) ContactHasTransactions(id string) (bool, error) {
+	return td.used[id], nil
+}
+
+func newTdContact() *TdContact {
+	return &TdContact{
+		TdTransaction: &TdTransaction{},
+		contacts: []*types.Contact{
+			&types.Contact{Id: "4", OrgId: "2", Name: "Corner Store", Type: "vendor"},
+			&types.Contact{Id: "5", OrgId: "2", Name: "Jane Doe", Type: "employee"},
+			&types.Contact{Id: "6", OrgId: "3", Name: "Other Org Customer", Type: "customer"},
+		},
+		used: map[string]bool{"4": true},
+	}
+}
+
+func TestCreateContact(t *testing.T) {
+	tests := map[string]struct {
+		err     error
+		contact *types.Contact
+	}{
+		"successful": {
+			err:     nil,
+			contact: &types.Contact{Id: "7", OrgId: "2", Name: "Acme", Type: "customer"},
+		},
+		"no name": {
+			err:     errors.New("name required"),
+			contact: &types.Contact{Id: "7", OrgId: "2", Type: "customer"},
+		},
+		"bad type": {
+			err:     errors.New("type must be customer, vendor or employee"),
+			contact: &types.Contact{Id: "7", OrgId: "2", Name: "Acme", Type: "supplier"},
+		},
+		"other org": {
+			err:     errors.New("User does not belong to org"),
+			contact: &types.Contact{Id: "7", OrgId: "3", Name: "Acme", Type: "customer"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdContact()
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateContact(test.contact, "3")
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.contact, td.contacts[3])
+		} else {
+			assert.Equal(t, 3, len(td.contacts))
+		}
+	}
+}
+
+func TestDeleteContact(t *testing.T) {
+	tests := map[string]struct {
+		err error
+		id  string
+	}{
+		"successful": {
+			err: nil,
+			id:  "5",
+		},
+		"has transactions": {
+			err: errors.New("Cannot delete a contact that has transactions"),
+			id:  "4",
+		},
+		"other org": {
+			err: errors.New("Contact not found"),
+			id:  "6",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdContact()
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.DeleteContact("2", test.id, "3")
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, 2, len(td.contacts))
+		} else {
+			assert.Equal(t, 3, len(td.contacts))
+		}
+	}
+}
+
+func TestCreateTransactionContact(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		contactId string
+	}{
+		"no contact": {
+			err:       nil,
+			contactId: "",
+		},
+		"contact": {
+			err:       nil,
+			contactId: "4",
+		},
+		"other org": {
+			err:       errors.New("Contact not found"),
+			contactId: "6",
+		},
+		"missing": {
+			err:       errors.New("Contact not found"),
+			contactId: "8",
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdContact()
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:        "1",
+			OrgId:     "2",
+			UserId:    "3",
+			Date:      time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			ContactId: test.contactId,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		}
+
+		err := model.CreateTransaction(transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+		} else {
+			assert.Equal(t, 0, len(td.inserted))
+		}
+	}
+}
+
+func TestCreateTransactionsContact(t *testing.T) {
+	transaction := func(id string, contactId string) *types.Transaction {
+		return &types.Transaction{
+			Id:        id,
+			Date:      time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			ContactId: contactId,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: id, AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: id, AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		}
+	}
+
+	td := newTdContact()
+	model := NewModel(td, nil, types.Config{})
+
+	transactionErrors, err := model.CreateTransactions("2", "3", []*types.Transaction{
+		transaction("1", "4"),
+		transaction("2", "6"),
+		transaction("3", "5"),
+		transaction("4", "8"),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.TransactionError{
+		&types.TransactionError{Index: 1, Id: "2", Error: "Contact not found"},
+		&types.TransactionError{Index: 3, Id: "4", Error: "Contact not found"},
+	}, transactionErrors)
+
+	// contacts are loaded once for the whole batch
+	assert.Equal(t, 0, td.lookups)
+}
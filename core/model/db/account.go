@@ -6,6 +6,7 @@ import (
 	"github.com/openaccounting/oa-server/core/model/types"
 	"github.com/openaccounting/oa-server/core/util"
 	"math"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -23,7 +24,9 @@ type AccountInterface interface {
 	DeleteAccount(id string) error
 	AddBalances([]*types.Account, time.Time) error
 	AddNativeBalancesCost([]*types.Account, time.Time) error
+	AddBalancesAndNativeBalancesCost([]*types.Account, time.Time) error
 	AddNativeBalancesNearestInTime([]*types.Account, time.Time) error
+	GetPeriodBalances([]*types.Account, []time.Time) (map[string][]*types.PeriodBalance, error)
 	AddBalance(*types.Account, time.Time) error
 	AddNativeBalanceCost(*types.Account, time.Time) error
 	AddNativeBalanceNearestInTime(*types.Account, time.Time) error
@@ -34,7 +37,7 @@ func (db *DB) InsertAccount(account *types.Account) error {
 	account.Inserted = time.Now()
 	account.Updated = account.Inserted
 
-	query := "INSERT INTO account(id,orgId,inserted,updated,name,parent,currency,`precision`,debitBalance) VALUES(UNHEX(?),UNHEX(?),?,?,?,UNHEX(?),?,?,?)"
+	query := "INSERT INTO account(id,orgId,inserted,updated,name,parent,currency,`precision`,debitBalance,cash) VALUES(UNHEX(?),UNHEX(?),?,?,?,UNHEX(?),?,?,?,?)"
 	_, err := db.Exec(
 		query,
 		account.Id,
@@ -45,7 +48,8 @@ func (db *DB) InsertAccount(account *types.Account) error {
 		account.Parent,
 		account.Currency,
 		account.Precision,
-		account.DebitBalance)
+		account.DebitBalance,
+		account.Cash)
 
 	return err
 }
@@ -53,7 +57,7 @@ func (db *DB) InsertAccount(account *types.Account) error {
 func (db *DB) UpdateAccount(account *types.Account) error {
 	account.Updated = time.Now()
 
-	query := "UPDATE account SET updated = ?, name = ?, parent = UNHEX(?), currency = ?, `precision` = ?, debitBalance = ? WHERE id = UNHEX(?)"
+	query := "UPDATE account SET updated = ?, name = ?, parent = UNHEX(?), currency = ?, `precision` = ?, debitBalance = ?, cash = ? WHERE id = UNHEX(?)"
 	_, err := db.Exec(
 		query,
 		util.TimeToMs(account.Updated),
@@ -62,6 +66,7 @@ func (db *DB) UpdateAccount(account *types.Account) error {
 		account.Currency,
 		account.Precision,
 		account.DebitBalance,
+		account.Cash,
 		account.Id)
 
 	return err
@@ -72,8 +77,8 @@ func (db *DB) GetAccount(id string) (*types.Account, error) {
 	var inserted int64
 	var updated int64
 
-	err := db.QueryRow("SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance FROM account WHERE id = UNHEX(?)", id).
-		Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance)
+	err := db.QueryRow("SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance,cash FROM account WHERE id = UNHEX(?)", id).
+		Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance, &a.Cash)
 
 	if a.Parent == emptyAccountId {
 		a.Parent = ""
@@ -92,7 +97,7 @@ func (db *DB) GetAccount(id string) (*types.Account, error) {
 }
 
 func (db *DB) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
-	rows, err := db.Query("SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance FROM account WHERE orgId = UNHEX(?)", orgId)
+	rows, err := db.Query("SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance,cash FROM account WHERE orgId = UNHEX(?)", orgId)
 
 	if err != nil {
 		return nil, err
@@ -107,7 +112,7 @@ func (db *DB) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
 		var inserted int64
 		var updated int64
 
-		err = rows.Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance)
+		err = rows.Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance, &a.Cash)
 		if err != nil {
 			return nil, err
 		}
@@ -183,96 +188,58 @@ func (db *DB) DeleteAccount(id string) error {
 
 	_, err := db.Exec(query, id)
 
-	return err
-}
-
-func (db *DB) AddBalances(accounts []*types.Account, date time.Time) error {
-	// TODO optimize
-	ids := make([]string, len(accounts))
-
-	for i, account := range accounts {
-		ids[i] = "UNHEX(\"" + account.Id + "\")"
-	}
-
-	balanceMap := make(map[string]*int64)
-
-	query := "SELECT LOWER(HEX(accountId)), SUM(amount) FROM split WHERE deleted = false AND accountId IN (" +
-		strings.Join(ids, ",") + ")" +
-		" AND date < ? GROUP BY accountId"
-
-	rows, err := db.Query(query, util.TimeToMs(date))
-
 	if err != nil {
 		return err
 	}
 
-	defer rows.Close()
+	// accounts with splits cannot be deleted so these are all zeros
+	query = "DELETE FROM balance WHERE accountId = UNHEX(?)"
 
-	for rows.Next() {
-		var id string
-		var balance int64
-		err := rows.Scan(&id, &balance)
-		if err != nil {
-			return err
-		}
+	_, err = db.Exec(query, id)
 
-		balanceMap[id] = &balance
-	}
+	return err
+}
 
-	err = rows.Err()
+func (db *DB) AddBalances(accounts []*types.Account, date time.Time) error {
+	balances, err := db.getBalances(accounts, date)
 
 	if err != nil {
 		return err
 	}
 
 	for _, account := range accounts {
-		account.Balance = balanceMap[account.Id]
+		account.Balance = &balances[account.Id].Balance
 	}
 
 	return nil
 }
 
 func (db *DB) AddNativeBalancesCost(accounts []*types.Account, date time.Time) error {
-	// TODO optimize
-	ids := make([]string, len(accounts))
-
-	for i, account := range accounts {
-		ids[i] = "UNHEX(\"" + account.Id + "\")"
-	}
-
-	balanceMap := make(map[string]*int64)
-
-	query := "SELECT LOWER(HEX(accountId)), SUM(nativeAmount) FROM split WHERE deleted = false AND accountId IN (" +
-		strings.Join(ids, ",") + ")" +
-		" AND date < ? GROUP BY accountId"
-
-	rows, err := db.Query(query, util.TimeToMs(date))
+	balances, err := db.getBalances(accounts, date)
 
 	if err != nil {
 		return err
 	}
 
-	defer rows.Close()
-
-	for rows.Next() {
-		var id string
-		var balance int64
-		err := rows.Scan(&id, &balance)
-		if err != nil {
-			return err
-		}
-
-		balanceMap[id] = &balance
+	for _, account := range accounts {
+		account.NativeBalance = &balances[account.Id].NativeBalance
 	}
 
-	err = rows.Err()
+	return nil
+}
+
+// AddBalancesAndNativeBalancesCost does the work of AddBalances and
+// AddNativeBalancesCost with a single lookup
+func (db *DB) AddBalancesAndNativeBalancesCost(accounts []*types.Account, date time.Time) error {
+	balances, err := db.getBalances(accounts, date)
 
 	if err != nil {
 		return err
 	}
 
 	for _, account := range accounts {
-		account.NativeBalance = balanceMap[account.Id]
+		account.Balance = &balances[account.Id].Balance
+		account.NativeBalance = &balances[account.Id].NativeBalance
 	}
 
 	return nil
@@ -291,35 +258,11 @@ func (db *DB) AddNativeBalancesNearestInTime(accounts []*types.Account, date tim
 }
 
 func (db *DB) AddBalance(account *types.Account, date time.Time) error {
-	var balance sql.NullInt64
-
-	query := "SELECT SUM(amount) FROM split WHERE deleted = false AND accountId = UNHEX(?) AND date < ?"
-
-	err := db.QueryRow(query, account.Id, util.TimeToMs(date)).Scan(&balance)
-
-	if err != nil {
-		return err
-	}
-
-	account.Balance = &balance.Int64
-
-	return nil
+	return db.AddBalances([]*types.Account{account}, date)
 }
 
 func (db *DB) AddNativeBalanceCost(account *types.Account, date time.Time) error {
-	var nativeBalance sql.NullInt64
-
-	query := "SELECT SUM(nativeAmount) FROM split WHERE deleted = false AND accountId = UNHEX(?) AND date < ?"
-
-	err := db.QueryRow(query, account.Id, util.TimeToMs(date)).Scan(&nativeBalance)
-
-	if err != nil {
-		return err
-	}
-
-	account.NativeBalance = &nativeBalance.Int64
-
-	return nil
+	return db.AddNativeBalancesCost([]*types.Account{account}, date)
 }
 
 func (db *DB) AddNativeBalanceNearestInTime(account *types.Account, date time.Time) error {
@@ -371,10 +314,10 @@ func (db *DB) GetRootAccount(orgId string) (*types.Account, error) {
 	var updated int64
 
 	err := db.QueryRow(
-		"SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance FROM account WHERE orgId = UNHEX(?) AND parent = UNHEX(?)",
+		"SELECT LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,LOWER(HEX(parent)),currency,`precision`,debitBalance,cash FROM account WHERE orgId = UNHEX(?) AND parent = UNHEX(?)",
 		orgId,
 		emptyAccountId).
-		Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance)
+		Scan(&a.Id, &a.OrgId, &inserted, &updated, &a.Name, &a.Parent, &a.Currency, &a.Precision, &a.DebitBalance, &a.Cash)
 
 	a.Parent = ""
 
@@ -389,3 +332,149 @@ func (db *DB) GetRootAccount(orgId string) (*types.Account, error) {
 		return &a, nil
 	}
 }
+
+// GetPeriodBalances sums splits for each account into buckets separated by
+// boundaries, which must be sorted. Bucket 0 holds splits before the first
+// boundary and bucket i holds splits from boundary i-1 up to boundary i.
+// Splits on or after the last boundary are left out.
+func (db *DB) GetPeriodBalances(accounts []*types.Account, boundaries []time.Time) (map[string][]*types.PeriodBalance, error) {
+	balances := make(map[string][]*types.PeriodBalance)
+
+	if len(accounts) == 0 || len(boundaries) == 0 {
+		return balances, nil
+	}
+
+	ids := make([]string, len(accounts))
+
+	for i, account := range accounts {
+		ids[i] = account.Id
+	}
+
+	// the first bucket starts from the snapshot before the first boundary
+	snapshots, since, err := db.getSnapshots(ids, boundaries[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	for id, snapshot := range snapshots {
+		if snapshot.Balance != 0 || snapshot.NativeBalance != 0 {
+			addPeriodBalance(balances, len(boundaries), id, 0, snapshot.Balance, snapshot.NativeBalance)
+		}
+	}
+
+	cases := make([]string, len(boundaries))
+	args := make([]interface{}, 0, len(boundaries)+2)
+
+	for i, boundary := range boundaries {
+		cases[i] = "WHEN date < ? THEN " + strconv.Itoa(i)
+		args = append(args, util.TimeToMs(boundary))
+	}
+
+	args = append(args, since, util.TimeToMs(boundaries[len(boundaries)-1]))
+
+	query := "SELECT LOWER(HEX(accountId)), CASE " + strings.Join(cases, " ") + " END AS bucket, SUM(amount), SUM(nativeAmount) FROM split WHERE deleted = false AND accountId IN (" +
+		unhexIds(ids) + ")" +
+		" AND date >= ? AND date < ? GROUP BY accountId, bucket"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var bucket int
+		var balance int64
+		var nativeBalance int64
+		err := rows.Scan(&id, &bucket, &balance, &nativeBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		addPeriodBalance(balances, len(boundaries), id, bucket, balance, nativeBalance)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func addPeriodBalance(balances map[string][]*types.PeriodBalance, buckets int, id string, bucket int, balance int64, nativeBalance int64) {
+	if balances[id] == nil {
+		balances[id] = make([]*types.PeriodBalance, buckets)
+
+		for i := range balances[id] {
+			balances[id][i] = &types.PeriodBalance{}
+		}
+	}
+
+	balances[id][bucket].Balance += balance
+	balances[id][bucket].NativeBalance += nativeBalance
+}
+
+// getBalances sums the splits of each account before date starting from the
+// nearest snapshot
+func (db *DB) getBalances(accounts []*types.Account, date time.Time) (map[string]*types.PeriodBalance, error) {
+	ids := make([]string, len(accounts))
+
+	for i, account := range accounts {
+		ids[i] = account.Id
+	}
+
+	balances, since, err := db.getSnapshots(ids, date)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		if balances[id] == nil {
+			balances[id] = &types.PeriodBalance{}
+		}
+	}
+
+	if len(ids) == 0 {
+		return balances, nil
+	}
+
+	query := "SELECT LOWER(HEX(accountId)), SUM(amount), SUM(nativeAmount) FROM split WHERE deleted = false AND accountId IN (" +
+		unhexIds(ids) + ")" +
+		" AND date >= ? AND date < ? GROUP BY accountId"
+
+	rows, err := db.Query(query, since, util.TimeToMs(date))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var balance int64
+		var nativeBalance int64
+		err := rows.Scan(&id, &balance, &nativeBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		balances[id].Balance += balance
+		balances[id].NativeBalance += nativeBalance
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
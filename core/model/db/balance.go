@@ -0,0 +1,133 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+)
+
+// The balance table caches the balance of each account at checkpoints so that
+// balance lookups only need to sum splits since the nearest checkpoint.
+// A row holds the sum of every split on the account dated before its date.
+//
+// Checkpoints are at the start of each month (UTC). Rows are created the first
+// time they are needed and deleted whenever a split dated before them is
+// inserted or deleted.
+
+// getCheckpoint returns the latest checkpoint at or before date. Checkpoints
+// are never in the future since that is where most new transactions go.
+func getCheckpoint(date time.Time) time.Time {
+	now := time.Now()
+
+	if date.After(now) {
+		date = now
+	}
+
+	date = date.UTC()
+
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// getSnapshots returns the balances of accounts at the latest checkpoint at or
+// before date, creating any that are missing. Splits from the returned
+// checkpoint (in ms) up to date still need to be added.
+func (db *DB) getSnapshots(accountIds []string, date time.Time) (map[string]*types.PeriodBalance, int64, error) {
+	snapshots := make(map[string]*types.PeriodBalance)
+	checkpoint := getCheckpoint(date)
+
+	// dates are unsigned so there is nothing to cache before the epoch
+	if len(accountIds) == 0 || checkpoint.Unix() <= 0 {
+		return snapshots, 0, nil
+	}
+
+	checkpointMs := util.TimeToMs(checkpoint)
+
+	err := db.readSnapshots(snapshots, accountIds, checkpointMs)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	missing := make([]string, 0)
+
+	for _, accountId := range accountIds {
+		if snapshots[accountId] == nil {
+			missing = append(missing, accountId)
+		}
+	}
+
+	if len(missing) == 0 {
+		return snapshots, checkpointMs, nil
+	}
+
+	// Accounts without splits get a row of zeros so they are not recomputed.
+	// INSERT ... SELECT locks the splits it reads so a transaction cannot be
+	// inserted (and invalidate this checkpoint) while it is being computed.
+	query := "INSERT IGNORE INTO balance(date,accountId,amount,nativeAmount)" +
+		" SELECT ?, a.id, COALESCE(SUM(s.amount), 0), COALESCE(SUM(s.nativeAmount), 0) FROM account a" +
+		" LEFT JOIN split s ON s.accountId = a.id AND s.deleted = false AND s.date < ?" +
+		" WHERE a.id IN (" + unhexIds(missing) + ") GROUP BY a.id"
+
+	_, err = db.Exec(query, checkpointMs, checkpointMs)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = db.readSnapshots(snapshots, missing, checkpointMs)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return snapshots, checkpointMs, nil
+}
+
+func (db *DB) readSnapshots(snapshots map[string]*types.PeriodBalance, accountIds []string, checkpointMs int64) error {
+	query := "SELECT LOWER(HEX(accountId)), amount, nativeAmount FROM balance WHERE date = ? AND accountId IN (" + unhexIds(accountIds) + ")"
+
+	rows, err := db.Query(query, checkpointMs)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		b := new(types.PeriodBalance)
+		err = rows.Scan(&id, &b.Balance, &b.NativeBalance)
+		if err != nil {
+			return err
+		}
+
+		snapshots[id] = b
+	}
+
+	return rows.Err()
+}
+
+// invalidateBalances deletes checkpoints affected by the splits of a
+// transaction. It must run in the same db transaction that inserts or deletes
+// the splits.
+func invalidateBalances(dbTx *sql.Tx, transactionId string) error {
+	query := "DELETE b FROM balance b JOIN split s ON s.accountId = b.accountId WHERE s.transactionId = UNHEX(?) AND b.date > s.date"
+
+	_, err := dbTx.Exec(query, transactionId)
+
+	return err
+}
+
+func unhexIds(ids []string) string {
+	unhexed := make([]string, len(ids))
+
+	for i, id := range ids {
+		unhexed[i] = "UNHEX(\"" + id + "\")"
+	}
+
+	return strings.Join(unhexed, ",")
+}
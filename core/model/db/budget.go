@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"errors"
 	"github.com/openaccounting/oa-server/core/model/types"
 	"github.com/openaccounting/oa-server/core/util"
@@ -11,15 +12,22 @@ type BudgetInterface interface {
 	GetBudget(string) (*types.Budget, error)
 	InsertAndReplaceBudget(*types.Budget) error
 	DeleteBudget(string) error
+	GetNamedBudgets(string) ([]*types.Budget, error)
+	GetNamedBudget(string) (*types.Budget, error)
+	InsertNamedBudget(*types.Budget) error
+	UpdateNamedBudget(*types.Budget) error
+	DeleteNamedBudget(string) error
 }
 
 const budgetFields = "LOWER(HEX(accountId)),inserted,amount"
+const namedBudgetFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,`interval`,start,periods"
+const budgetItemFields = "LOWER(HEX(budgetId)),LOWER(HEX(accountId)),period,amount"
 
 func (db *DB) GetBudget(orgId string) (*types.Budget, error) {
 	var budget types.Budget
 	var inserted int64
 
-	rows, err := db.Query("SELECT "+budgetFields+" FROM budgetitem WHERE orgId = UNHEX(?) ORDER BY HEX(accountId)", orgId)
+	rows, err := db.Query("SELECT "+budgetFields+" FROM budgetitem WHERE orgId = UNHEX(?) AND budgetId IS NULL ORDER BY HEX(accountId)", orgId)
 
 	if err != nil {
 		return nil, err
@@ -78,7 +86,7 @@ func (db *DB) InsertAndReplaceBudget(budget *types.Budget) (err error) {
 	}()
 
 	// delete previous budget
-	query1 := "DELETE FROM budgetitem WHERE orgId = UNHEX(?)"
+	query1 := "DELETE FROM budgetitem WHERE orgId = UNHEX(?) AND budgetId IS NULL"
 
 	_, err = dbTx.Exec(
 		query1,
@@ -109,9 +117,273 @@ func (db *DB) InsertAndReplaceBudget(budget *types.Budget) (err error) {
 }
 
 func (db *DB) DeleteBudget(orgId string) error {
-	query := "DELETE FROM budgetitem WHERE orgId = UNHEX(?)"
+	query := "DELETE FROM budgetitem WHERE orgId = UNHEX(?) AND budgetId IS NULL"
 
 	_, err := db.Exec(query, orgId)
 
 	return err
 }
+
+func (db *DB) GetNamedBudgets(orgId string) ([]*types.Budget, error) {
+	rows, err := db.Query("SELECT "+namedBudgetFields+" FROM budget WHERE orgId = UNHEX(?) ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	budgets := make([]*types.Budget, 0)
+	budgetMap := make(map[string]*types.Budget)
+
+	for rows.Next() {
+		var inserted int64
+		var updated int64
+		var start int64
+		b := new(types.Budget)
+		err = rows.Scan(&b.Id, &b.OrgId, &inserted, &updated, &b.Name, &b.Interval, &start, &b.Periods)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Inserted = util.MsToTime(inserted)
+		b.Updated = util.MsToTime(updated)
+		b.Start = util.MsToTime(start)
+		b.Items = make([]*types.BudgetItem, 0)
+
+		budgets = append(budgets, b)
+		budgetMap[b.Id] = b
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := db.getNamedBudgetItems("orgId = UNHEX(?) AND budgetId IS NOT NULL", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for budgetId, budgetItems := range items {
+		if b, ok := budgetMap[budgetId]; ok {
+			b.Items = budgetItems
+		}
+	}
+
+	return budgets, nil
+}
+
+func (db *DB) GetNamedBudget(id string) (*types.Budget, error) {
+	var inserted int64
+	var updated int64
+	var start int64
+	b := new(types.Budget)
+
+	err := db.QueryRow("SELECT "+namedBudgetFields+" FROM budget WHERE id = UNHEX(?)", id).
+		Scan(&b.Id, &b.OrgId, &inserted, &updated, &b.Name, &b.Interval, &start, &b.Periods)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.New("Budget not found")
+	case err != nil:
+		return nil, err
+	}
+
+	b.Inserted = util.MsToTime(inserted)
+	b.Updated = util.MsToTime(updated)
+	b.Start = util.MsToTime(start)
+	b.Items = make([]*types.BudgetItem, 0)
+
+	items, err := db.getNamedBudgetItems("budgetId = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if budgetItems, ok := items[b.Id]; ok {
+		b.Items = budgetItems
+	}
+
+	return b, nil
+}
+
+func (db *DB) InsertNamedBudget(budget *types.Budget) (err error) {
+	budget.Inserted = time.Now()
+	budget.Updated = budget.Inserted
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query := "INSERT INTO budget(id,orgId,inserted,updated,name,`interval`,start,periods) VALUES(UNHEX(?),UNHEX(?),?,?,?,?,?,?)"
+
+	_, err = dbTx.Exec(
+		query,
+		budget.Id,
+		budget.OrgId,
+		util.TimeToMs(budget.Inserted),
+		util.TimeToMs(budget.Updated),
+		budget.Name,
+		budget.Interval,
+		util.TimeToMs(budget.Start),
+		budget.Periods,
+	)
+
+	if err != nil {
+		return
+	}
+
+	err = insertNamedBudgetItems(dbTx, budget)
+
+	return
+}
+
+func (db *DB) UpdateNamedBudget(budget *types.Budget) (err error) {
+	budget.Updated = time.Now()
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "UPDATE budget SET updated = ?, name = ?, `interval` = ?, start = ?, periods = ? WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(
+		query1,
+		util.TimeToMs(budget.Updated),
+		budget.Name,
+		budget.Interval,
+		util.TimeToMs(budget.Start),
+		budget.Periods,
+		budget.Id,
+	)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM budgetitem WHERE budgetId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, budget.Id)
+
+	if err != nil {
+		return
+	}
+
+	err = insertNamedBudgetItems(dbTx, budget)
+
+	return
+}
+
+func (db *DB) DeleteNamedBudget(id string) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM budgetitem WHERE budgetId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query1, id)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM budget WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, id)
+
+	return
+}
+
+func (db *DB) getNamedBudgetItems(where string, args ...interface{}) (map[string][]*types.BudgetItem, error) {
+	rows, err := db.Query("SELECT "+budgetItemFields+" FROM budgetitem WHERE "+where+" ORDER BY HEX(accountId), period", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	items := make(map[string][]*types.BudgetItem)
+
+	for rows.Next() {
+		var budgetId string
+		i := new(types.BudgetItem)
+		err := rows.Scan(&budgetId, &i.AccountId, &i.Period, &i.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		items[budgetId] = append(items[budgetId], i)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func insertNamedBudgetItems(dbTx *sql.Tx, budget *types.Budget) error {
+	for _, item := range budget.Items {
+		query := "INSERT INTO budgetitem(orgId,budgetId,accountId,period,inserted,amount) VALUES (UNHEX(?),UNHEX(?),UNHEX(?),?,?,?)"
+
+		_, err := dbTx.Exec(
+			query,
+			budget.OrgId,
+			budget.Id,
+			item.AccountId,
+			item.Period,
+			util.TimeToMs(budget.Updated),
+			item.Amount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
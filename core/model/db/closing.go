@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type ClosingInterface interface {
+	GetClosings(string) ([]*types.Closing, error)
+	GetClosing(string) (*types.Closing, error)
+	InsertClosing(*types.Closing, *types.Transaction) error
+	DeleteClosing(*types.Closing) error
+}
+
+const closingFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),inserted,year,start,end,LOWER(HEX(equityAccountId)),LOWER(HEX(transactionId))"
+
+func (db *DB) GetClosings(orgId string) ([]*types.Closing, error) {
+	rows, err := db.Query("SELECT "+closingFields+" FROM closing WHERE orgId = UNHEX(?) ORDER BY year", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	closings := make([]*types.Closing, 0)
+
+	for rows.Next() {
+		var inserted int64
+		var start int64
+		var end int64
+		c := new(types.Closing)
+		err = rows.Scan(&c.Id, &c.OrgId, &c.UserId, &inserted, &c.Year, &start, &end, &c.EquityAccountId, &c.TransactionId)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Inserted = util.MsToTime(inserted)
+		c.Start = util.MsToTime(start)
+		c.End = util.MsToTime(end)
+
+		closings = append(closings, c)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return closings, nil
+}
+
+func (db *DB) GetClosing(id string) (*types.Closing, error) {
+	var c types.Closing
+	var inserted int64
+	var start int64
+	var end int64
+
+	err := db.QueryRow("SELECT "+closingFields+" FROM closing WHERE id = UNHEX(?)", id).
+		Scan(&c.Id, &c.OrgId, &c.UserId, &inserted, &c.Year, &start, &end, &c.EquityAccountId, &c.TransactionId)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.New("Closing not found")
+	case err != nil:
+		return nil, err
+	default:
+		c.Inserted = util.MsToTime(inserted)
+		c.Start = util.MsToTime(start)
+		c.End = util.MsToTime(end)
+		return &c, nil
+	}
+}
+
+// InsertClosing saves closing along with its closing transaction in a single
+// db transaction
+func (db *DB) InsertClosing(closing *types.Closing, transaction *types.Transaction) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	err = insertTransaction(dbTx, transaction)
+
+	if err != nil {
+		return
+	}
+
+	closing.Inserted = transaction.Inserted
+	closing.TransactionId = transaction.Id
+
+	query := "INSERT INTO closing(id,orgId,userId,inserted,year,start,end,equityAccountId,transactionId) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,UNHEX(?),UNHEX(?))"
+
+	_, err = dbTx.Exec(
+		query,
+		closing.Id,
+		closing.OrgId,
+		closing.UserId,
+		util.TimeToMs(closing.Inserted),
+		closing.Year,
+		util.TimeToMs(closing.Start),
+		util.TimeToMs(closing.End),
+		closing.EquityAccountId,
+		closing.TransactionId,
+	)
+
+	return
+}
+
+// DeleteClosing reverses a closing by deleting its closing transaction
+func (db *DB) DeleteClosing(closing *types.Closing) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	err = deleteTransaction(dbTx, closing.TransactionId, util.TimeToMs(time.Now()))
+
+	if err != nil {
+		return
+	}
+
+	query := "DELETE FROM closing WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query, closing.Id)
+
+	return
+}
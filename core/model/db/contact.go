@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type ContactInterface interface {
+	GetContacts(string) ([]*types.Contact, error)
+	GetContact(string) (*types.Contact, error)
+	InsertContact(*types.Contact) error
+	UpdateContact(*types.Contact) error
+	DeleteContact(string) error
+	ContactHasTransactions(string) (bool, error)
+}
+
+const contactFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,type,email,phone,address,inactive"
+
+func (db *DB) GetContacts(orgId string) ([]*types.Contact, error) {
+	rows, err := db.Query("SELECT "+contactFields+" FROM contact WHERE orgId = UNHEX(?) ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return db.unmarshalContacts(rows)
+}
+
+func (db *DB) GetContact(id string) (*types.Contact, error) {
+	rows, err := db.Query("SELECT "+contactFields+" FROM contact WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := db.unmarshalContacts(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contacts) == 0 {
+		return nil, errors.New("Contact not found")
+	}
+
+	return contacts[0], nil
+}
+
+func (db *DB) InsertContact(contact *types.Contact) error {
+	contact.Inserted = time.Now()
+	contact.Updated = contact.Inserted
+
+	query := "INSERT INTO contact(id,orgId,inserted,updated,name,type,email,phone,address,inactive) VALUES(UNHEX(?),UNHEX(?),?,?,?,?,?,?,?,?)"
+
+	_, err := db.Exec(
+		query,
+		contact.Id,
+		contact.OrgId,
+		util.TimeToMs(contact.Inserted),
+		util.TimeToMs(contact.Updated),
+		contact.Name,
+		contact.Type,
+		contact.Email,
+		contact.Phone,
+		contact.Address,
+		contact.Inactive,
+	)
+
+	return err
+}
+
+func (db *DB) UpdateContact(contact *types.Contact) error {
+	contact.Updated = time.Now()
+
+	query := "UPDATE contact SET updated = ?, name = ?, type = ?, email = ?, phone = ?, address = ?, inactive = ? WHERE id = UNHEX(?)"
+
+	_, err := db.Exec(
+		query,
+		util.TimeToMs(contact.Updated),
+		contact.Name,
+		contact.Type,
+		contact.Email,
+		contact.Phone,
+		contact.Address,
+		contact.Inactive,
+		contact.Id,
+	)
+
+	return err
+}
+
+func (db *DB) DeleteContact(id string) error {
+	_, err := db.Exec("DELETE FROM contact WHERE id = UNHEX(?)", id)
+
+	return err
+}
+
+// ContactHasTransactions returns true if any undeleted transaction refers to
+// contact id
+func (db *DB) ContactHasTransactions(id string) (bool, error) {
+	var count int
+
+	err := db.QueryRow("SELECT COUNT(*) FROM transaction WHERE contactId = UNHEX(?) AND deleted = false", id).Scan(&count)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (db *DB) unmarshalContacts(rows *sql.Rows) ([]*types.Contact, error) {
+	defer rows.Close()
+
+	contacts := make([]*types.Contact, 0)
+
+	for rows.Next() {
+		c := new(types.Contact)
+		var inserted int64
+		var updated int64
+		err := rows.Scan(&c.Id, &c.OrgId, &inserted, &updated, &c.Name, &c.Type, &c.Email, &c.Phone, &c.Address, &c.Inactive)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Inserted = util.MsToTime(inserted)
+		c.Updated = util.MsToTime(updated)
+
+		contacts = append(contacts, c)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return contacts, nil
+}
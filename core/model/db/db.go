@@ -3,6 +3,8 @@ package db
 import (
 	"database/sql"
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
 )
 
 type DB struct {
@@ -20,6 +22,17 @@ type Datastore interface {
 	ApiKeyInterface
 	SystemHealthInteface
 	BudgetInterface
+	LedgerInterface
+	PeriodInterface
+	ClosingInterface
+	RecurringTransactionInterface
+	TemplateInterface
+	ImportInterface
+	RuleInterface
+	ReconciliationInterface
+	ContactInterface
+	DimensionInterface
+	LockInterface
 }
 
 func NewDB(dataSourceName string) (*DB, error) {
@@ -79,3 +92,36 @@ func (db *DB) Escape(sql string) string {
 
 	return string(dest)
 }
+
+func nullMsToTime(ms sql.NullInt64) *time.Time {
+	if !ms.Valid {
+		return nil
+	}
+
+	t := util.MsToTime(ms.Int64)
+	return &t
+}
+
+func timeToNullMs(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: util.TimeToMs(*t), Valid: true}
+}
+
+func int64ToNull(i *int64) sql.NullInt64 {
+	if i == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: *i, Valid: true}
+}
+
+func float64ToNull(f *float64) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: *f, Valid: true}
+}
@@ -0,0 +1,459 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DimensionInterface interface {
+	GetDimensions(string) ([]*types.Dimension, error)
+	GetDimension(string) (*types.Dimension, error)
+	InsertDimension(*types.Dimension) error
+	UpdateDimension(*types.Dimension) error
+	DeleteDimension(string) error
+	GetUsedDimensionValueIds(string) ([]string, error)
+	GetDimensionPeriodBalances([]*types.Account, []time.Time, map[string][]string) (map[string][]*types.PeriodBalance, error)
+}
+
+const dimensionFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name"
+const dimensionValueFields = "LOWER(HEX(v.id)),LOWER(HEX(v.dimensionId)),v.name,v.inactive"
+
+func (db *DB) GetDimensions(orgId string) ([]*types.Dimension, error) {
+	rows, err := db.Query("SELECT "+dimensionFields+" FROM dimension WHERE orgId = UNHEX(?) ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dimensions, err := db.unmarshalDimensions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addDimensionValues(dimensions, "d.orgId = UNHEX(?)", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dimensions, nil
+}
+
+func (db *DB) GetDimension(id string) (*types.Dimension, error) {
+	rows, err := db.Query("SELECT "+dimensionFields+" FROM dimension WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dimensions, err := db.unmarshalDimensions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dimensions) == 0 {
+		return nil, errors.New("Dimension not found")
+	}
+
+	err = db.addDimensionValues(dimensions, "d.id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dimensions[0], nil
+}
+
+func (db *DB) InsertDimension(dimension *types.Dimension) (err error) {
+	dimension.Inserted = time.Now()
+	dimension.Updated = dimension.Inserted
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query := "INSERT INTO dimension(id,orgId,inserted,updated,name) VALUES(UNHEX(?),UNHEX(?),?,?,?)"
+
+	_, err = dbTx.Exec(
+		query,
+		dimension.Id,
+		dimension.OrgId,
+		util.TimeToMs(dimension.Inserted),
+		util.TimeToMs(dimension.Updated),
+		dimension.Name,
+	)
+
+	if err != nil {
+		return
+	}
+
+	err = saveDimensionValues(dbTx, dimension)
+
+	return
+}
+
+// UpdateDimension saves the name of a dimension along with its values. Values
+// missing from dimension.Values are deleted.
+func (db *DB) UpdateDimension(dimension *types.Dimension) (err error) {
+	dimension.Updated = time.Now()
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "UPDATE dimension SET updated = ?, name = ? WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(
+		query1,
+		util.TimeToMs(dimension.Updated),
+		dimension.Name,
+		dimension.Id,
+	)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM dimensionvalue WHERE dimensionId = UNHEX(?)"
+	args := []interface{}{dimension.Id}
+
+	if len(dimension.Values) > 0 {
+		query2 += " AND id NOT IN (UNHEX(?)" + strings.Repeat(",UNHEX(?)", len(dimension.Values)-1) + ")"
+
+		for _, value := range dimension.Values {
+			args = append(args, value.Id)
+		}
+	}
+
+	_, err = dbTx.Exec(query2, args...)
+
+	if err != nil {
+		return
+	}
+
+	err = saveDimensionValues(dbTx, dimension)
+
+	return
+}
+
+func (db *DB) DeleteDimension(id string) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM dimensionvalue WHERE dimensionId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query1, id)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM dimension WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, id)
+
+	return
+}
+
+// GetUsedDimensionValueIds returns the values of dimension id that are set on
+// undeleted splits
+func (db *DB) GetUsedDimensionValueIds(id string) ([]string, error) {
+	query := "SELECT DISTINCT LOWER(HEX(sd.valueId)) FROM splitdimension sd" +
+		" JOIN split s ON s.transactionId = sd.transactionId AND s.position = sd.position" +
+		" WHERE sd.dimensionId = UNHEX(?) AND s.deleted = false"
+
+	rows, err := db.Query(query, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	valueIds := make([]string, 0)
+
+	for rows.Next() {
+		var valueId string
+		err = rows.Scan(&valueId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		valueIds = append(valueIds, valueId)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return valueIds, nil
+}
+
+// GetDimensionPeriodBalances is GetPeriodBalances for only the splits matching
+// filter. filter maps a dimension id to the values a split may have for it, an
+// empty value matching splits without one. The ids in filter must have been
+// checked against the org's dimensions.
+//
+// Checkpoints in the balance table include every split so they are not used
+// and all splits up to the last boundary are summed.
+func (db *DB) GetDimensionPeriodBalances(accounts []*types.Account, boundaries []time.Time, filter map[string][]string) (map[string][]*types.PeriodBalance, error) {
+	balances := make(map[string][]*types.PeriodBalance)
+
+	if len(accounts) == 0 || len(boundaries) == 0 {
+		return balances, nil
+	}
+
+	ids := make([]string, len(accounts))
+
+	for i, account := range accounts {
+		ids[i] = account.Id
+	}
+
+	cases := make([]string, len(boundaries))
+	args := make([]interface{}, 0, len(boundaries)+1)
+
+	for i, boundary := range boundaries {
+		cases[i] = "WHEN s.date < ? THEN " + strconv.Itoa(i)
+		args = append(args, util.TimeToMs(boundary))
+	}
+
+	args = append(args, util.TimeToMs(boundaries[len(boundaries)-1]))
+
+	query := "SELECT LOWER(HEX(s.accountId)), CASE " + strings.Join(cases, " ") + " END AS bucket, SUM(s.amount), SUM(s.nativeAmount) FROM split s WHERE s.deleted = false AND s.accountId IN (" +
+		unhexIds(ids) + ")" +
+		" AND s.date < ?"
+
+	for dimensionId, valueIds := range filter {
+		query += " AND (" + dimensionFilterCondition(dimensionId, valueIds) + ")"
+	}
+
+	query += " GROUP BY s.accountId, bucket"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var bucket int
+		var balance int64
+		var nativeBalance int64
+		err := rows.Scan(&id, &bucket, &balance, &nativeBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		addPeriodBalance(balances, len(boundaries), id, bucket, balance, nativeBalance)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+// dimensionFilterCondition matches splits s having one of valueIds for
+// dimension dimensionId
+func dimensionFilterCondition(dimensionId string, valueIds []string) string {
+	exists := "SELECT 1 FROM splitdimension sd WHERE sd.transactionId = s.transactionId AND sd.position = s.position AND sd.dimensionId = UNHEX(\"" + dimensionId + "\")"
+
+	conditions := make([]string, 0, 2)
+	ids := make([]string, 0, len(valueIds))
+
+	for _, valueId := range valueIds {
+		if valueId == "" {
+			conditions = append(conditions, "NOT EXISTS ("+exists+")")
+		} else {
+			ids = append(ids, valueId)
+		}
+	}
+
+	if len(ids) > 0 {
+		conditions = append(conditions, "EXISTS ("+exists+" AND sd.valueId IN ("+unhexIds(ids)+"))")
+	}
+
+	if len(conditions) == 0 {
+		return "false"
+	}
+
+	return strings.Join(conditions, " OR ")
+}
+
+func (db *DB) unmarshalDimensions(rows *sql.Rows) ([]*types.Dimension, error) {
+	defer rows.Close()
+
+	dimensions := make([]*types.Dimension, 0)
+
+	for rows.Next() {
+		d := new(types.Dimension)
+		var inserted int64
+		var updated int64
+		err := rows.Scan(&d.Id, &d.OrgId, &inserted, &updated, &d.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Inserted = util.MsToTime(inserted)
+		d.Updated = util.MsToTime(updated)
+		d.Values = make([]*types.DimensionValue, 0)
+
+		dimensions = append(dimensions, d)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dimensions, nil
+}
+
+// addDimensionValues loads the values of dimensions in name order. where
+// selects the dimensions (aliased d) whose values are loaded.
+func (db *DB) addDimensionValues(dimensions []*types.Dimension, where string, args ...interface{}) error {
+	if len(dimensions) == 0 {
+		return nil
+	}
+
+	dimensionMap := make(map[string]*types.Dimension)
+
+	for _, dimension := range dimensions {
+		dimensionMap[dimension.Id] = dimension
+	}
+
+	rows, err := db.Query("SELECT "+dimensionValueFields+" FROM dimensionvalue v JOIN dimension d ON d.id = v.dimensionId WHERE "+where+" ORDER BY v.name", args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		v := new(types.DimensionValue)
+		err = rows.Scan(&v.Id, &v.DimensionId, &v.Name, &v.Inactive)
+
+		if err != nil {
+			return err
+		}
+
+		if dimension, ok := dimensionMap[v.DimensionId]; ok {
+			dimension.Values = append(dimension.Values, v)
+		}
+	}
+
+	return rows.Err()
+}
+
+// saveDimensionValues updates the values of dimension that already exist and
+// inserts the rest. A value id used by another dimension fails to insert.
+func saveDimensionValues(dbTx *sql.Tx, dimension *types.Dimension) error {
+	rows, err := dbTx.Query("SELECT LOWER(HEX(id)) FROM dimensionvalue WHERE dimensionId = UNHEX(?)", dimension.Id)
+
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]bool)
+
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		existing[id] = true
+	}
+
+	rows.Close()
+
+	err = rows.Err()
+
+	if err != nil {
+		return err
+	}
+
+	for _, value := range dimension.Values {
+		if existing[value.Id] {
+			query := "UPDATE dimensionvalue SET name = ?, inactive = ? WHERE id = UNHEX(?)"
+
+			_, err = dbTx.Exec(query, value.Name, value.Inactive, value.Id)
+		} else {
+			query := "INSERT INTO dimensionvalue(id,dimensionId,name,inactive) VALUES (UNHEX(?),UNHEX(?),?,?)"
+
+			_, err = dbTx.Exec(
+				query,
+				value.Id,
+				dimension.Id,
+				value.Name,
+				value.Inactive)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type ImportInterface interface {
+	GetCsvMapping(string) (*types.CsvMapping, error)
+	SaveCsvMapping(*types.CsvMapping) error
+}
+
+const csvMappingFields = "LOWER(HEX(orgId)),LOWER(HEX(accountId)),inserted,updated,delimiter,headerRows,dateColumn,dateFormat,amountColumn,outflowColumn,amountSign,decimalSeparator,descriptionColumn,referenceColumn,categoryColumn"
+
+func (db *DB) GetCsvMapping(accountId string) (*types.CsvMapping, error) {
+	m := new(types.CsvMapping)
+	var inserted int64
+	var updated int64
+
+	err := db.QueryRow("SELECT "+csvMappingFields+" FROM csvmapping WHERE accountId = UNHEX(?)", accountId).Scan(
+		&m.OrgId,
+		&m.AccountId,
+		&inserted,
+		&updated,
+		&m.Delimiter,
+		&m.HeaderRows,
+		&m.DateColumn,
+		&m.DateFormat,
+		&m.AmountColumn,
+		&m.OutflowColumn,
+		&m.AmountSign,
+		&m.DecimalSeparator,
+		&m.DescriptionColumn,
+		&m.ReferenceColumn,
+		&m.CategoryColumn,
+	)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.New("CSV mapping not found")
+	case err != nil:
+		return nil, err
+	}
+
+	m.Inserted = util.MsToTime(inserted)
+	m.Updated = util.MsToTime(updated)
+
+	return m, nil
+}
+
+// SaveCsvMapping replaces the mapping of an account
+func (db *DB) SaveCsvMapping(mapping *types.CsvMapping) (err error) {
+	mapping.Updated = time.Now()
+
+	if mapping.Inserted.IsZero() {
+		mapping.Inserted = mapping.Updated
+	}
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM csvmapping WHERE accountId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query1, mapping.AccountId)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "INSERT INTO csvmapping(orgId,accountId,inserted,updated,delimiter,headerRows,dateColumn,dateFormat,amountColumn,outflowColumn,amountSign,decimalSeparator,descriptionColumn,referenceColumn,categoryColumn) VALUES(UNHEX(?),UNHEX(?),?,?,?,?,?,?,?,?,?,?,?,?,?)"
+
+	_, err = dbTx.Exec(
+		query2,
+		mapping.OrgId,
+		mapping.AccountId,
+		util.TimeToMs(mapping.Inserted),
+		util.TimeToMs(mapping.Updated),
+		mapping.Delimiter,
+		mapping.HeaderRows,
+		mapping.DateColumn,
+		mapping.DateFormat,
+		mapping.AmountColumn,
+		mapping.OutflowColumn,
+		mapping.AmountSign,
+		mapping.DecimalSeparator,
+		mapping.DescriptionColumn,
+		mapping.ReferenceColumn,
+		mapping.CategoryColumn,
+	)
+
+	return
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+)
+
+// Ledger lines are always in ascending order so that running balances can be
+// computed. The split id breaks ties between splits inserted at the same time.
+const ledgerOrder = " ORDER BY s.date ASC, s.inserted ASC, s.id ASC"
+
+// Larger than any number of splits. MySQL requires a limit when using an offset.
+const ledgerMaxLimit = "18446744073709551615"
+
+type LedgerInterface interface {
+	GetLedgerLines([]string, time.Time, time.Time, *types.QueryOptions) ([]*types.LedgerLine, error)
+	GetLedgerSkippedBalances([]string, time.Time, time.Time, *types.QueryOptions) (map[string]*types.PeriodBalance, error)
+}
+
+// GetLedgerLines returns one page of splits on accountIds from start up to end
+func (db *DB) GetLedgerLines(accountIds []string, start time.Time, end time.Time, options *types.QueryOptions) ([]*types.LedgerLine, error) {
+	lines := make([]*types.LedgerLine, 0)
+
+	if len(accountIds) == 0 {
+		return lines, nil
+	}
+
+	query := "SELECT LOWER(HEX(s.transactionId)),LOWER(HEX(s.accountId)),s.date,s.inserted,t.description,s.amount,s.nativeAmount FROM split s" +
+		" JOIN transaction t ON t.id = s.transactionId" +
+		db.getLedgerWhere(accountIds) +
+		ledgerOrder +
+		db.getLedgerLimit(options.Skip, options.Limit)
+
+	rows, err := db.Query(query, util.TimeToMs(start), util.TimeToMs(end))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		l := new(types.LedgerLine)
+		var date int64
+		var inserted int64
+		err = rows.Scan(&l.TransactionId, &l.AccountId, &date, &inserted, &l.Description, &l.Amount, &l.NativeAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		l.Date = util.MsToTime(date)
+		l.Inserted = util.MsToTime(inserted)
+
+		lines = append(lines, l)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// GetLedgerSkippedBalances sums the splits on each account that come before
+// the requested page so that its running balances can start from there
+func (db *DB) GetLedgerSkippedBalances(accountIds []string, start time.Time, end time.Time, options *types.QueryOptions) (map[string]*types.PeriodBalance, error) {
+	balances := make(map[string]*types.PeriodBalance)
+
+	if len(accountIds) == 0 || options.Skip == 0 {
+		return balances, nil
+	}
+
+	query := "SELECT LOWER(HEX(accountId)), SUM(amount), SUM(nativeAmount) FROM (" +
+		"SELECT s.accountId,s.amount,s.nativeAmount FROM split s" +
+		db.getLedgerWhere(accountIds) +
+		ledgerOrder +
+		" LIMIT " + strconv.Itoa(options.Skip) +
+		") AS skipped GROUP BY accountId"
+
+	rows, err := db.Query(query, util.TimeToMs(start), util.TimeToMs(end))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		b := new(types.PeriodBalance)
+		err = rows.Scan(&id, &b.Balance, &b.NativeBalance)
+		if err != nil {
+			return nil, err
+		}
+
+		balances[id] = b
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return balances, nil
+}
+
+func (db *DB) getLedgerWhere(accountIds []string) string {
+	ids := make([]string, len(accountIds))
+
+	for i, accountId := range accountIds {
+		ids[i] = "UNHEX(\"" + accountId + "\")"
+	}
+
+	return " WHERE s.deleted = false AND s.accountId IN (" + strings.Join(ids, ",") + ") AND s.date >= ? AND s.date < ?"
+}
+
+func (db *DB) getLedgerLimit(skip int, limit int) string {
+	switch {
+	case skip != 0 && limit != 0:
+		return " LIMIT " + strconv.Itoa(skip) + ", " + strconv.Itoa(limit)
+	case skip != 0:
+		return " LIMIT " + strconv.Itoa(skip) + ", " + ledgerMaxLimit
+	case limit != 0:
+		return " LIMIT " + strconv.Itoa(limit)
+	default:
+		return ""
+	}
+}
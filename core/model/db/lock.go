@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log"
+)
+
+type LockInterface interface {
+	RunWithLock(string, func() error) (bool, error)
+}
+
+// RunWithLock runs run while holding the MySQL named lock name so that it never
+// runs on more than one server at a time. Named locks are shared by every
+// database of a MySQL server so the name is prefixed with the database. If
+// another server holds the lock run is skipped and false is returned.
+func (db *DB) RunWithLock(name string, run func() error) (bool, error) {
+	ctx := context.Background()
+
+	// named locks belong to a connection so the same one must release it
+	conn, err := db.Conn(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Close()
+
+	var locked sql.NullInt64
+
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(CONCAT(DATABASE(), '.', ?), 0)", name).Scan(&locked)
+
+	if err != nil {
+		return false, err
+	}
+
+	if locked.Int64 != 1 {
+		return false, nil
+	}
+
+	defer func() {
+		var released sql.NullInt64
+		err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.', ?))", name).Scan(&released)
+
+		if err != nil {
+			log.Printf("Failed to release lock %s: %s\n", name, err.Error())
+		}
+	}()
+
+	return true, run()
+}
@@ -11,6 +11,7 @@ import (
 type OrgInterface interface {
 	CreateOrg(*types.Org, string, []*types.Account) error
 	UpdateOrg(*types.Org) error
+	UpdateLockDate(string, *time.Time) error
 	GetOrg(string, string) (*types.Org, error)
 	GetOrgs(string) ([]*types.Org, error)
 	GetOrgUserIds(string) ([]string, error)
@@ -21,7 +22,7 @@ type OrgInterface interface {
 	DeleteInvite(string) error
 }
 
-const orgFields = "LOWER(HEX(o.id)),o.inserted,o.updated,o.name,o.currency,o.`precision`,o.timezone"
+const orgFields = "LOWER(HEX(o.id)),o.inserted,o.updated,o.name,o.currency,o.`precision`,o.timezone,o.lockDate,o.fiscalYearStart"
 const inviteFields = "i.id,LOWER(HEX(i.orgId)),i.inserted,i.updated,i.email,i.accepted"
 
 func (db *DB) CreateOrg(org *types.Org, userId string, accounts []*types.Account) (err error) {
@@ -46,7 +47,7 @@ func (db *DB) CreateOrg(org *types.Org, userId string, accounts []*types.Account
 	org.Updated = org.Inserted
 
 	// create org
-	query1 := "INSERT INTO org(id,inserted,updated,name,currency,`precision`,timezone) VALUES(UNHEX(?),?,?,?,?,?,?)"
+	query1 := "INSERT INTO org(id,inserted,updated,name,currency,`precision`,timezone,fiscalYearStart) VALUES(UNHEX(?),?,?,?,?,?,?,?)"
 
 	res, err := tx.Exec(
 		query1,
@@ -57,6 +58,7 @@ func (db *DB) CreateOrg(org *types.Org, userId string, accounts []*types.Account
 		org.Currency,
 		org.Precision,
 		org.Timezone,
+		org.FiscalYearStart,
 	)
 
 	if err != nil {
@@ -127,25 +129,39 @@ func (db *DB) CreateOrg(org *types.Org, userId string, accounts []*types.Account
 func (db *DB) UpdateOrg(org *types.Org) error {
 	org.Updated = time.Now()
 
-	query := "UPDATE org SET updated = ?, name = ?, timezone = ? WHERE id = UNHEX(?)"
+	query := "UPDATE org SET updated = ?, name = ?, timezone = ?, fiscalYearStart = ? WHERE id = UNHEX(?)"
 	_, err := db.Exec(
 		query,
 		util.TimeToMs(org.Updated),
 		org.Name,
 		org.Timezone,
+		org.FiscalYearStart,
 		org.Id,
 	)
 
 	return err
 }
 
+func (db *DB) UpdateLockDate(orgId string, lockDate *time.Time) error {
+	query := "UPDATE org SET updated = ?, lockDate = ? WHERE id = UNHEX(?)"
+	_, err := db.Exec(
+		query,
+		util.TimeToMs(time.Now()),
+		timeToNullMs(lockDate),
+		orgId,
+	)
+
+	return err
+}
+
 func (db *DB) GetOrg(orgId string, userId string) (*types.Org, error) {
 	var o types.Org
 	var inserted int64
 	var updated int64
+	var lockDate sql.NullInt64
 
 	err := db.QueryRow("SELECT "+orgFields+" FROM org o JOIN userorg ON userorg.orgId = o.id WHERE o.id = UNHEX(?) AND userorg.userId = UNHEX(?)", orgId, userId).
-		Scan(&o.Id, &inserted, &updated, &o.Name, &o.Currency, &o.Precision, &o.Timezone)
+		Scan(&o.Id, &inserted, &updated, &o.Name, &o.Currency, &o.Precision, &o.Timezone, &lockDate, &o.FiscalYearStart)
 
 	switch {
 	case err == sql.ErrNoRows:
@@ -155,6 +171,7 @@ func (db *DB) GetOrg(orgId string, userId string) (*types.Org, error) {
 	default:
 		o.Inserted = util.MsToTime(inserted)
 		o.Updated = util.MsToTime(updated)
+		o.LockDate = nullMsToTime(lockDate)
 		return &o, nil
 	}
 }
@@ -174,14 +191,16 @@ func (db *DB) GetOrgs(userId string) ([]*types.Org, error) {
 		o := new(types.Org)
 		var inserted int64
 		var updated int64
+		var lockDate sql.NullInt64
 
-		err = rows.Scan(&o.Id, &inserted, &updated, &o.Name, &o.Currency, &o.Precision, &o.Timezone)
+		err = rows.Scan(&o.Id, &inserted, &updated, &o.Name, &o.Currency, &o.Precision, &o.Timezone, &lockDate, &o.FiscalYearStart)
 		if err != nil {
 			return nil, err
 		}
 
 		o.Inserted = util.MsToTime(inserted)
 		o.Updated = util.MsToTime(updated)
+		o.LockDate = nullMsToTime(lockDate)
 
 		orgs = append(orgs, o)
 	}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type PeriodInterface interface {
+	GetAccountingPeriods(string) ([]*types.AccountingPeriod, error)
+	GetAccountingPeriod(string) (*types.AccountingPeriod, error)
+	InsertAccountingPeriod(*types.AccountingPeriod) error
+	UpdateAccountingPeriod(*types.AccountingPeriod) error
+}
+
+const periodFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),inserted,updated,start,end,closed"
+
+func (db *DB) GetAccountingPeriods(orgId string) ([]*types.AccountingPeriod, error) {
+	rows, err := db.Query("SELECT "+periodFields+" FROM accountingperiod WHERE orgId = UNHEX(?) ORDER BY start", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	periods := make([]*types.AccountingPeriod, 0)
+
+	for rows.Next() {
+		var inserted int64
+		var updated int64
+		var start int64
+		var end int64
+		p := new(types.AccountingPeriod)
+		err = rows.Scan(&p.Id, &p.OrgId, &p.UserId, &inserted, &updated, &start, &end, &p.Closed)
+		if err != nil {
+			return nil, err
+		}
+
+		p.Inserted = util.MsToTime(inserted)
+		p.Updated = util.MsToTime(updated)
+		p.Start = util.MsToTime(start)
+		p.End = util.MsToTime(end)
+
+		periods = append(periods, p)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+func (db *DB) GetAccountingPeriod(id string) (*types.AccountingPeriod, error) {
+	var p types.AccountingPeriod
+	var inserted int64
+	var updated int64
+	var start int64
+	var end int64
+
+	err := db.QueryRow("SELECT "+periodFields+" FROM accountingperiod WHERE id = UNHEX(?)", id).
+		Scan(&p.Id, &p.OrgId, &p.UserId, &inserted, &updated, &start, &end, &p.Closed)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, errors.New("Period not found")
+	case err != nil:
+		return nil, err
+	default:
+		p.Inserted = util.MsToTime(inserted)
+		p.Updated = util.MsToTime(updated)
+		p.Start = util.MsToTime(start)
+		p.End = util.MsToTime(end)
+		return &p, nil
+	}
+}
+
+func (db *DB) InsertAccountingPeriod(period *types.AccountingPeriod) error {
+	period.Inserted = time.Now()
+	period.Updated = period.Inserted
+
+	query := "INSERT INTO accountingperiod(id,orgId,userId,inserted,updated,start,end,closed) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,?)"
+	_, err := db.Exec(
+		query,
+		period.Id,
+		period.OrgId,
+		period.UserId,
+		util.TimeToMs(period.Inserted),
+		util.TimeToMs(period.Updated),
+		util.TimeToMs(period.Start),
+		util.TimeToMs(period.End),
+		period.Closed,
+	)
+
+	return err
+}
+
+func (db *DB) UpdateAccountingPeriod(period *types.AccountingPeriod) error {
+	period.Updated = time.Now()
+
+	query := "UPDATE accountingperiod SET userId = UNHEX(?), updated = ?, closed = ? WHERE id = UNHEX(?)"
+	_, err := db.Exec(
+		query,
+		period.UserId,
+		util.TimeToMs(period.Updated),
+		period.Closed,
+		period.Id,
+	)
+
+	return err
+}
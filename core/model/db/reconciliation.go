@@ -0,0 +1,295 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"strings"
+	"time"
+)
+
+type ReconciliationInterface interface {
+	GetReconciliations(string) ([]*types.Reconciliation, error)
+	GetReconciliation(string) (*types.Reconciliation, error)
+	InsertReconciliation(*types.Reconciliation) error
+	UpdateReconciliation(*types.Reconciliation) error
+	DeleteReconciliation(string) error
+	GetClearedBalance(string, time.Time) (int64, error)
+	GetClearedTransactionIds(string, time.Time) ([]string, error)
+	UpdateSplitStatus(string, []string, string) error
+	FinishReconciliation(*types.Reconciliation) error
+}
+
+const reconciliationFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),LOWER(HEX(accountId)),inserted,updated,statementDate,statementBalance,finished"
+
+// GetReconciliations returns the reconciliations of an org, latest statement
+// first
+func (db *DB) GetReconciliations(orgId string) ([]*types.Reconciliation, error) {
+	rows, err := db.Query("SELECT "+reconciliationFields+" FROM reconciliation WHERE orgId = UNHEX(?) ORDER BY statementDate DESC, inserted DESC", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return db.unmarshalReconciliations(rows)
+}
+
+func (db *DB) GetReconciliation(id string) (*types.Reconciliation, error) {
+	rows, err := db.Query("SELECT "+reconciliationFields+" FROM reconciliation WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations, err := db.unmarshalReconciliations(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(reconciliations) == 0 {
+		return nil, errors.New("Reconciliation not found")
+	}
+
+	return reconciliations[0], nil
+}
+
+func (db *DB) InsertReconciliation(reconciliation *types.Reconciliation) error {
+	reconciliation.Inserted = time.Now()
+	reconciliation.Updated = reconciliation.Inserted
+
+	query := "INSERT INTO reconciliation(id,orgId,userId,accountId,inserted,updated,statementDate,statementBalance,finished) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,?)"
+
+	_, err := db.Exec(
+		query,
+		reconciliation.Id,
+		reconciliation.OrgId,
+		reconciliation.UserId,
+		reconciliation.AccountId,
+		util.TimeToMs(reconciliation.Inserted),
+		util.TimeToMs(reconciliation.Updated),
+		util.TimeToMs(reconciliation.StatementDate),
+		reconciliation.StatementBalance,
+		reconciliation.Finished,
+	)
+
+	return err
+}
+
+func (db *DB) UpdateReconciliation(reconciliation *types.Reconciliation) error {
+	reconciliation.Updated = time.Now()
+
+	query := "UPDATE reconciliation SET updated = ?, statementDate = ?, statementBalance = ? WHERE id = UNHEX(?)"
+
+	_, err := db.Exec(
+		query,
+		util.TimeToMs(reconciliation.Updated),
+		util.TimeToMs(reconciliation.StatementDate),
+		reconciliation.StatementBalance,
+		reconciliation.Id,
+	)
+
+	return err
+}
+
+func (db *DB) DeleteReconciliation(id string) error {
+	_, err := db.Exec("DELETE FROM reconciliation WHERE id = UNHEX(?)", id)
+
+	return err
+}
+
+// GetClearedBalance returns the sum of the cleared and reconciled splits of an
+// account dated before statementDate in the account currency
+func (db *DB) GetClearedBalance(accountId string, statementDate time.Time) (int64, error) {
+	var balance sql.NullInt64
+
+	query := "SELECT SUM(amount) FROM split WHERE accountId = UNHEX(?) AND date < ? AND status IN ('cleared','reconciled') AND deleted = false"
+
+	err := db.QueryRow(query, accountId, util.TimeToMs(statementDate)).Scan(&balance)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return balance.Int64, nil
+}
+
+// GetClearedTransactionIds returns the ids of the transactions with a cleared
+// split in an account dated before statementDate
+func (db *DB) GetClearedTransactionIds(accountId string, statementDate time.Time) ([]string, error) {
+	query := "SELECT DISTINCT LOWER(HEX(transactionId)) FROM split WHERE accountId = UNHEX(?) AND date < ? AND status = 'cleared' AND deleted = false"
+
+	rows, err := db.Query(query, accountId, util.TimeToMs(statementDate))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := make([]string, 0)
+
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+
+		if err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// UpdateSplitStatus sets the status of the splits of transactionIds in an
+// account. Reconciled splits are left alone. The transactions are marked as
+// updated along with their splits so they are picked up by clients syncing
+// changes.
+func (db *DB) UpdateSplitStatus(accountId string, transactionIds []string, status string) (err error) {
+	if len(transactionIds) == 0 {
+		return
+	}
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	updated := util.TimeToMs(time.Now())
+	ids := "UNHEX(?)" + strings.Repeat(",UNHEX(?)", len(transactionIds)-1)
+
+	args := []interface{}{updated}
+
+	for _, id := range transactionIds {
+		args = append(args, id)
+	}
+
+	query1 := "UPDATE transaction SET updated = ? WHERE id IN (" + ids + ")"
+
+	_, err = dbTx.Exec(query1, args...)
+
+	if err != nil {
+		return
+	}
+
+	args = []interface{}{updated, status}
+
+	for _, id := range transactionIds {
+		args = append(args, id)
+	}
+
+	args = append(args, accountId)
+
+	query2 := "UPDATE split SET updated = ?, status = ? WHERE transactionId IN (" + ids + ") AND accountId = UNHEX(?) AND status != 'reconciled' AND deleted = false"
+
+	_, err = dbTx.Exec(query2, args...)
+
+	return
+}
+
+// FinishReconciliation reconciles the cleared splits of the account of
+// reconciliation dated before its statement date and marks it as finished
+func (db *DB) FinishReconciliation(reconciliation *types.Reconciliation) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	reconciliation.Updated = time.Now()
+	updated := util.TimeToMs(reconciliation.Updated)
+
+	// transactions first so the cleared splits can still be found
+	statementDate := util.TimeToMs(reconciliation.StatementDate)
+
+	query1 := "UPDATE transaction SET updated = ? WHERE id IN (SELECT transactionId FROM split WHERE accountId = UNHEX(?) AND date < ? AND status = 'cleared' AND deleted = false)"
+
+	_, err = dbTx.Exec(query1, updated, reconciliation.AccountId, statementDate)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "UPDATE split SET updated = ?, status = 'reconciled' WHERE accountId = UNHEX(?) AND date < ? AND status = 'cleared' AND deleted = false"
+
+	_, err = dbTx.Exec(query2, updated, reconciliation.AccountId, statementDate)
+
+	if err != nil {
+		return
+	}
+
+	query3 := "UPDATE reconciliation SET updated = ?, finished = true WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query3, updated, reconciliation.Id)
+
+	if err != nil {
+		return
+	}
+
+	reconciliation.Finished = true
+
+	return
+}
+
+func (db *DB) unmarshalReconciliations(rows *sql.Rows) ([]*types.Reconciliation, error) {
+	defer rows.Close()
+
+	reconciliations := make([]*types.Reconciliation, 0)
+
+	for rows.Next() {
+		r := new(types.Reconciliation)
+		var inserted int64
+		var updated int64
+		var statementDate int64
+		err := rows.Scan(&r.Id, &r.OrgId, &r.UserId, &r.AccountId, &inserted, &updated, &statementDate, &r.StatementBalance, &r.Finished)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Inserted = util.MsToTime(inserted)
+		r.Updated = util.MsToTime(updated)
+		r.StatementDate = util.MsToTime(statementDate)
+
+		reconciliations = append(reconciliations, r)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliations, nil
+}
@@ -0,0 +1,338 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type RecurringTransactionInterface interface {
+	GetRecurringTransactions(string) ([]*types.RecurringTransaction, error)
+	GetRecurringTransaction(string) (*types.RecurringTransaction, error)
+	GetDueRecurringTransactions(time.Time) ([]*types.RecurringTransaction, error)
+	InsertRecurringTransaction(*types.RecurringTransaction) error
+	UpdateRecurringTransaction(*types.RecurringTransaction) error
+	UpdateRecurringTransactionRun(*types.RecurringTransaction) error
+	DeleteRecurringTransaction(string) error
+}
+
+const recurringTransactionFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),inserted,updated,description,data,rule,start,end,lastRun,nextRun,lastError"
+
+func (db *DB) GetRecurringTransactions(orgId string) ([]*types.RecurringTransaction, error) {
+	rows, err := db.Query("SELECT "+recurringTransactionFields+" FROM recurringtransaction WHERE orgId = UNHEX(?) ORDER BY description", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recurrings, err := db.unmarshalRecurringTransactions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addRecurringSplits(recurrings, "r.orgId = UNHEX(?)", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recurrings, nil
+}
+
+func (db *DB) GetRecurringTransaction(id string) (*types.RecurringTransaction, error) {
+	rows, err := db.Query("SELECT "+recurringTransactionFields+" FROM recurringtransaction WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recurrings, err := db.unmarshalRecurringTransactions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(recurrings) == 0 {
+		return nil, errors.New("Recurring transaction not found")
+	}
+
+	err = db.addRecurringSplits(recurrings, "s.recurringTransactionId = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recurrings[0], nil
+}
+
+// GetDueRecurringTransactions returns the recurring transactions of every org
+// whose next run is on or before date
+func (db *DB) GetDueRecurringTransactions(date time.Time) ([]*types.RecurringTransaction, error) {
+	ms := util.TimeToMs(date)
+
+	rows, err := db.Query("SELECT "+recurringTransactionFields+" FROM recurringtransaction WHERE nextRun <= ? ORDER BY nextRun", ms)
+
+	if err != nil {
+		return nil, err
+	}
+
+	recurrings, err := db.unmarshalRecurringTransactions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addRecurringSplits(recurrings, "r.nextRun <= ?", ms)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recurrings, nil
+}
+
+func (db *DB) InsertRecurringTransaction(recurring *types.RecurringTransaction) (err error) {
+	recurring.Inserted = time.Now()
+	recurring.Updated = recurring.Inserted
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query := "INSERT INTO recurringtransaction(id,orgId,userId,inserted,updated,description,data,rule,start,end,lastRun,nextRun,lastError) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,?,?,?,?,?,?)"
+
+	_, err = dbTx.Exec(
+		query,
+		recurring.Id,
+		recurring.OrgId,
+		recurring.UserId,
+		util.TimeToMs(recurring.Inserted),
+		util.TimeToMs(recurring.Updated),
+		recurring.Description,
+		recurring.Data,
+		recurring.Rule,
+		util.TimeToMs(recurring.Start),
+		timeToNullMs(recurring.End),
+		timeToNullMs(recurring.LastRun),
+		timeToNullMs(recurring.NextRun),
+		recurring.LastError,
+	)
+
+	if err != nil {
+		return
+	}
+
+	err = insertRecurringSplits(dbTx, recurring)
+
+	return
+}
+
+func (db *DB) UpdateRecurringTransaction(recurring *types.RecurringTransaction) (err error) {
+	recurring.Updated = time.Now()
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "UPDATE recurringtransaction SET userId = UNHEX(?), updated = ?, description = ?, data = ?, rule = ?, start = ?, end = ?, nextRun = ?, lastError = ? WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(
+		query1,
+		recurring.UserId,
+		util.TimeToMs(recurring.Updated),
+		recurring.Description,
+		recurring.Data,
+		recurring.Rule,
+		util.TimeToMs(recurring.Start),
+		timeToNullMs(recurring.End),
+		timeToNullMs(recurring.NextRun),
+		recurring.LastError,
+		recurring.Id,
+	)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM recurringsplit WHERE recurringTransactionId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, recurring.Id)
+
+	if err != nil {
+		return
+	}
+
+	err = insertRecurringSplits(dbTx, recurring)
+
+	return
+}
+
+// UpdateRecurringTransactionRun saves lastRun, nextRun and lastError after an
+// occurrence has been created or skipped
+func (db *DB) UpdateRecurringTransactionRun(recurring *types.RecurringTransaction) error {
+	query := "UPDATE recurringtransaction SET lastRun = ?, nextRun = ?, lastError = ? WHERE id = UNHEX(?)"
+
+	_, err := db.Exec(
+		query,
+		timeToNullMs(recurring.LastRun),
+		timeToNullMs(recurring.NextRun),
+		recurring.LastError,
+		recurring.Id,
+	)
+
+	return err
+}
+
+func (db *DB) DeleteRecurringTransaction(id string) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM recurringsplit WHERE recurringTransactionId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query1, id)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM recurringtransaction WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, id)
+
+	return
+}
+
+func (db *DB) unmarshalRecurringTransactions(rows *sql.Rows) ([]*types.RecurringTransaction, error) {
+	defer rows.Close()
+
+	recurrings := make([]*types.RecurringTransaction, 0)
+
+	for rows.Next() {
+		r := new(types.RecurringTransaction)
+		var inserted int64
+		var updated int64
+		var start int64
+		var end sql.NullInt64
+		var lastRun sql.NullInt64
+		var nextRun sql.NullInt64
+		err := rows.Scan(&r.Id, &r.OrgId, &r.UserId, &inserted, &updated, &r.Description, &r.Data, &r.Rule, &start, &end, &lastRun, &nextRun, &r.LastError)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Inserted = util.MsToTime(inserted)
+		r.Updated = util.MsToTime(updated)
+		r.Start = util.MsToTime(start)
+		r.End = nullMsToTime(end)
+		r.LastRun = nullMsToTime(lastRun)
+		r.NextRun = nullMsToTime(nextRun)
+		r.Splits = make([]*types.Split, 0)
+
+		recurrings = append(recurrings, r)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return recurrings, nil
+}
+
+// addRecurringSplits loads the splits matching where and attaches them to
+// recurrings. where can refer to recurringsplit as s and recurringtransaction
+// as r.
+func (db *DB) addRecurringSplits(recurrings []*types.RecurringTransaction, where string, args ...interface{}) error {
+	query := "SELECT LOWER(HEX(s.recurringTransactionId)),LOWER(HEX(s.accountId)),s.amount,s.nativeAmount FROM recurringsplit s JOIN recurringtransaction r ON r.id = s.recurringTransactionId WHERE " + where + " ORDER BY s.id"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	recurringMap := make(map[string]*types.RecurringTransaction)
+
+	for _, r := range recurrings {
+		recurringMap[r.Id] = r
+	}
+
+	for rows.Next() {
+		s := new(types.Split)
+		err := rows.Scan(&s.TransactionId, &s.AccountId, &s.Amount, &s.NativeAmount)
+		if err != nil {
+			return err
+		}
+
+		if r, ok := recurringMap[s.TransactionId]; ok {
+			r.Splits = append(r.Splits, s)
+		}
+	}
+
+	return rows.Err()
+}
+
+func insertRecurringSplits(dbTx *sql.Tx, recurring *types.RecurringTransaction) error {
+	for _, split := range recurring.Splits {
+		query := "INSERT INTO recurringsplit(recurringTransactionId,accountId,amount,nativeAmount) VALUES (UNHEX(?),UNHEX(?),?,?)"
+
+		_, err := dbTx.Exec(
+			query,
+			recurring.Id,
+			split.AccountId,
+			split.Amount,
+			split.NativeAmount)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
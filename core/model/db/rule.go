@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type RuleInterface interface {
+	GetRules(string) ([]*types.Rule, error)
+	GetRule(string) (*types.Rule, error)
+	InsertRule(*types.Rule) error
+	UpdateRule(*types.Rule) error
+	DeleteRule(string) error
+}
+
+const ruleFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,priority,descriptionPattern,minAmount,maxAmount,LOWER(HEX(accountId)),payee"
+
+// GetRules returns the rules of an org in the order they are applied
+func (db *DB) GetRules(orgId string) ([]*types.Rule, error) {
+	rows, err := db.Query("SELECT "+ruleFields+" FROM rule WHERE orgId = UNHEX(?) ORDER BY priority, name", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return db.unmarshalRules(rows)
+}
+
+func (db *DB) GetRule(id string) (*types.Rule, error) {
+	rows, err := db.Query("SELECT "+ruleFields+" FROM rule WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := db.unmarshalRules(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return nil, errors.New("Rule not found")
+	}
+
+	return rules[0], nil
+}
+
+func (db *DB) InsertRule(rule *types.Rule) error {
+	rule.Inserted = time.Now()
+	rule.Updated = rule.Inserted
+
+	query := "INSERT INTO rule(id,orgId,inserted,updated,name,priority,descriptionPattern,minAmount,maxAmount,accountId,payee) VALUES(UNHEX(?),UNHEX(?),?,?,?,?,?,?,?,UNHEX(?),?)"
+
+	_, err := db.Exec(
+		query,
+		rule.Id,
+		rule.OrgId,
+		util.TimeToMs(rule.Inserted),
+		util.TimeToMs(rule.Updated),
+		rule.Name,
+		rule.Priority,
+		rule.DescriptionPattern,
+		int64ToNull(rule.MinAmount),
+		int64ToNull(rule.MaxAmount),
+		rule.AccountId,
+		rule.Payee,
+	)
+
+	return err
+}
+
+func (db *DB) UpdateRule(rule *types.Rule) error {
+	rule.Updated = time.Now()
+
+	query := "UPDATE rule SET updated = ?, name = ?, priority = ?, descriptionPattern = ?, minAmount = ?, maxAmount = ?, accountId = UNHEX(?), payee = ? WHERE id = UNHEX(?)"
+
+	_, err := db.Exec(
+		query,
+		util.TimeToMs(rule.Updated),
+		rule.Name,
+		rule.Priority,
+		rule.DescriptionPattern,
+		int64ToNull(rule.MinAmount),
+		int64ToNull(rule.MaxAmount),
+		rule.AccountId,
+		rule.Payee,
+		rule.Id,
+	)
+
+	return err
+}
+
+func (db *DB) DeleteRule(id string) error {
+	_, err := db.Exec("DELETE FROM rule WHERE id = UNHEX(?)", id)
+
+	return err
+}
+
+func (db *DB) unmarshalRules(rows *sql.Rows) ([]*types.Rule, error) {
+	defer rows.Close()
+
+	rules := make([]*types.Rule, 0)
+
+	for rows.Next() {
+		r := new(types.Rule)
+		var inserted int64
+		var updated int64
+		var minAmount sql.NullInt64
+		var maxAmount sql.NullInt64
+		err := rows.Scan(&r.Id, &r.OrgId, &inserted, &updated, &r.Name, &r.Priority, &r.DescriptionPattern, &minAmount, &maxAmount, &r.AccountId, &r.Payee)
+		if err != nil {
+			return nil, err
+		}
+
+		r.Inserted = util.MsToTime(inserted)
+		r.Updated = util.MsToTime(updated)
+
+		if minAmount.Valid {
+			r.MinAmount = &minAmount.Int64
+		}
+
+		if maxAmount.Valid {
+			r.MaxAmount = &maxAmount.Int64
+		}
+
+		rules = append(rules, r)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
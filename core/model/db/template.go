@@ -0,0 +1,291 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"time"
+)
+
+type TemplateInterface interface {
+	GetTemplates(string) ([]*types.Template, error)
+	GetTemplate(string) (*types.Template, error)
+	InsertTemplate(*types.Template) error
+	UpdateTemplate(*types.Template) error
+	DeleteTemplate(string) error
+}
+
+const templateFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),inserted,updated,name,description,data"
+
+func (db *DB) GetTemplates(orgId string) ([]*types.Template, error) {
+	rows, err := db.Query("SELECT "+templateFields+" FROM template WHERE orgId = UNHEX(?) ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := db.unmarshalTemplates(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addTemplateSplits(templates, "t.orgId = UNHEX(?)", orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (db *DB) GetTemplate(id string) (*types.Template, error) {
+	rows, err := db.Query("SELECT "+templateFields+" FROM template WHERE id = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := db.unmarshalTemplates(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(templates) == 0 {
+		return nil, errors.New("Template not found")
+	}
+
+	err = db.addTemplateSplits(templates, "s.templateId = UNHEX(?)", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templates[0], nil
+}
+
+func (db *DB) InsertTemplate(template *types.Template) (err error) {
+	template.Inserted = time.Now()
+	template.Updated = template.Inserted
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query := "INSERT INTO template(id,orgId,inserted,updated,name,description,data) VALUES(UNHEX(?),UNHEX(?),?,?,?,?,?)"
+
+	_, err = dbTx.Exec(
+		query,
+		template.Id,
+		template.OrgId,
+		util.TimeToMs(template.Inserted),
+		util.TimeToMs(template.Updated),
+		template.Name,
+		template.Description,
+		template.Data,
+	)
+
+	if err != nil {
+		return
+	}
+
+	err = insertTemplateSplits(dbTx, template)
+
+	return
+}
+
+func (db *DB) UpdateTemplate(template *types.Template) (err error) {
+	template.Updated = time.Now()
+
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "UPDATE template SET updated = ?, name = ?, description = ?, data = ? WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(
+		query1,
+		util.TimeToMs(template.Updated),
+		template.Name,
+		template.Description,
+		template.Data,
+		template.Id,
+	)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM templatesplit WHERE templateId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, template.Id)
+
+	if err != nil {
+		return
+	}
+
+	err = insertTemplateSplits(dbTx, template)
+
+	return
+}
+
+func (db *DB) DeleteTemplate(id string) (err error) {
+	dbTx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM templatesplit WHERE templateId = UNHEX(?)"
+
+	_, err = dbTx.Exec(query1, id)
+
+	if err != nil {
+		return
+	}
+
+	query2 := "DELETE FROM template WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(query2, id)
+
+	return
+}
+
+func (db *DB) unmarshalTemplates(rows *sql.Rows) ([]*types.Template, error) {
+	defer rows.Close()
+
+	templates := make([]*types.Template, 0)
+
+	for rows.Next() {
+		t := new(types.Template)
+		var inserted int64
+		var updated int64
+		err := rows.Scan(&t.Id, &t.OrgId, &inserted, &updated, &t.Name, &t.Description, &t.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Inserted = util.MsToTime(inserted)
+		t.Updated = util.MsToTime(updated)
+		t.Splits = make([]*types.TemplateSplit, 0)
+
+		templates = append(templates, t)
+	}
+
+	err := rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// addTemplateSplits loads the splits matching where and attaches them to
+// templates. where can refer to templatesplit as s and template as t.
+func (db *DB) addTemplateSplits(templates []*types.Template, where string, args ...interface{}) error {
+	query := "SELECT LOWER(HEX(s.templateId)),LOWER(HEX(s.accountId)),s.amount,s.percent FROM templatesplit s JOIN template t ON t.id = s.templateId WHERE " + where + " ORDER BY s.id"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	templateMap := make(map[string]*types.Template)
+
+	for _, t := range templates {
+		templateMap[t.Id] = t
+	}
+
+	for rows.Next() {
+		s := new(types.TemplateSplit)
+		var amount sql.NullInt64
+		var percent sql.NullFloat64
+		err := rows.Scan(&s.TemplateId, &s.AccountId, &amount, &percent)
+		if err != nil {
+			return err
+		}
+
+		if amount.Valid {
+			s.Amount = &amount.Int64
+		}
+
+		if percent.Valid {
+			s.Percent = &percent.Float64
+		}
+
+		if t, ok := templateMap[s.TemplateId]; ok {
+			t.Splits = append(t.Splits, s)
+		}
+	}
+
+	return rows.Err()
+}
+
+func insertTemplateSplits(dbTx *sql.Tx, template *types.Template) error {
+	for _, split := range template.Splits {
+		var percent sql.NullFloat64
+
+		if split.Percent != nil {
+			percent.Float64 = *split.Percent
+			percent.Valid = true
+		}
+
+		query := "INSERT INTO templatesplit(templateId,accountId,amount,percent) VALUES (UNHEX(?),UNHEX(?),?,?)"
+
+		_, err := dbTx.Exec(
+			query,
+			template.Id,
+			split.AccountId,
+			int64ToNull(split.Amount),
+			percent)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
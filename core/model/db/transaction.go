@@ -10,16 +10,22 @@ import (
 	"github.com/openaccounting/oa-server/core/util"
 )
 
-const txFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),date,inserted,updated,description,data,deleted"
-const splitFields = "id,LOWER(HEX(transactionId)),LOWER(HEX(accountId)),date,inserted,updated,amount,nativeAmount,deleted"
+const txFields = "LOWER(HEX(id)),LOWER(HEX(orgId)),LOWER(HEX(userId)),date,inserted,updated,description,LOWER(HEX(contactId)),data,LOWER(HEX(reversalOf)),autoReverseDate,deleted"
+const splitFields = "id,LOWER(HEX(transactionId)),LOWER(HEX(accountId)),date,inserted,updated,amount,nativeAmount,externalId,status,memo,quantity,unitPrice,deleted"
 
 type TransactionInterface interface {
 	InsertTransaction(*types.Transaction) error
+	InsertTransactions([]*types.Transaction) error
 	GetTransactionById(string) (*types.Transaction, error)
 	GetTransactionsByAccount(string, *types.QueryOptions) ([]*types.Transaction, error)
 	GetTransactionsByOrg(string, *types.QueryOptions, []string) ([]*types.Transaction, error)
 	DeleteTransaction(string) error
 	DeleteAndInsertTransaction(string, *types.Transaction) error
+	GetReversals(string) ([]*types.Transaction, error)
+	GetDueAutoReversals(time.Time) ([]*types.Transaction, error)
+	ClearAutoReverseDate(string, time.Time) error
+	GetSplitExternalIds(string, []string) ([]string, error)
+	GetTransactionsWithSplit(string, int64, time.Time, time.Time) ([]*types.Transaction, error)
 }
 
 func (db *DB) InsertTransaction(transaction *types.Transaction) (err error) {
@@ -41,38 +47,32 @@ func (db *DB) InsertTransaction(transaction *types.Transaction) (err error) {
 		}
 	}()
 
-	// save tx
-	query1 := "INSERT INTO transaction(id,orgId,userId,date,inserted,updated,description,data) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,?)"
+	err = insertTransaction(dbTx, transaction)
 
-	_, err = dbTx.Exec(
-		query1,
-		transaction.Id,
-		transaction.OrgId,
-		transaction.UserId,
-		util.TimeToMs(transaction.Date),
-		util.TimeToMs(transaction.Inserted),
-		util.TimeToMs(transaction.Updated),
-		transaction.Description,
-		transaction.Data,
-	)
+	return
+}
+
+// InsertTransactions inserts all transactions in a single db transaction
+func (db *DB) InsertTransactions(transactions []*types.Transaction) (err error) {
+	dbTx, err := db.Begin()
 
 	if err != nil {
 		return
 	}
 
-	// save splits
-	for _, split := range transaction.Splits {
-		query := "INSERT INTO split(transactionId,accountId,date,inserted,updated,amount,nativeAmount) VALUES (UNHEX(?),UNHEX(?),?,?,?,?,?)"
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
 
-		_, err = dbTx.Exec(
-			query,
-			transaction.Id,
-			split.AccountId,
-			util.TimeToMs(transaction.Date),
-			util.TimeToMs(transaction.Inserted),
-			util.TimeToMs(transaction.Updated),
-			split.Amount,
-			split.NativeAmount)
+	for _, transaction := range transactions {
+		err = insertTransaction(dbTx, transaction)
 
 		if err != nil {
 			return
@@ -91,7 +91,7 @@ func (db *DB) GetTransactionById(id string) (*types.Transaction, error) {
 		return nil, err
 	}
 
-	rows, err := db.Query("SELECT "+splitFields+" FROM split WHERE transactionId = UNHEX(?) ORDER BY id", t.Id)
+	rows, err := db.Query("SELECT "+splitFields+" FROM split WHERE transactionId = UNHEX(?) ORDER BY position, id", t.Id)
 
 	if err != nil {
 		return nil, err
@@ -103,13 +103,25 @@ func (db *DB) GetTransactionById(id string) (*types.Transaction, error) {
 		return nil, err
 	}
 
+	err = db.addTags([]*types.Transaction{t})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addSplitDimensions([]*types.Transaction{t})
+
+	if err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
 func (db *DB) GetTransactionsByAccount(accountId string, options *types.QueryOptions) ([]*types.Transaction, error) {
 	query := "SELECT DISTINCT LOWER(HEX(s.transactionId)),s.date,s.inserted,s.updated FROM split s"
 
-	if options.DescriptionStartsWith != "" {
+	if options.DescriptionStartsWith != "" || options.ContactId != "" {
 		query = query + " JOIN transaction t ON t.id = s.transactionId"
 	}
 
@@ -170,7 +182,7 @@ func (db *DB) GetTransactionsByAccount(accountId string, options *types.QueryOpt
 		transactionMap[t.Id] = t
 	}
 
-	rows, err = db.Query("SELECT " + splitFields + " FROM split WHERE transactionId IN (" + strings.Join(ids, ",") + ") ORDER BY id")
+	rows, err = db.Query("SELECT " + splitFields + " FROM split WHERE transactionId IN (" + strings.Join(ids, ",") + ") ORDER BY position, id")
 
 	if err != nil {
 		return nil, err
@@ -187,6 +199,18 @@ func (db *DB) GetTransactionsByAccount(accountId string, options *types.QueryOpt
 		transaction.Splits = append(transaction.Splits, s)
 	}
 
+	err = db.addTags(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addSplitDimensions(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
@@ -201,7 +225,7 @@ func (db *DB) GetTransactionsByOrg(orgId string, options *types.QueryOptions, ac
 
 	query := "SELECT DISTINCT LOWER(HEX(s.transactionId)),s.date,s.inserted,s.updated FROM split s"
 
-	if options.DescriptionStartsWith != "" {
+	if options.DescriptionStartsWith != "" || options.ContactId != "" {
 		query = query + " JOIN transaction t ON t.id = s.transactionId"
 	}
 
@@ -263,7 +287,7 @@ func (db *DB) GetTransactionsByOrg(orgId string, options *types.QueryOptions, ac
 		transactionMap[t.Id] = t
 	}
 
-	rows, err = db.Query("SELECT " + splitFields + " FROM split WHERE transactionId IN (" + strings.Join(ids, ",") + ") ORDER BY id")
+	rows, err = db.Query("SELECT " + splitFields + " FROM split WHERE transactionId IN (" + strings.Join(ids, ",") + ") ORDER BY position, id")
 
 	if err != nil {
 		return nil, err
@@ -280,6 +304,18 @@ func (db *DB) GetTransactionsByOrg(orgId string, options *types.QueryOptions, ac
 		transaction.Splits = append(transaction.Splits, s)
 	}
 
+	err = db.addTags(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addSplitDimensions(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return transactions, nil
 }
 
@@ -301,41 +337,109 @@ func (db *DB) DeleteTransaction(id string) (err error) {
 		}
 	}()
 
-	updatedTime := util.TimeToMs(time.Now())
-
-	// mark splits as deleted
+	err = deleteTransaction(dbTx, id, util.TimeToMs(time.Now()))
 
-	query1 := "UPDATE split SET updated = ?, deleted = true WHERE transactionId = UNHEX(?)"
+	return
+}
 
-	_, err = dbTx.Exec(
-		query1,
-		updatedTime,
-		id,
-	)
+func (db *DB) DeleteAndInsertTransaction(oldId string, transaction *types.Transaction) (err error) {
+	// Save to db
+	dbTx, err := db.Begin()
 
 	if err != nil {
 		return
 	}
 
-	// mark transaction as deleted
+	defer func() {
+		if p := recover(); p != nil {
+			dbTx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			dbTx.Rollback()
+		} else {
+			err = dbTx.Commit()
+		}
+	}()
 
-	query2 := "UPDATE transaction SET updated = ?, deleted = true WHERE id = UNHEX(?)"
+	err = deleteTransaction(dbTx, oldId, util.TimeToMs(transaction.Updated))
+
+	if err != nil {
+		return
+	}
+
+	// keep reversals linked to the new version of the transaction
+	query := "UPDATE transaction SET reversalOf = UNHEX(?) WHERE reversalOf = UNHEX(?)"
 
 	_, err = dbTx.Exec(
-		query2,
-		updatedTime,
-		id,
+		query,
+		transaction.Id,
+		oldId,
 	)
 
 	if err != nil {
 		return
 	}
 
+	err = insertTransaction(dbTx, transaction)
+
 	return
 }
 
-func (db *DB) DeleteAndInsertTransaction(oldId string, transaction *types.Transaction) (err error) {
-	// Save to db
+// GetReversals returns the undeleted transactions that reverse transaction id.
+// Splits are not included.
+func (db *DB) GetReversals(id string) ([]*types.Transaction, error) {
+	rows, err := db.Query("SELECT "+txFields+" FROM transaction WHERE reversalOf = UNHEX(?) AND deleted = false", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return db.unmarshalTransactions(rows)
+}
+
+// GetDueAutoReversals returns undeleted transactions with an autoReverseDate
+// on or before date that have never been reversed. A reversal that was later
+// deleted still counts so that it is not recreated.
+func (db *DB) GetDueAutoReversals(date time.Time) ([]*types.Transaction, error) {
+	query := "SELECT " + txFields + " FROM transaction t WHERE t.deleted = false AND t.autoReverseDate <= ? AND NOT EXISTS (SELECT 1 FROM transaction r WHERE r.reversalOf = t.id) ORDER BY t.autoReverseDate"
+
+	rows, err := db.Query(query, util.TimeToMs(date))
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := db.unmarshalTransactions(rows)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addSplits(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// reversals copy the tags and split dimensions
+	err = db.addTags(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.addSplitDimensions(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+// ClearAutoReverseDate removes the autoReverseDate of transaction id so that
+// it is no longer picked up by GetDueAutoReversals
+func (db *DB) ClearAutoReverseDate(id string, updated time.Time) (err error) {
 	dbTx, err := db.Begin()
 
 	if err != nil {
@@ -353,75 +457,196 @@ func (db *DB) DeleteAndInsertTransaction(oldId string, transaction *types.Transa
 		}
 	}()
 
-	updatedTime := util.TimeToMs(transaction.Updated)
+	updatedMs := util.TimeToMs(updated)
 
-	// mark splits as deleted
+	// keep updated in sync between the transaction and its splits
+	_, err = dbTx.Exec("UPDATE transaction SET autoReverseDate = NULL, updated = ? WHERE id = UNHEX(?)", updatedMs, id)
 
-	query1 := "UPDATE split SET updated = ?, deleted = true WHERE transactionId = UNHEX(?)"
+	if err != nil {
+		return
+	}
 
-	_, err = dbTx.Exec(
-		query1,
-		updatedTime,
-		oldId,
-	)
+	_, err = dbTx.Exec("UPDATE split SET updated = ? WHERE transactionId = UNHEX(?)", updatedMs, id)
+
+	return
+}
+
+// GetTransactionsWithSplit returns undeleted transactions that have a split
+// for amount in account accountId dated between start and end inclusive
+func (db *DB) GetTransactionsWithSplit(accountId string, amount int64, start time.Time, end time.Time) ([]*types.Transaction, error) {
+	query := "SELECT " + txFields + " FROM transaction WHERE deleted = false AND id IN (SELECT transactionId FROM split WHERE accountId = UNHEX(?) AND date >= ? AND date <= ? AND amount = ? AND deleted = false) ORDER BY date"
+
+	rows, err := db.Query(query, accountId, util.TimeToMs(start), util.TimeToMs(end), amount)
 
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	// mark transaction as deleted
+	transactions, err := db.unmarshalTransactions(rows)
 
-	query2 := "UPDATE transaction SET updated = ?, deleted = true WHERE id = UNHEX(?)"
+	if err != nil {
+		return nil, err
+	}
 
-	_, err = dbTx.Exec(
-		query2,
-		updatedTime,
-		oldId,
-	)
+	err = db.addSplits(transactions)
 
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	// save new tx
-	query3 := "INSERT INTO transaction(id,orgId,userId,date,inserted,updated,description,data) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,?)"
+	return transactions, nil
+}
 
-	_, err = dbTx.Exec(
-		query3,
-		transaction.Id,
-		transaction.OrgId,
-		transaction.UserId,
-		util.TimeToMs(transaction.Date),
-		util.TimeToMs(transaction.Inserted),
-		updatedTime,
-		transaction.Description,
-		transaction.Data,
-	)
+// addSplits loads the splits of each transaction
+func (db *DB) addSplits(transactions []*types.Transaction) error {
+	for _, transaction := range transactions {
+		rows, err := db.Query("SELECT "+splitFields+" FROM split WHERE transactionId = UNHEX(?) ORDER BY position, id", transaction.Id)
+
+		if err != nil {
+			return err
+		}
+
+		transaction.Splits, err = db.unmarshalSplits(rows)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addTags loads the tags of transactions in alphabetical order
+func (db *DB) addTags(transactions []*types.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	transactionMap := make(map[string]*types.Transaction)
+	ids := make([]string, len(transactions))
+
+	for i, transaction := range transactions {
+		transaction.Tags = make([]string, 0)
+		transactionMap[transaction.Id] = transaction
+		ids[i] = transaction.Id
+	}
+
+	rows, err := db.Query("SELECT LOWER(HEX(transactionId)),tag FROM transactiontag WHERE transactionId IN (" + unhexIds(ids) + ") ORDER BY tag")
 
 	if err != nil {
-		return
+		return err
 	}
 
-	// save splits
-	for _, split := range transaction.Splits {
-		query := "INSERT INTO split(transactionId,accountId,date,inserted,updated,amount,nativeAmount) VALUES (UNHEX(?),UNHEX(?),?,?,?,?,?)"
+	defer rows.Close()
 
-		_, err = dbTx.Exec(
-			query,
-			transaction.Id,
-			split.AccountId,
-			util.TimeToMs(transaction.Date),
-			util.TimeToMs(transaction.Inserted),
-			updatedTime,
-			split.Amount,
-			split.NativeAmount)
+	for rows.Next() {
+		var id string
+		var tag string
+		err = rows.Scan(&id, &tag)
 
 		if err != nil {
-			return
+			return err
 		}
+
+		transaction := transactionMap[id]
+		transaction.Tags = append(transaction.Tags, tag)
 	}
 
-	return
+	return rows.Err()
+}
+
+// addSplitDimensions loads the dimension values of the splits of transactions.
+// Splits must already be loaded in order of their position.
+func (db *DB) addSplitDimensions(transactions []*types.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	transactionMap := make(map[string]*types.Transaction)
+	ids := make([]string, len(transactions))
+
+	for i, transaction := range transactions {
+		for _, split := range transaction.Splits {
+			split.Dimensions = make(map[string]string)
+		}
+
+		transactionMap[transaction.Id] = transaction
+		ids[i] = transaction.Id
+	}
+
+	rows, err := db.Query("SELECT LOWER(HEX(transactionId)),position,LOWER(HEX(dimensionId)),LOWER(HEX(valueId)) FROM splitdimension WHERE transactionId IN (" + unhexIds(ids) + ")")
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		var position int
+		var dimensionId string
+		var valueId string
+		err = rows.Scan(&id, &position, &dimensionId, &valueId)
+
+		if err != nil {
+			return err
+		}
+
+		transaction := transactionMap[id]
+
+		if position < len(transaction.Splits) {
+			transaction.Splits[position].Dimensions[dimensionId] = valueId
+		}
+	}
+
+	return rows.Err()
+}
+
+// GetSplitExternalIds returns which of externalIds are already used by splits
+// of account accountId. Deleted splits are included so that an imported
+// transaction that was deleted is not imported again.
+func (db *DB) GetSplitExternalIds(accountId string, externalIds []string) ([]string, error) {
+	found := make([]string, 0)
+
+	if len(externalIds) == 0 {
+		return found, nil
+	}
+
+	args := []interface{}{accountId}
+
+	for _, externalId := range externalIds {
+		args = append(args, externalId)
+	}
+
+	query := "SELECT DISTINCT externalId FROM split WHERE accountId = UNHEX(?) AND externalId IN (?" + strings.Repeat(",?", len(externalIds)-1) + ")"
+
+	rows, err := db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var externalId string
+		err = rows.Scan(&externalId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, externalId)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return found, nil
 }
 
 func (db *DB) unmarshalTransaction(row *sql.Row) (*types.Transaction, error) {
@@ -430,8 +655,11 @@ func (db *DB) unmarshalTransaction(row *sql.Row) (*types.Transaction, error) {
 	var date int64
 	var inserted int64
 	var updated int64
+	var contactId sql.NullString
+	var reversalOf sql.NullString
+	var autoReverseDate sql.NullInt64
 
-	err := row.Scan(&t.Id, &t.OrgId, &t.UserId, &date, &inserted, &updated, &t.Description, &t.Data, &t.Deleted)
+	err := row.Scan(&t.Id, &t.OrgId, &t.UserId, &date, &inserted, &updated, &t.Description, &contactId, &t.Data, &reversalOf, &autoReverseDate, &t.Deleted)
 
 	if err != nil {
 		return nil, err
@@ -440,6 +668,9 @@ func (db *DB) unmarshalTransaction(row *sql.Row) (*types.Transaction, error) {
 	t.Date = util.MsToTime(date)
 	t.Inserted = util.MsToTime(inserted)
 	t.Updated = util.MsToTime(updated)
+	t.ContactId = contactId.String
+	t.ReversalOf = reversalOf.String
+	t.AutoReverseDate = nullMsToTime(autoReverseDate)
 
 	return t, nil
 }
@@ -454,7 +685,10 @@ func (db *DB) unmarshalTransactions(rows *sql.Rows) ([]*types.Transaction, error
 		var date int64
 		var inserted int64
 		var updated int64
-		err := rows.Scan(&t.Id, &t.OrgId, &t.UserId, &date, &inserted, &updated, &t.Description, &t.Data, &t.Deleted)
+		var contactId sql.NullString
+		var reversalOf sql.NullString
+		var autoReverseDate sql.NullInt64
+		err := rows.Scan(&t.Id, &t.OrgId, &t.UserId, &date, &inserted, &updated, &t.Description, &contactId, &t.Data, &reversalOf, &autoReverseDate, &t.Deleted)
 		if err != nil {
 			return nil, err
 		}
@@ -462,6 +696,9 @@ func (db *DB) unmarshalTransactions(rows *sql.Rows) ([]*types.Transaction, error
 		t.Date = util.MsToTime(date)
 		t.Inserted = util.MsToTime(inserted)
 		t.Updated = util.MsToTime(updated)
+		t.ContactId = contactId.String
+		t.ReversalOf = reversalOf.String
+		t.AutoReverseDate = nullMsToTime(autoReverseDate)
 		transactions = append(transactions, t)
 	}
 
@@ -485,12 +722,25 @@ func (db *DB) unmarshalSplits(rows *sql.Rows) ([]*types.Split, error) {
 		var date int64
 		var inserted int64
 		var updated int64
+		var externalId sql.NullString
+		var quantity sql.NullFloat64
+		var unitPrice sql.NullFloat64
 		var deleted bool
-		err := rows.Scan(&id, &s.TransactionId, &s.AccountId, &date, &inserted, &updated, &s.Amount, &s.NativeAmount, &deleted)
+		err := rows.Scan(&id, &s.TransactionId, &s.AccountId, &date, &inserted, &updated, &s.Amount, &s.NativeAmount, &externalId, &s.Status, &s.Memo, &quantity, &unitPrice, &deleted)
 		if err != nil {
 			return nil, err
 		}
 
+		s.ExternalId = externalId.String
+
+		if quantity.Valid {
+			s.Quantity = &quantity.Float64
+		}
+
+		if unitPrice.Valid {
+			s.UnitPrice = &unitPrice.Float64
+		}
+
 		splits = append(splits, s)
 	}
 
@@ -536,6 +786,14 @@ func (db *DB) addOptionsToQuery(query string, options *types.QueryOptions) strin
 		query += " AND t.description LIKE '" + db.Escape(options.DescriptionStartsWith) + "%'"
 	}
 
+	if options.ContactId != "" {
+		query += " AND t.contactId = UNHEX('" + db.Escape(options.ContactId) + "')"
+	}
+
+	for _, tag := range options.Tags {
+		query += " AND EXISTS (SELECT 1 FROM transactiontag tt WHERE tt.transactionId = s.transactionId AND tt.tag = '" + db.Escape(tag) + "')"
+	}
+
 	if options.Sort == "updated-asc" {
 		query += " ORDER BY s.updated ASC"
 	} else {
@@ -560,3 +818,128 @@ func (db *DB) addSortToQuery(query string, options *types.QueryOptions) string {
 
 	return query
 }
+
+// insertTransaction saves transaction and its splits as part of dbTx
+func insertTransaction(dbTx *sql.Tx, transaction *types.Transaction) error {
+	// save tx
+	query1 := "INSERT INTO transaction(id,orgId,userId,date,inserted,updated,description,contactId,data,reversalOf,autoReverseDate) VALUES(UNHEX(?),UNHEX(?),UNHEX(?),?,?,?,?,UNHEX(?),?,UNHEX(?),?)"
+
+	var contactId sql.NullString
+
+	if transaction.ContactId != "" {
+		contactId.String = transaction.ContactId
+		contactId.Valid = true
+	}
+
+	var reversalOf sql.NullString
+
+	if transaction.ReversalOf != "" {
+		reversalOf.String = transaction.ReversalOf
+		reversalOf.Valid = true
+	}
+
+	_, err := dbTx.Exec(
+		query1,
+		transaction.Id,
+		transaction.OrgId,
+		transaction.UserId,
+		util.TimeToMs(transaction.Date),
+		util.TimeToMs(transaction.Inserted),
+		util.TimeToMs(transaction.Updated),
+		transaction.Description,
+		contactId,
+		transaction.Data,
+		reversalOf,
+		timeToNullMs(transaction.AutoReverseDate),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// save splits along with their position in the transaction
+	for i, split := range transaction.Splits {
+		query := "INSERT INTO split(transactionId,accountId,date,inserted,updated,amount,nativeAmount,externalId,status,memo,quantity,unitPrice,position) VALUES (UNHEX(?),UNHEX(?),?,?,?,?,?,?,?,?,?,?,?)"
+
+		var externalId sql.NullString
+
+		if split.ExternalId != "" {
+			externalId.String = split.ExternalId
+			externalId.Valid = true
+		}
+
+		_, err = dbTx.Exec(
+			query,
+			transaction.Id,
+			split.AccountId,
+			util.TimeToMs(transaction.Date),
+			util.TimeToMs(transaction.Inserted),
+			util.TimeToMs(transaction.Updated),
+			split.Amount,
+			split.NativeAmount,
+			externalId,
+			split.Status,
+			split.Memo,
+			float64ToNull(split.Quantity),
+			float64ToNull(split.UnitPrice),
+			i)
+
+		if err != nil {
+			return err
+		}
+
+		for dimensionId, valueId := range split.Dimensions {
+			query := "INSERT INTO splitdimension(transactionId,position,dimensionId,valueId) VALUES (UNHEX(?),?,UNHEX(?),UNHEX(?))"
+
+			_, err = dbTx.Exec(query, transaction.Id, i, dimensionId, valueId)
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, tag := range transaction.Tags {
+		_, err = dbTx.Exec("INSERT INTO transactiontag(transactionId,tag) VALUES (UNHEX(?),?)", transaction.Id, tag)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return invalidateBalances(dbTx, transaction.Id)
+}
+
+// deleteTransaction marks transaction id and its splits as deleted as part of
+// dbTx
+func deleteTransaction(dbTx *sql.Tx, id string, updatedTime int64) error {
+	// mark splits as deleted
+
+	query1 := "UPDATE split SET updated = ?, deleted = true WHERE transactionId = UNHEX(?)"
+
+	_, err := dbTx.Exec(
+		query1,
+		updatedTime,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// mark transaction as deleted
+
+	query2 := "UPDATE transaction SET updated = ?, deleted = true WHERE id = UNHEX(?)"
+
+	_, err = dbTx.Exec(
+		query2,
+		updatedTime,
+		id,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return invalidateBalances(dbTx, id)
+}
@@ -0,0 +1,384 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"time"
+)
+
+type DimensionInterface interface {
+	GetDimensions(string, string) ([]*types.Dimension, error)
+	GetDimension(string, string, string) (*types.Dimension, error)
+	CreateDimension(*types.Dimension, string) error
+	UpdateDimension(*types.Dimension, string) error
+	DeleteDimension(string, string, string) error
+}
+
+// dimensionFilter maps a dimension id to the values a split may have for it.
+// An empty value matches splits without a value for the dimension. A split
+// must match every dimension in the filter.
+type dimensionFilter map[string][]string
+
+func (model *Model) GetDimensions(orgId string, userId string) ([]*types.Dimension, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	return model.db.GetDimensions(orgId)
+}
+
+func (model *Model) GetDimension(orgId string, id string, userId string) (*types.Dimension, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	dimension, err := model.db.GetDimension(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if dimension.OrgId != orgId {
+		return nil, errors.New("Dimension not found")
+	}
+
+	return dimension, nil
+}
+
+func (model *Model) CreateDimension(dimension *types.Dimension, userId string) error {
+	err := model.checkDimension(dimension, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertDimension(dimension)
+}
+
+// UpdateDimension renames a dimension and replaces its values. Values that are
+// used by splits cannot be removed but can be made inactive.
+func (model *Model) UpdateDimension(dimension *types.Dimension, userId string) error {
+	// GetDimension checks that the dimension belongs to the org
+	original, err := model.GetDimension(dimension.OrgId, dimension.Id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkDimension(dimension, userId)
+
+	if err != nil {
+		return err
+	}
+
+	used, err := model.db.GetUsedDimensionValueIds(dimension.Id)
+
+	if err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool)
+
+	for _, value := range dimension.Values {
+		kept[value.Id] = true
+	}
+
+	for _, valueId := range used {
+		if kept[valueId] == false {
+			return errors.New("Cannot remove a dimension value that is used by splits")
+		}
+	}
+
+	dimension.Inserted = original.Inserted
+
+	return model.db.UpdateDimension(dimension)
+}
+
+func (model *Model) DeleteDimension(orgId string, id string, userId string) error {
+	_, err := model.GetDimension(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	used, err := model.db.GetUsedDimensionValueIds(id)
+
+	if err != nil {
+		return err
+	}
+
+	if len(used) > 0 {
+		return errors.New("Cannot delete a dimension that is used by splits")
+	}
+
+	return model.db.DeleteDimension(id)
+}
+
+func (model *Model) checkDimension(dimension *types.Dimension, userId string) error {
+	if dimension.Id == "" {
+		return errors.New("id required")
+	}
+
+	if dimension.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if dimension.Name == "" {
+		return errors.New("name required")
+	}
+
+	ids := make(map[string]bool)
+
+	for _, value := range dimension.Values {
+		if value.Id == "" {
+			return errors.New("value id required")
+		}
+
+		if value.Name == "" {
+			return errors.New("value name required")
+		}
+
+		if ids[value.Id] == true {
+			return errors.New("duplicate value id " + value.Id)
+		}
+
+		ids[value.Id] = true
+		value.DimensionId = dimension.Id
+	}
+
+	belongs, err := model.UserBelongsToOrg(userId, dimension.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	if belongs == false {
+		return errors.New("User does not belong to org")
+	}
+
+	return nil
+}
+
+// checkSplitDimensions makes sure that every dimension value set on a split is
+// an active value of that dimension and that the dimension belongs to the org
+// of the transaction. Inactive values are only allowed if original, the
+// transaction being edited or reversed, already uses them. values are the
+// dimension values of the org if they are already loaded, otherwise nil and
+// they are loaded when needed. Empty values are removed.
+func (model *Model) checkSplitDimensions(transaction *types.Transaction, original *types.Transaction, values map[string]map[string]*types.DimensionValue) error {
+	for _, split := range transaction.Splits {
+		for dimensionId, valueId := range split.Dimensions {
+			if valueId == "" {
+				delete(split.Dimensions, dimensionId)
+				continue
+			}
+
+			if values == nil {
+				dimensions, err := model.db.GetDimensions(transaction.OrgId)
+
+				if err != nil {
+					return err
+				}
+
+				values = getDimensionValues(dimensions)
+			}
+
+			if values[dimensionId] == nil {
+				return errors.New("Dimension not found")
+			}
+
+			value := values[dimensionId][valueId]
+
+			if value == nil {
+				return errors.New("Dimension value not found")
+			}
+
+			if value.Inactive == true && !transactionHasDimensionValue(original, dimensionId, valueId) {
+				return errors.New("Dimension value is inactive")
+			}
+		}
+	}
+
+	return nil
+}
+
+// getDimensionFilters turns the dimension options of a report into filters
+// for its balances. Without a pivot there is a single filter, which is empty
+// if options.DimensionValues is. With a pivot there is a filter for each value
+// of the pivot dimension followed by one for splits without a value, and the
+// pivot value of each filter is returned alongside it.
+func (model *Model) getDimensionFilters(orgId string, options *types.ReportOptions) ([]dimensionFilter, []string, error) {
+	filter := make(dimensionFilter)
+
+	if len(options.DimensionValues) == 0 && options.Pivot == "" {
+		return []dimensionFilter{filter}, nil, nil
+	}
+
+	dimensions, err := model.db.GetDimensions(orgId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dimensionIds := make(map[string]string)
+
+	for _, dimension := range dimensions {
+		for _, value := range dimension.Values {
+			dimensionIds[value.Id] = dimension.Id
+		}
+	}
+
+	// values of the same dimension are alternatives
+	for _, valueId := range options.DimensionValues {
+		dimensionId, ok := dimensionIds[valueId]
+
+		if !ok {
+			return nil, nil, errors.New("Dimension value not found")
+		}
+
+		filter[dimensionId] = append(filter[dimensionId], valueId)
+	}
+
+	if options.Pivot == "" {
+		return []dimensionFilter{filter}, nil, nil
+	}
+
+	var pivot *types.Dimension
+
+	for _, dimension := range dimensions {
+		if dimension.Id == options.Pivot {
+			pivot = dimension
+		}
+	}
+
+	if pivot == nil {
+		return nil, nil, errors.New("Dimension not found")
+	}
+
+	pivotValues := make([]string, 0, len(pivot.Values)+1)
+
+	if allowed, ok := filter[pivot.Id]; ok {
+		pivotValues = append(pivotValues, allowed...)
+	} else {
+		for _, value := range pivot.Values {
+			pivotValues = append(pivotValues, value.Id)
+		}
+
+		pivotValues = append(pivotValues, "")
+	}
+
+	filters := make([]dimensionFilter, len(pivotValues))
+
+	for i, valueId := range pivotValues {
+		filters[i] = make(dimensionFilter)
+
+		for dimensionId, valueIds := range filter {
+			filters[i][dimensionId] = valueIds
+		}
+
+		filters[i][pivot.Id] = []string{valueId}
+	}
+
+	return filters, pivotValues, nil
+}
+
+// getFilteredPeriodBalances is getPeriodBalances for only the splits matching
+// filter
+func (model *Model) getFilteredPeriodBalances(accounts []*types.Account, times []time.Time, filter dimensionFilter) (*periodBalances, error) {
+	if len(filter) == 0 {
+		return model.getPeriodBalances(accounts, times)
+	}
+
+	boundaries := getBoundaries(times)
+
+	balances, err := model.db.GetDimensionPeriodBalances(accounts, boundaries, filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &periodBalances{boundaries: boundaries, balances: balances}, nil
+}
+
+// filterTransactionSplits returns copies of transactions keeping only the
+// splits that match filter
+func filterTransactionSplits(transactions []*types.Transaction, filter dimensionFilter) []*types.Transaction {
+	if len(filter) == 0 {
+		return transactions
+	}
+
+	filtered := make([]*types.Transaction, len(transactions))
+
+	for i, transaction := range transactions {
+		transactionCopy := *transaction
+		transactionCopy.Splits = make([]*types.Split, 0, len(transaction.Splits))
+
+		for _, split := range transaction.Splits {
+			if splitMatchesFilter(split, filter) {
+				transactionCopy.Splits = append(transactionCopy.Splits, split)
+			}
+		}
+
+		filtered[i] = &transactionCopy
+	}
+
+	return filtered
+}
+
+func splitMatchesFilter(split *types.Split, filter dimensionFilter) bool {
+	for dimensionId, valueIds := range filter {
+		matches := false
+
+		for _, valueId := range valueIds {
+			if split.Dimensions[dimensionId] == valueId {
+				matches = true
+			}
+		}
+
+		if !matches {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getDimensionValues(dimensions []*types.Dimension) map[string]map[string]*types.DimensionValue {
+	values := make(map[string]map[string]*types.DimensionValue)
+
+	for _, dimension := range dimensions {
+		values[dimension.Id] = make(map[string]*types.DimensionValue)
+
+		for _, value := range dimension.Values {
+			values[dimension.Id][value.Id] = value
+		}
+	}
+
+	return values
+}
+
+func transactionHasDimensionValue(transaction *types.Transaction, dimensionId string, valueId string) bool {
+	if transaction == nil {
+		return false
+	}
+
+	for _, split := range transaction.Splits {
+		if split.Dimensions[dimensionId] == valueId {
+			return true
+		}
+	}
+
+	return false
+}
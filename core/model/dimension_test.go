@@ -0,0 +1,484 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdDimension struct {
+	*TdTransaction
+	dimensions []*types.Dimension
+	used       map[string][]string
+	saved      *types.Dimension
+	loads      int
+}
+
+func (td *TdDimension) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "2"}}, nil
+}
+
+func (td *TdDimension) GetDimensions(orgId string) ([]*types.Dimension, error) {
+	td.loads++
+
+	dimensions := make([]*types.Dimension, 0)
+
+	for _, dimension := range td.dimensions {
+		if dimension.OrgId == orgId {
+			dimensions = append(dimensions, dimension)
+		}
+	}
+
+	return dimensions, nil
+}
+
+func (td *TdDimension) GetDimension(id string) (*types.Dimension, error) {
+	for _, dimension := range td.dimensions {
+		if dimension.Id == id {
+			return dimension, nil
+		}
+	}
+
+	return nil, errors.New("Dimension not found")
+}
+
+func (td *TdDimension) InsertDimension(dimension *types.Dimension) error {
+	td.saved = dimension
+	return nil
+}
+
+func (td *TdDimension) UpdateDimension(dimension *types.Dimension) error {
+	td.saved = dimension
+	return nil
+}
+
+func (td *TdDimension) GetUsedDimensionValueIds(id string) ([]string, error) {
+	return td.used[id], nil
+}
+
+func getDimensionTestDimensions() []*types.Dimension {
+	return []*types.Dimension{
+		&types.Dimension{Id: "d", OrgId: "2", Name: "Department", Values: []*types.DimensionValue{
+			&types.DimensionValue{Id: "s", DimensionId: "d", Name: "Sales"},
+			&types.DimensionValue{Id: "u", DimensionId: "d", Name: "Support"},
+		}},
+		&types.Dimension{Id: "p", OrgId: "2", Name: "Project", Values: []*types.DimensionValue{
+			&types.DimensionValue{Id: "x", DimensionId: "p", Name: "Expansion"},
+			&types.DimensionValue{Id: "z", DimensionId: "p", Name: "Pilot", Inactive: true},
+		}},
+		&types.Dimension{Id: "o", OrgId: "3", Name: "Other Org", Values: []*types.DimensionValue{
+			&types.DimensionValue{Id: "y", DimensionId: "o", Name: "Other"},
+		}},
+	}
+}
+
+func newTdDimension() *TdDimension {
+	return &TdDimension{
+		TdTransaction: &TdTransaction{},
+		dimensions:    getDimensionTestDimensions(),
+		used:          map[string][]string{"d": []string{"s"}},
+	}
+}
+
+func TestCreateDimension(t *testing.T) {
+	tests := map[string]struct {
+		err       error
+		dimension *types.Dimension
+	}{
+		"successful": {
+			err: nil,
+			dimension: &types.Dimension{Id: "c", OrgId: "2", Name: "Class", Values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "r", Name: "Retail"},
+			}},
+		},
+		"no name": {
+			err:       errors.New("name required"),
+			dimension: &types.Dimension{Id: "c", OrgId: "2"},
+		},
+		"no value name": {
+			err: errors.New("value name required"),
+			dimension: &types.Dimension{Id: "c", OrgId: "2", Name: "Class", Values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "r"},
+			}},
+		},
+		"duplicate value": {
+			err: errors.New("duplicate value id r"),
+			dimension: &types.Dimension{Id: "c", OrgId: "2", Name: "Class", Values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "r", Name: "Retail"},
+				&types.DimensionValue{Id: "r", Name: "Wholesale"},
+			}},
+		},
+		"other org": {
+			err:       errors.New("User does not belong to org"),
+			dimension: &types.Dimension{Id: "c", OrgId: "3", Name: "Class"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdDimension()
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateDimension(test.dimension, "3")
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.dimension, td.saved)
+			assert.Equal(t, "c", td.saved.Values[0].DimensionId)
+		} else {
+			assert.Nil(t, td.saved)
+		}
+	}
+}
+
+func TestUpdateDimension(t *testing.T) {
+	tests := map[string]struct {
+		err    error
+		values []*types.DimensionValue
+	}{
+		"rename and add": {
+			err: nil,
+			values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "s", Name: "Sales & Marketing"},
+				&types.DimensionValue{Id: "u", Name: "Support"},
+				&types.DimensionValue{Id: "e", Name: "Engineering"},
+			},
+		},
+		"remove unused": {
+			err: nil,
+			values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "s", Name: "Sales"},
+			},
+		},
+		"deactivate used": {
+			err: nil,
+			values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "s", Name: "Sales", Inactive: true},
+			},
+		},
+		"remove used": {
+			err: errors.New("Cannot remove a dimension value that is used by splits"),
+			values: []*types.DimensionValue{
+				&types.DimensionValue{Id: "u", Name: "Support"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdDimension()
+		model := NewModel(td, nil, types.Config{})
+
+		dimension := &types.Dimension{Id: "d", OrgId: "2", Name: "Department", Values: test.values}
+
+		err := model.UpdateDimension(dimension, "3")
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, dimension, td.saved)
+		} else {
+			assert.Nil(t, td.saved)
+		}
+	}
+}
+
+func TestCreateTransactionDimensions(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		dimensions map[string]string
+		want       map[string]string
+	}{
+		"no dimensions": {
+			err:        nil,
+			dimensions: nil,
+			want:       nil,
+		},
+		"dimensions": {
+			err:        nil,
+			dimensions: map[string]string{"d": "s", "p": "x"},
+			want:       map[string]string{"d": "s", "p": "x"},
+		},
+		"empty value": {
+			err:        nil,
+			dimensions: map[string]string{"d": "s", "p": ""},
+			want:       map[string]string{"d": "s"},
+		},
+		"value of another dimension": {
+			err:        errors.New("Dimension value not found"),
+			dimensions: map[string]string{"d": "x"},
+		},
+		"other org": {
+			err:        errors.New("Dimension not found"),
+			dimensions: map[string]string{"o": "y"},
+		},
+		"inactive value": {
+			err:        errors.New("Dimension value is inactive"),
+			dimensions: map[string]string{"d": "s", "p": "z"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdDimension()
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:     "1",
+			OrgId:  "2",
+			UserId: "3",
+			Date:   time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000, Dimensions: test.dimensions},
+			},
+		}
+
+		err := model.CreateTransaction(transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.want, transaction.Splits[1].Dimensions)
+		} else {
+			assert.Equal(t, 0, len(td.inserted))
+		}
+	}
+}
+
+func TestUpdateTransactionDimensions(t *testing.T) {
+	tests := map[string]struct {
+		err      error
+		original map[string]string
+	}{
+		"inactive value kept": {
+			err:      nil,
+			original: map[string]string{"p": "z"},
+		},
+		"inactive value added": {
+			err:      errors.New("Dimension value is inactive"),
+			original: map[string]string{"p": "x"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdDimension()
+		model := NewModel(td, nil, types.Config{})
+
+		original := &types.Transaction{
+			Id:    "1",
+			OrgId: "2",
+			Date:  time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000, Dimensions: test.original},
+			},
+		}
+
+		td.On("GetTransactionById", "1").Return(original, nil)
+		td.On("GetReversals", "1").Return([]*types.Transaction{}, nil)
+
+		transaction := &types.Transaction{
+			Id:     "2",
+			OrgId:  "2",
+			UserId: "3",
+			Date:   time.Date(2018, time.February, 16, 0, 0, 0, 0, time.UTC),
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1200, NativeAmount: 1200},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -1200, NativeAmount: -1200, Dimensions: map[string]string{"p": "z"}},
+			},
+		}
+
+		err := model.UpdateTransaction("1", transaction)
+
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestCreateTransactionsDimensions(t *testing.T) {
+	transaction := func(id string, dimensions map[string]string) *types.Transaction {
+		return &types.Transaction{
+			Id:   id,
+			Date: time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			Splits: []*types.Split{
+				&types.Split{TransactionId: id, AccountId: "1", Amount: 1000, NativeAmount: 1000, Dimensions: dimensions},
+				&types.Split{TransactionId: id, AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		}
+	}
+
+	td := newTdDimension()
+	model := NewModel(td, nil, types.Config{})
+
+	transactionErrors, err := model.CreateTransactions("2", "3", []*types.Transaction{
+		transaction("1", map[string]string{"d": "s"}),
+		transaction("2", map[string]string{"p": "z"}),
+		transaction("3", map[string]string{"d": "u", "p": "x"}),
+		transaction("4", map[string]string{"o": "y"}),
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.TransactionError{
+		&types.TransactionError{Index: 1, Id: "2", Error: "Dimension value is inactive"},
+		&types.TransactionError{Index: 3, Id: "4", Error: "Dimension not found"},
+	}, transactionErrors)
+
+	// dimensions are loaded once for the whole batch
+	assert.Equal(t, 1, td.loads)
+}
+
+type TdDimensionReport struct {
+	*TdReport
+	splits []*dimensionTestSplit
+}
+
+type dimensionTestSplit struct {
+	accountId string
+	date      time.Time
+	amount    int64
+	values    map[string]string
+}
+
+func (td *TdDimensionReport) GetDimensions(orgId string) ([]*types.Dimension, error) {
+	return getDimensionTestDimensions()[:2], nil
+}
+
+func (td *TdDimensionReport) GetPeriodBalances(accounts []*types.Account, boundaries []time.Time) (map[string][]*types.PeriodBalance, error) {
+	return td.GetDimensionPeriodBalances(accounts, boundaries, nil)
+}
+
+func (td *TdDimensionReport) GetDimensionPeriodBalances(accounts []*types.Account, boundaries []time.Time, filter map[string][]string) (map[string][]*types.PeriodBalance, error) {
+	report := &TdReport{}
+
+	for _, split := range td.splits {
+		if splitMatchesFilter(&types.Split{Dimensions: split.values}, filter) {
+			report.splits = append(report.splits, &reportTestSplit{split.accountId, split.date, split.amount})
+		}
+	}
+
+	return report.GetPeriodBalances(accounts, boundaries)
+}
+
+func newTdDimensionReport() *TdDimensionReport {
+	location, _ := time.LoadLocation("America/New_York")
+	date := time.Date(2018, time.March, 15, 0, 0, 0, 0, location)
+
+	return &TdDimensionReport{
+		TdReport: &TdReport{permissioned: []string{"1"}},
+		splits: []*dimensionTestSplit{
+			&dimensionTestSplit{"7", date, 2000, nil},
+			&dimensionTestSplit{"10", date, -2000, map[string]string{"d": "s", "p": "x"}},
+			&dimensionTestSplit{"7", date, 1000, nil},
+			&dimensionTestSplit{"10", date, -1000, map[string]string{"d": "u"}},
+			&dimensionTestSplit{"9", date, -800, nil},
+			&dimensionTestSplit{"11", date, 500, map[string]string{"d": "u"}},
+			&dimensionTestSplit{"11", date, 300, nil},
+		},
+	}
+}
+
+func TestGetIncomeStatementDimensions(t *testing.T) {
+	tests := map[string]struct {
+		err         error
+		options     *types.ReportOptions
+		pivotValues []string
+		income      []int64
+		expenses    []int64
+		netIncome   []int64
+	}{
+		"no filter": {
+			options:   &types.ReportOptions{},
+			income:    []int64{3000},
+			expenses:  []int64{800},
+			netIncome: []int64{2200},
+		},
+		"filter": {
+			options:   &types.ReportOptions{DimensionValues: []string{"u"}},
+			income:    []int64{1000},
+			expenses:  []int64{500},
+			netIncome: []int64{500},
+		},
+		"filter on two dimensions": {
+			options:   &types.ReportOptions{DimensionValues: []string{"u", "x"}},
+			income:    []int64{0},
+			expenses:  []int64{0},
+			netIncome: []int64{0},
+		},
+		"filter on two values": {
+			options:   &types.ReportOptions{DimensionValues: []string{"s", "u"}},
+			income:    []int64{3000},
+			expenses:  []int64{500},
+			netIncome: []int64{2500},
+		},
+		"pivot": {
+			options:     &types.ReportOptions{Pivot: "d"},
+			pivotValues: []string{"s", "u", ""},
+			income:      []int64{2000, 1000, 0},
+			expenses:    []int64{0, 500, 300},
+			netIncome:   []int64{2000, 500, -300},
+		},
+		"pivot with filter": {
+			options:     &types.ReportOptions{Pivot: "d", DimensionValues: []string{"u"}},
+			pivotValues: []string{"u"},
+			income:      []int64{1000},
+			expenses:    []int64{500},
+			netIncome:   []int64{500},
+		},
+		"unknown value": {
+			err:     errors.New("Dimension value not found"),
+			options: &types.ReportOptions{DimensionValues: []string{"y"}},
+		},
+		"unknown pivot": {
+			err:     errors.New("Dimension not found"),
+			options: &types.ReportOptions{Pivot: "o"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdDimensionReport()
+		model := NewModel(td, nil, types.Config{})
+
+		test.options.Start = "2018-01-01"
+		test.options.End = "2018-12-31"
+
+		incomeStatement, err := model.GetIncomeStatement("1", "1", test.options)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.pivotValues, incomeStatement.PivotValues)
+			assert.Equal(t, len(test.income), len(incomeStatement.Periods))
+			assert.Equal(t, test.income, incomeStatement.Income.Totals)
+			assert.Equal(t, test.expenses, incomeStatement.Expenses.Totals)
+			assert.Equal(t, test.netIncome, incomeStatement.NetIncome)
+		}
+	}
+}
+
+func TestGetBalanceSheetPivot(t *testing.T) {
+	td := newTdDimensionReport()
+	model := NewModel(td, nil, types.Config{})
+
+	balanceSheet, err := model.GetBalanceSheet("1", "1", &types.ReportOptions{
+		Periods: []*types.DateRange{&types.DateRange{End: "2018-06-30"}, &types.DateRange{End: "2018-12-31"}},
+		Pivot:   "d",
+	}, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"s", "s", "u", "u", "", ""}, balanceSheet.PivotValues)
+	assert.Equal(t, 6, len(balanceSheet.Dates))
+	assert.True(t, balanceSheet.Dates[0].Equal(balanceSheet.Dates[2]))
+	assert.Equal(t, []int64{0, 0, 0, 0, 3000, 3000}, balanceSheet.Assets.Totals)
+	assert.Equal(t, []int64{0, 0, 0, 0, 800, 800}, balanceSheet.Liabilities.Totals)
+	assert.Equal(t, []int64{2000, 2000, 500, 500, -300, -300}, balanceSheet.Equity.Totals)
+}
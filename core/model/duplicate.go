@@ -0,0 +1,130 @@
+package model
+
+import (
+	"github.com/openaccounting/oa-server/core/model/types"
+	"strings"
+	"unicode"
+)
+
+// duplicateDays is how many days apart a transaction and a possible duplicate
+// of it can be
+const duplicateDays = 3
+
+// getPossibleDuplicates returns the ids of existing transactions that look
+// like transaction. They must be dated within duplicateDays of it and have a
+// similar description. If accountId is empty they must have the same amounts
+// in the same accounts, otherwise only the split for accountId has to match.
+func (model *Model) getPossibleDuplicates(transaction *types.Transaction, accountId string) ([]string, error) {
+	splits := transaction.Splits
+
+	if accountId != "" {
+		splits = make([]*types.Split, 0)
+
+		for _, split := range transaction.Splits {
+			if split.AccountId == accountId {
+				splits = append(splits, split)
+			}
+		}
+	}
+
+	if len(splits) == 0 {
+		return nil, nil
+	}
+
+	start := transaction.Date.AddDate(0, 0, -duplicateDays)
+	end := transaction.Date.AddDate(0, 0, duplicateDays)
+
+	candidates, err := model.db.GetTransactionsWithSplit(splits[0].AccountId, splits[0].Amount, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+
+	for _, candidate := range candidates {
+		if candidate.Id == transaction.Id {
+			continue
+		}
+
+		if accountId == "" && len(candidate.Splits) != len(splits) {
+			continue
+		}
+
+		if splitsMatch(splits, candidate.Splits) && descriptionsMatch(transaction.Description, candidate.Description) {
+			ids = append(ids, candidate.Id)
+		}
+	}
+
+	return ids, nil
+}
+
+// splitsMatch returns true if every split in splits has its own split in
+// candidates with the same account and amount
+func splitsMatch(splits []*types.Split, candidates []*types.Split) bool {
+	used := make([]bool, len(candidates))
+
+	for _, split := range splits {
+		found := false
+
+		for i, candidate := range candidates {
+			if !used[i] && candidate.AccountId == split.AccountId && candidate.Amount == split.Amount {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// descriptionsMatch returns true if one description is blank or contains the
+// other, or if at least half of the words in the shorter one are also in the
+// longer one. Case and punctuation are ignored so that "POS 1234 CORNER
+// STORE" matches "Corner store".
+func descriptionsMatch(a string, b string) bool {
+	wordsA := getDescriptionWords(a)
+	wordsB := getDescriptionWords(b)
+
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return true
+	}
+
+	joinedA := strings.Join(wordsA, " ")
+	joinedB := strings.Join(wordsB, " ")
+
+	if strings.Contains(joinedA, joinedB) || strings.Contains(joinedB, joinedA) {
+		return true
+	}
+
+	if len(wordsA) > len(wordsB) {
+		wordsA, wordsB = wordsB, wordsA
+	}
+
+	longer := make(map[string]bool)
+
+	for _, word := range wordsB {
+		longer[word] = true
+	}
+
+	common := 0
+
+	for _, word := range wordsA {
+		if longer[word] {
+			common++
+		}
+	}
+
+	return common*2 >= len(wordsA)
+}
+
+func getDescriptionWords(description string) []string {
+	return strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
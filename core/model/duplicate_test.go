@@ -0,0 +1,99 @@
+package model
+
+import (
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCreateTransactionPossibleDuplicates(t *testing.T) {
+	existing := &types.Transaction{
+		Id:          "5",
+		Date:        time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+		Description: "POS 1234 CORNER STORE",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "5", AccountId: "1", Amount: -1250, NativeAmount: -1250},
+			&types.Split{TransactionId: "5", AccountId: "2", Amount: 1250, NativeAmount: 1250},
+		},
+	}
+
+	tests := map[string]struct {
+		date        time.Time
+		description string
+		amount      int64
+		duplicates  []string
+	}{
+		"duplicate": {
+			date:        time.Date(2018, time.February, 12, 0, 0, 0, 0, time.UTC),
+			description: "Corner store",
+			amount:      1250,
+			duplicates:  []string{"5"},
+		},
+		"too far apart": {
+			date:        time.Date(2018, time.February, 14, 0, 0, 0, 0, time.UTC),
+			description: "Corner store",
+			amount:      1250,
+			duplicates:  nil,
+		},
+		"different amount": {
+			date:        time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+			description: "Corner store",
+			amount:      1200,
+			duplicates:  nil,
+		},
+		"different description": {
+			date:        time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+			description: "Bakery",
+			amount:      1250,
+			duplicates:  nil,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{existing: []*types.Transaction{existing}}
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:          "6",
+			OrgId:       "2",
+			UserId:      "3",
+			Date:        test.date,
+			Description: test.description,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "6", AccountId: "1", Amount: -test.amount, NativeAmount: -test.amount},
+				&types.Split{TransactionId: "6", AccountId: "2", Amount: test.amount, NativeAmount: test.amount},
+			},
+		}
+
+		err := model.CreateTransaction(transaction)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test.duplicates, transaction.PossibleDuplicates)
+		assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+	}
+}
+
+func TestDescriptionsMatch(t *testing.T) {
+	tests := map[string]struct {
+		a     string
+		b     string
+		match bool
+	}{
+		"same":             {"Corner store", "corner store", true},
+		"contained":        {"POS 1234 CORNER STORE", "Corner store.", true},
+		"blank":            {"", "Corner store", true},
+		"half of words":    {"AMAZON MKTP US", "Amazon prime", true},
+		"different":        {"Corner store", "Bakery", false},
+		"one common word":  {"POS 1234 AMAZON", "POS 5678 WALMART", false},
+		"punctuation only": {"--", "Bakery", true},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		assert.Equal(t, test.match, descriptionsMatch(test.a, test.b))
+	}
+}
@@ -0,0 +1,399 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/importer"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"strings"
+	"time"
+)
+
+type ImportInterface interface {
+	GetCsvMapping(string, string, string) (*types.CsvMapping, error)
+	SaveCsvMapping(*types.CsvMapping, string) error
+	ImportCsv(string, string, string, *types.CsvImport) (*types.ImportResult, error)
+	ImportOfx(string, string, string, *types.StatementImport) (*types.ImportResult, error)
+	ImportQif(string, string, string, *types.StatementImport) (*types.ImportResult, error)
+}
+
+func (model *Model) GetCsvMapping(orgId string, accountId string, userId string) (*types.CsvMapping, error) {
+	_, _, err := model.getImportAccount(orgId, accountId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := model.db.GetCsvMapping(accountId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.OrgId != orgId {
+		return nil, errors.New("CSV mapping not found")
+	}
+
+	return mapping, nil
+}
+
+func (model *Model) SaveCsvMapping(mapping *types.CsvMapping, userId string) error {
+	_, _, err := model.getImportAccount(mapping.OrgId, mapping.AccountId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	err = importer.CheckCsvMapping(mapping)
+
+	if err != nil {
+		return err
+	}
+
+	return model.saveCsvMapping(mapping)
+}
+
+// ImportCsv turns the lines of a CSV bank statement into transactions between
+// account accountId and either the account named in the category column or
+// csvImport.OffsetAccountId. Lines that were already imported are skipped. The
+// transactions are only created if csvImport.Create is set, otherwise they are
+// returned as drafts.
+func (model *Model) ImportCsv(orgId string, accountId string, userId string, csvImport *types.CsvImport) (*types.ImportResult, error) {
+	org, account, err := model.getImportAccount(orgId, accountId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mapping := csvImport.Mapping
+
+	if mapping == nil {
+		mapping, err = model.GetCsvMapping(orgId, accountId, userId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mapping.OrgId = orgId
+	mapping.AccountId = accountId
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := importer.ParseCsv(csvImport.Data, mapping, account.Precision, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if csvImport.Mapping != nil && csvImport.SaveMapping {
+		err = model.saveCsvMapping(mapping)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return model.importEntries(org, account, userId, entries, csvImport.OffsetAccountId, csvImport.Create, csvImport.AllowDuplicates)
+}
+
+// ImportOfx is ImportCsv for OFX and QFX files. Transactions whose FITID was
+// already imported into the account are skipped.
+func (model *Model) ImportOfx(orgId string, accountId string, userId string, statementImport *types.StatementImport) (*types.ImportResult, error) {
+	org, account, err := model.getImportAccount(orgId, accountId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := importer.ParseOfx(statementImport.Data, account.Precision, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model.importEntries(org, account, userId, entries, statementImport.OffsetAccountId, statementImport.Create, statementImport.AllowDuplicates)
+}
+
+// ImportQif is ImportCsv for QIF files
+func (model *Model) ImportQif(orgId string, accountId string, userId string, statementImport *types.StatementImport) (*types.ImportResult, error) {
+	org, account, err := model.getImportAccount(orgId, accountId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := importer.ParseQif(statementImport.Data, statementImport.DateFormat, account.Precision, location)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model.importEntries(org, account, userId, entries, statementImport.OffsetAccountId, statementImport.Create, statementImport.AllowDuplicates)
+}
+
+// saveCsvMapping saves a checked mapping, keeping the time it was first saved
+func (model *Model) saveCsvMapping(mapping *types.CsvMapping) error {
+	original, err := model.db.GetCsvMapping(mapping.AccountId)
+
+	if err == nil {
+		mapping.Inserted = original.Inserted
+	} else {
+		mapping.Inserted = time.Time{}
+	}
+
+	return model.db.SaveCsvMapping(mapping)
+}
+
+// getImportAccount returns the org and the account statements are imported
+// into after checking that the user can write to it
+func (model *Model) getImportAccount(orgId string, accountId string, userId string) (*types.Org, *types.Account, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account, err := getImportSplitAccount(org, userAccounts, accountId)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return org, account, nil
+}
+
+// getImportSplitAccount returns the account for an imported split. Imported
+// amounts are in the org currency so they are also the native amounts.
+func getImportSplitAccount(org *types.Org, userAccounts []*types.Account, accountId string) (*types.Account, error) {
+	var account *types.Account
+
+	for _, userAccount := range userAccounts {
+		if userAccount.Id == accountId && !userAccount.ReadOnly {
+			account = userAccount
+		}
+	}
+
+	if account == nil {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", accountId))
+	}
+
+	if account.HasChildren == true {
+		return nil, errors.New("Cannot use parent account for split")
+	}
+
+	if account.Currency != org.Currency {
+		return nil, errors.New("imported transactions must use accounts in the org currency")
+	}
+
+	return account, nil
+}
+
+// importEntries builds a transaction for every entry that has not been
+// imported into account before and creates them if create is set. An entry is
+// balanced against the account of the first rule that matches it, otherwise
+// the account named by its category, otherwise offsetAccountId. If none of
+// these gives an account, the draft has a split without an account and an
+// error. Unless allowDuplicates is set, transactions that look like existing
+// ones are held back in result.PossibleDuplicates.
+func (model *Model) importEntries(org *types.Org, account *types.Account, userId string, entries []*importer.Entry, offsetAccountId string, create bool, allowDuplicates bool) (*types.ImportResult, error) {
+	userAccounts, err := model.GetAccounts(org.Id, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var offsetAccount *types.Account
+
+	if offsetAccountId != "" {
+		offsetAccount, err = getImportSplitAccount(org, userAccounts, offsetAccountId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if offsetAccount.Id == account.Id {
+			return nil, errors.New("offsetAccountId must be a different account")
+		}
+	}
+
+	rules, err := model.getCompiledRules(org.Id, userAccounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	externalIds := make([]string, len(entries))
+
+	for i, entry := range entries {
+		externalIds[i] = entry.ExternalId
+	}
+
+	imported, err := model.db.GetSplitExternalIds(account.Id, externalIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool)
+
+	for _, externalId := range imported {
+		skip[externalId] = true
+	}
+
+	result := &types.ImportResult{
+		Transactions:       make([]*types.Transaction, 0),
+		PossibleDuplicates: make([]*types.Transaction, 0),
+		Errors:             make([]*types.TransactionError, 0),
+	}
+
+	for _, entry := range entries {
+		if skip[entry.ExternalId] {
+			result.Skipped++
+			continue
+		}
+
+		skip[entry.ExternalId] = true
+
+		id, err := util.NewGuid()
+
+		if err != nil {
+			return nil, err
+		}
+
+		transaction := &types.Transaction{
+			Id:          id,
+			OrgId:       org.Id,
+			UserId:      userId,
+			Date:        entry.Date,
+			Description: entry.Description,
+			Splits: []*types.Split{
+				&types.Split{
+					TransactionId: id,
+					AccountId:     account.Id,
+					Amount:        entry.Amount,
+					NativeAmount:  entry.Amount,
+					ExternalId:    entry.ExternalId,
+				},
+				&types.Split{
+					TransactionId: id,
+					Amount:        -entry.Amount,
+					NativeAmount:  -entry.Amount,
+				},
+			},
+		}
+
+		rule := matchRule(rules, entry.Description, entry.Amount, account.Id)
+		category := getImportCategoryAccount(org, userAccounts, account, entry.Category)
+
+		// an existing transaction is unlikely to use the offset account, which
+		// is often a suspense account, so only the imported split is compared
+		duplicateAccountId := account.Id
+
+		switch {
+		case rule != nil:
+			transaction.Splits[1].AccountId = rule.AccountId
+			duplicateAccountId = ""
+
+			if rule.Payee != "" {
+				transaction.Description = rule.Payee
+			}
+		case category != nil:
+			transaction.Splits[1].AccountId = category.Id
+			duplicateAccountId = ""
+		case offsetAccount != nil:
+			transaction.Splits[1].AccountId = offsetAccount.Id
+		}
+
+		transaction.PossibleDuplicates, err = model.getPossibleDuplicates(transaction, duplicateAccountId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(transaction.PossibleDuplicates) > 0 && !allowDuplicates {
+			result.PossibleDuplicates = append(result.PossibleDuplicates, transaction)
+			continue
+		}
+
+		if transaction.Splits[1].AccountId == "" {
+			result.Errors = append(result.Errors, &types.TransactionError{
+				Index: len(result.Transactions),
+				Id:    id,
+				Error: getImportCategoryError(entry.Category),
+			})
+		}
+
+		result.Transactions = append(result.Transactions, transaction)
+	}
+
+	if !create || len(result.Transactions) == 0 || len(result.Errors) > 0 {
+		return result, nil
+	}
+
+	transactionErrors, err := model.CreateTransactions(org.Id, userId, result.Transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(transactionErrors) > 0 {
+		result.Errors = transactionErrors
+		return result, nil
+	}
+
+	result.Created = true
+
+	return result, nil
+}
+
+// getImportCategoryAccount returns the account whose name matches category
+// ignoring case, or nil if there is no usable account with that name
+func getImportCategoryAccount(org *types.Org, userAccounts []*types.Account, account *types.Account, category string) *types.Account {
+	if category == "" {
+		return nil
+	}
+
+	for _, userAccount := range userAccounts {
+		if userAccount.Id == account.Id || !strings.EqualFold(userAccount.Name, category) {
+			continue
+		}
+
+		if match, err := getImportSplitAccount(org, userAccounts, userAccount.Id); err == nil {
+			return match
+		}
+	}
+
+	return nil
+}
+
+func getImportCategoryError(category string) string {
+	if category == "" {
+		return "offsetAccountId required"
+	}
+
+	return fmt.Sprintf("no account matches category %s and offsetAccountId is not set", category)
+}
@@ -0,0 +1,469 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdImport struct {
+	*TdTransaction
+	externalIds []string
+	mappings    []*types.CsvMapping
+	rules       []*types.Rule
+}
+
+func (td *TdImport) GetOrg(orgId string, userId string) (*types.Org, error) {
+	return &types.Org{Id: "2", Currency: "USD", Timezone: "UTC"}, nil
+}
+
+func (td *TdImport) GetPermissionedAccountIds(userId string, orgId string, tokenId string) ([]string, error) {
+	return []string{"1", "2", "3", "4"}, nil
+}
+
+func (td *TdImport) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
+	return []*types.Account{
+		&types.Account{Id: "1", Name: "Checking", Currency: "USD", Precision: 2},
+		&types.Account{Id: "2", Name: "Groceries", Currency: "USD", Precision: 2},
+		&types.Account{Id: "3", Name: "Suspense", Currency: "USD", Precision: 2},
+		&types.Account{Id: "4", Name: "Travel", Currency: "EUR", Precision: 2},
+	}, nil
+}
+
+func (td *TdImport) GetSplitExternalIds(accountId string, externalIds []string) ([]string, error) {
+	found := make([]string, 0)
+
+	for _, externalId := range externalIds {
+		for _, imported := range td.externalIds {
+			if externalId == imported {
+				found = append(found, externalId)
+			}
+		}
+	}
+
+	return found, nil
+}
+
+func (td *TdImport) GetCsvMapping(accountId string) (*types.CsvMapping, error) {
+	for _, mapping := range td.mappings {
+		if mapping.AccountId == accountId {
+			return mapping, nil
+		}
+	}
+
+	return nil, errors.New("CSV mapping not found")
+}
+
+func (td *TdImport) SaveCsvMapping(mapping *types.CsvMapping) error {
+	td.mappings = append(td.mappings, mapping)
+	return nil
+}
+
+func (td *TdImport) GetRules(orgId string) ([]*types.Rule, error) {
+	return td.rules, nil
+}
+
+func TestImportCsv(t *testing.T) {
+	data := "Date,Description,Amount,Reference,Category\n" +
+		"03/02/2018,Corner store,\"(1,234.50)\",A1,groceries\n" +
+		"05/02/2018,Salary,\"2,000.00\",A2,\n" +
+		"06/02/2018,Hotel,-100,A3,Travel\n"
+
+	mapping := &types.CsvMapping{
+		HeaderRows:        1,
+		DateColumn:        1,
+		DateFormat:        "DD/MM/YYYY",
+		DescriptionColumn: 2,
+		AmountColumn:      3,
+		ReferenceColumn:   4,
+		CategoryColumn:    5,
+	}
+
+	tests := map[string]struct {
+		csvImport   *types.CsvImport
+		externalIds []string
+		offsets     []string
+		skipped     int
+		errors      []*types.TransactionError
+		err         error
+	}{
+		"categories and offset account": {
+			csvImport: &types.CsvImport{Data: data, Mapping: mapping, OffsetAccountId: "3"},
+			offsets:   []string{"2", "3", "3"},
+		},
+		"already imported": {
+			csvImport:   &types.CsvImport{Data: data, Mapping: mapping, OffsetAccountId: "3"},
+			externalIds: []string{"A1", "A3"},
+			offsets:     []string{"3"},
+			skipped:     2,
+		},
+		"no offset account": {
+			csvImport: &types.CsvImport{Data: data, Mapping: mapping},
+			offsets:   []string{"2", "", ""},
+			errors: []*types.TransactionError{
+				&types.TransactionError{Index: 1, Error: "offsetAccountId required"},
+				&types.TransactionError{Index: 2, Error: "no account matches category Travel and offsetAccountId is not set"},
+			},
+		},
+		"create": {
+			csvImport: &types.CsvImport{Data: data, Mapping: mapping, OffsetAccountId: "3", Create: true},
+			offsets:   []string{"2", "3", "3"},
+		},
+		"offset account in another currency": {
+			csvImport: &types.CsvImport{Data: data, Mapping: mapping, OffsetAccountId: "4"},
+			err:       errors.New("imported transactions must use accounts in the org currency"),
+		},
+		"no saved mapping": {
+			csvImport: &types.CsvImport{Data: data, OffsetAccountId: "3"},
+			err:       errors.New("CSV mapping not found"),
+		},
+		"bad date": {
+			csvImport: &types.CsvImport{Data: data + "2018-02-07,Refund,1.00,A4,\n", Mapping: mapping, OffsetAccountId: "3"},
+			err:       errors.New("row 5: date 2018-02-07 does not match format DD/MM/YYYY"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdImport{TdTransaction: &TdTransaction{}, externalIds: test.externalIds}
+		model := NewModel(td, nil, types.Config{})
+
+		result, err := model.ImportCsv("2", "1", "3", test.csvImport)
+
+		assert.Equal(t, test.err, err)
+
+		if err != nil {
+			continue
+		}
+
+		offsets := make([]string, len(result.Transactions))
+
+		for i, transaction := range result.Transactions {
+			assert.Equal(t, "1", transaction.Splits[0].AccountId)
+			assert.Equal(t, -transaction.Splits[0].Amount, transaction.Splits[1].Amount)
+			offsets[i] = transaction.Splits[1].AccountId
+		}
+
+		for i, transactionError := range test.errors {
+			transactionError.Id = result.Transactions[transactionError.Index].Id
+			assert.Equal(t, transactionError, result.Errors[i])
+		}
+
+		assert.Equal(t, test.offsets, offsets)
+		assert.Equal(t, test.skipped, result.Skipped)
+		assert.Equal(t, len(test.errors), len(result.Errors))
+		assert.Equal(t, test.csvImport.Create, result.Created)
+
+		if test.csvImport.Create {
+			assert.Equal(t, result.Transactions, td.inserted)
+		} else {
+			assert.Equal(t, 0, len(td.inserted))
+		}
+	}
+}
+
+func TestImportCsvPossibleDuplicates(t *testing.T) {
+	// entered by hand against the groceries account before the statement arrived
+	existing := &types.Transaction{
+		Id:          "5",
+		Date:        time.Date(2018, time.February, 2, 0, 0, 0, 0, time.UTC),
+		Description: "Corner store",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "5", AccountId: "1", Amount: -1250, NativeAmount: -1250},
+			&types.Split{TransactionId: "5", AccountId: "2", Amount: 1250, NativeAmount: 1250},
+		},
+	}
+
+	data := "2018-02-03,CORNER STORE #12,-12.50\n2018-02-04,Salary,2000\n"
+	mapping := &types.CsvMapping{DateColumn: 1, DescriptionColumn: 2, AmountColumn: 3}
+
+	td := &TdImport{TdTransaction: &TdTransaction{existing: []*types.Transaction{existing}}}
+	model := NewModel(td, nil, types.Config{})
+
+	result, err := model.ImportCsv("2", "1", "3", &types.CsvImport{
+		Data:            data,
+		Mapping:         mapping,
+		OffsetAccountId: "3",
+		Create:          true,
+	})
+
+	assert.Nil(t, err)
+	assert.True(t, result.Created)
+	assert.Equal(t, 1, len(result.Transactions))
+	assert.Equal(t, "Salary", result.Transactions[0].Description)
+	assert.Equal(t, 1, len(result.PossibleDuplicates))
+	assert.Equal(t, []string{"5"}, result.PossibleDuplicates[0].PossibleDuplicates)
+	assert.Equal(t, result.Transactions, td.inserted)
+
+	td = &TdImport{TdTransaction: &TdTransaction{existing: []*types.Transaction{existing}}}
+	model = NewModel(td, nil, types.Config{})
+
+	result, err = model.ImportCsv("2", "1", "3", &types.CsvImport{
+		Data:            data,
+		Mapping:         mapping,
+		OffsetAccountId: "3",
+		Create:          true,
+		AllowDuplicates: true,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(result.Transactions))
+	assert.Equal(t, 0, len(result.PossibleDuplicates))
+	assert.Equal(t, []string{"5"}, result.Transactions[0].PossibleDuplicates)
+}
+
+func TestImportCsvAmounts(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		mapping *types.CsvMapping
+		amounts []int64
+		err     error
+	}{
+		"inflow and outflow columns": {
+			data:    "2018-02-03;10,50;\n2018-02-04;;-1.200,00\n",
+			mapping: &types.CsvMapping{Delimiter: ";", DateColumn: 1, AmountColumn: 2, OutflowColumn: 3, DecimalSeparator: ","},
+			amounts: []int64{1050, -120000},
+		},
+		"positive amounts are outflows": {
+			data:    "2018-02-03,$25.99\n2018-02-04,-$5\n",
+			mapping: &types.CsvMapping{DateColumn: 1, AmountColumn: 2, AmountSign: "outflow"},
+			amounts: []int64{-2599, 500},
+		},
+		"too many decimal places": {
+			data:    "2018-02-03,1.005\n",
+			mapping: &types.CsvMapping{DateColumn: 1, AmountColumn: 2},
+			err:     errors.New("row 1: amount 1.005 has more than 2 decimal places"),
+		},
+		"missing column": {
+			data:    "2018-02-03\n",
+			mapping: &types.CsvMapping{DateColumn: 1, AmountColumn: 2},
+			err:     errors.New("row 1: column 2 not found"),
+		},
+		"missing amount column": {
+			data:    "2018-02-03,1.00\n",
+			mapping: &types.CsvMapping{DateColumn: 1},
+			err:     errors.New("amountColumn required"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdImport{TdTransaction: &TdTransaction{}}
+		model := NewModel(td, nil, types.Config{})
+
+		result, err := model.ImportCsv("2", "1", "3", &types.CsvImport{
+			Data:            test.data,
+			Mapping:         test.mapping,
+			OffsetAccountId: "3",
+		})
+
+		assert.Equal(t, test.err, err)
+
+		if err != nil {
+			continue
+		}
+
+		amounts := make([]int64, len(result.Transactions))
+
+		for i, transaction := range result.Transactions {
+			amounts[i] = transaction.Splits[0].Amount
+		}
+
+		assert.Equal(t, test.amounts, amounts)
+		assert.Equal(t, time.Date(2018, time.February, 3, 0, 0, 0, 0, time.UTC), result.Transactions[0].Date)
+	}
+}
+
+func TestSaveCsvMapping(t *testing.T) {
+	td := &TdImport{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	mapping := &types.CsvMapping{OrgId: "2", AccountId: "1", DateColumn: 1, AmountColumn: 2}
+
+	err := model.SaveCsvMapping(mapping, "3")
+
+	assert.Nil(t, err)
+	assert.Equal(t, ",", mapping.Delimiter)
+	assert.Equal(t, "YYYY-MM-DD", mapping.DateFormat)
+	assert.Equal(t, "inflow", mapping.AmountSign)
+	assert.Equal(t, ".", mapping.DecimalSeparator)
+
+	saved, err := model.GetCsvMapping("2", "1", "3")
+
+	assert.Nil(t, err)
+	assert.Equal(t, mapping, saved)
+
+	mapping = &types.CsvMapping{OrgId: "2", AccountId: "1", DateColumn: 1, AmountColumn: 2, AmountSign: "debit"}
+
+	err = model.SaveCsvMapping(mapping, "3")
+
+	assert.Equal(t, errors.New("amountSign must be inflow or outflow"), err)
+}
+
+func TestImportOfx(t *testing.T) {
+	sgml := "OFXHEADER:100\nDATA:OFXSGML\nVERSION:102\n\n<OFX>\n<BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>\n" +
+		"<STMTTRN>\n<TRNTYPE>DEBIT\n<DTPOSTED>20180203120000.000[-5:EST]\n<TRNAMT>-12.50\n<FITID>20180203001\n<NAME>Corner store &amp; deli\n</STMTTRN>\n" +
+		"<STMTTRN>\n<TRNTYPE>CREDIT\n<DTPOSTED>20180205\n<TRNAMT>2000\n<FITID>20180205001\n<MEMO>Salary\n</STMTTRN>\n" +
+		"</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1>\n</OFX>\n"
+
+	xml := "<?xml version=\"1.0\"?>\n<?OFX OFXHEADER=\"200\" VERSION=\"211\"?>\n<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><BANKTRANLIST>" +
+		"<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20180203</DTPOSTED><TRNAMT>-12.50</TRNAMT><FITID>20180203001</FITID><NAME>Corner store &amp; deli</NAME></STMTTRN>" +
+		"<STMTTRN><TRNTYPE>CREDIT</TRNTYPE><DTPOSTED>20180205</DTPOSTED><TRNAMT>2000.00</TRNAMT><FITID>20180205001</FITID><MEMO>Salary</MEMO></STMTTRN>" +
+		"</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>"
+
+	tests := map[string]struct {
+		data         string
+		externalIds  []string
+		descriptions []string
+		amounts      []int64
+		err          error
+	}{
+		"sgml": {
+			data:         sgml,
+			descriptions: []string{"Corner store & deli", "Salary"},
+			amounts:      []int64{-1250, 200000},
+		},
+		"xml": {
+			data:         xml,
+			descriptions: []string{"Corner store & deli", "Salary"},
+			amounts:      []int64{-1250, 200000},
+		},
+		"already imported": {
+			data:         sgml,
+			externalIds:  []string{"20180203001"},
+			descriptions: []string{"Salary"},
+			amounts:      []int64{200000},
+		},
+		"bad date": {
+			data: "<OFX><STMTTRN><DTPOSTED>2018<TRNAMT>1.00</STMTTRN></OFX>",
+			err:  errors.New("transaction 1: invalid DTPOSTED 2018"),
+		},
+		"no transactions": {
+			data: "<OFX></OFX>",
+			err:  errors.New("no entries found"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdImport{TdTransaction: &TdTransaction{}, externalIds: test.externalIds}
+		model := NewModel(td, nil, types.Config{})
+
+		result, err := model.ImportOfx("2", "1", "3", &types.StatementImport{
+			Data:            test.data,
+			OffsetAccountId: "3",
+			Create:          true,
+		})
+
+		assert.Equal(t, test.err, err)
+
+		if err != nil {
+			continue
+		}
+
+		descriptions := make([]string, len(result.Transactions))
+		amounts := make([]int64, len(result.Transactions))
+
+		for i, transaction := range result.Transactions {
+			descriptions[i] = transaction.Description
+			amounts[i] = transaction.Splits[0].Amount
+		}
+
+		assert.Equal(t, test.descriptions, descriptions)
+		assert.Equal(t, test.amounts, amounts)
+		assert.Equal(t, len(test.externalIds), result.Skipped)
+		assert.Equal(t, "20180205001", result.Transactions[len(result.Transactions)-1].Splits[0].ExternalId)
+		assert.Equal(t, time.Date(2018, time.February, 5, 0, 0, 0, 0, time.UTC), result.Transactions[len(result.Transactions)-1].Date)
+		assert.True(t, result.Created)
+		assert.Equal(t, result.Transactions, td.inserted)
+	}
+}
+
+func TestImportQif(t *testing.T) {
+	data := "!Account\nNChecking\n^\n!Type:Cat\nNGroceries\n^\n!Type:Bank\n" +
+		"D2/3'18\nT-12.50\nPCorner store\nLFood:Groceries\n^\n" +
+		"D2/3'18\nT-12.50\nPCorner store\nLFood:Groceries\nSFood:Groceries\n$-12.50\n^\n" +
+		"D02/05/2018\nT2,000.00\nMSalary\n^\n"
+
+	tests := map[string]struct {
+		data       string
+		dateFormat string
+		offsets    []string
+		amounts    []int64
+		err        error
+	}{
+		"categories": {
+			data:    data,
+			offsets: []string{"2", "2", "3"},
+			amounts: []int64{-1250, -1250, 200000},
+		},
+		"date format": {
+			data:       "!Type:CCard\nD03/02/2018\nU-5.00\nL[Travel]\n^\n",
+			dateFormat: "DD/MM/YYYY",
+			offsets:    []string{"3"},
+			amounts:    []int64{-500},
+		},
+		"bad amount": {
+			data: "!Type:Bank\nD2/3/2018\nTtwelve\n^\n",
+			err:  errors.New("line 3: invalid amount twelve"),
+		},
+		"only categories": {
+			data: "!Type:Cat\nNGroceries\n^\n",
+			err:  errors.New("no entries found"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdImport{TdTransaction: &TdTransaction{}}
+		model := NewModel(td, nil, types.Config{})
+
+		result, err := model.ImportQif("2", "1", "3", &types.StatementImport{
+			Data:            test.data,
+			DateFormat:      test.dateFormat,
+			OffsetAccountId: "3",
+		})
+
+		assert.Equal(t, test.err, err)
+
+		if err != nil {
+			continue
+		}
+
+		offsets := make([]string, len(result.Transactions))
+		amounts := make([]int64, len(result.Transactions))
+
+		for i, transaction := range result.Transactions {
+			offsets[i] = transaction.Splits[1].AccountId
+			amounts[i] = transaction.Splits[0].Amount
+		}
+
+		assert.Equal(t, test.offsets, offsets)
+		assert.Equal(t, test.amounts, amounts)
+		assert.Equal(t, time.Date(2018, time.February, 3, 0, 0, 0, 0, time.UTC), result.Transactions[0].Date)
+	}
+
+	// identical transactions get different ids that stay the same on the next import
+	td := &TdImport{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	first, err := model.ImportQif("2", "1", "3", &types.StatementImport{Data: data, OffsetAccountId: "3"})
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, first.Transactions[0].Splits[0].ExternalId, first.Transactions[1].Splits[0].ExternalId)
+
+	td.externalIds = []string{first.Transactions[0].Splits[0].ExternalId, first.Transactions[1].Splits[0].ExternalId}
+
+	second, err := model.ImportQif("2", "1", "3", &types.StatementImport{Data: data, OffsetAccountId: "3"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, second.Skipped)
+	assert.Equal(t, 1, len(second.Transactions))
+	assert.Equal(t, "Salary", second.Transactions[0].Description)
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+)
+
+type LedgerInterface interface {
+	GetLedger(string, string, string, *types.ReportOptions, *types.QueryOptions) (*types.Ledger, error)
+}
+
+// GetLedger lists every split on an account and its descendants from start up
+// to end along with a running balance after each line. Pages selected with
+// skip and limit start from the balance of everything before them.
+//
+// Balances are in the account's currency and only include descendants sharing
+// that currency. Native balances include every descendant.
+func (model *Model) GetLedger(orgId string, userId string, accountId string, reportOptions *types.ReportOptions, queryOptions *types.QueryOptions) (*types.Ledger, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, accountId) {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", accountId))
+	}
+
+	if queryOptions.Skip < 0 || queryOptions.Limit < 0 {
+		return nil, errors.New("skip and limit must not be negative")
+	}
+
+	// TODO how do we make dates optional parameters
+	// instead of resorting to this hack?
+	start := time.Time{}
+	end := time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	if reportOptions.Start != "" || reportOptions.End != "" {
+		location, err := time.LoadLocation(org.Timezone)
+
+		if err != nil {
+			return nil, err
+		}
+
+		period, err := getReportPeriod(&types.DateRange{Start: reportOptions.Start, End: reportOptions.End}, location, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		start = period.Start
+		end = period.End
+	}
+
+	accountMap := model.makeAccountMap(userAccounts)
+	account := accountMap[accountId].Account
+	accounts := append([]*types.Account{account}, model.getChildren(accountId, accountMap)...)
+	accountIds := make([]string, len(accounts))
+
+	for i, a := range accounts {
+		accountIds[i] = a.Id
+	}
+
+	balances, err := model.getPeriodBalances(accounts, []time.Time{start, end})
+
+	if err != nil {
+		return nil, err
+	}
+
+	skipped, err := model.db.GetLedgerSkippedBalances(accountIds, start, end, queryOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := model.db.GetLedgerLines(accountIds, start, end, queryOptions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &types.Ledger{
+		OrgId:     orgId,
+		AccountId: accountId,
+		Currency:  account.Currency,
+		Precision: account.Precision,
+		Start:     start,
+		End:       end,
+		Lines:     lines,
+	}
+
+	sameCurrency := make(map[string]bool)
+
+	for _, a := range accounts {
+		sameCurrency[a.Id] = a.Currency == account.Currency
+	}
+
+	opening := balances.asOf(start)
+	closing := balances.asOf(end)
+
+	var balance int64
+	var nativeBalance int64
+
+	for _, a := range accounts {
+		if b, ok := opening[a.Id]; ok {
+			if sameCurrency[a.Id] {
+				ledger.OpeningBalance += b.balance
+			}
+
+			ledger.OpeningNativeBalance += b.nativeBalance
+		}
+
+		if b, ok := closing[a.Id]; ok {
+			if sameCurrency[a.Id] {
+				ledger.ClosingBalance += b.balance
+			}
+
+			ledger.ClosingNativeBalance += b.nativeBalance
+		}
+
+		// splits on pages before this one
+		if b, ok := skipped[a.Id]; ok {
+			if sameCurrency[a.Id] {
+				balance += b.Balance
+			}
+
+			nativeBalance += b.NativeBalance
+		}
+	}
+
+	balance += ledger.OpeningBalance
+	nativeBalance += ledger.OpeningNativeBalance
+
+	for _, line := range lines {
+		if sameCurrency[line.AccountId] {
+			balance += line.Amount
+		}
+
+		nativeBalance += line.NativeAmount
+
+		line.Balance = balance
+		line.NativeBalance = nativeBalance
+	}
+
+	return ledger, nil
+}
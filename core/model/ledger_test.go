@@ -0,0 +1,145 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdLedger struct {
+	*TdReport
+}
+
+func (td *TdLedger) getLines(accountIds []string, start time.Time, end time.Time) []*types.LedgerLine {
+	lines := make([]*types.LedgerLine, 0)
+
+	// test splits are already in date order
+	for _, split := range td.splits {
+		if contains(accountIds, split.accountId) && !split.date.Before(start) && split.date.Before(end) {
+			lines = append(lines, &types.LedgerLine{
+				AccountId:    split.accountId,
+				Date:         split.date,
+				Amount:       split.amount,
+				NativeAmount: split.amount,
+			})
+		}
+	}
+
+	return lines
+}
+
+func (td *TdLedger) GetLedgerLines(accountIds []string, start time.Time, end time.Time, options *types.QueryOptions) ([]*types.LedgerLine, error) {
+	lines := td.getLines(accountIds, start, end)
+
+	if options.Skip > len(lines) {
+		return []*types.LedgerLine{}, nil
+	}
+
+	lines = lines[options.Skip:]
+
+	if options.Limit != 0 && options.Limit < len(lines) {
+		lines = lines[:options.Limit]
+	}
+
+	return lines, nil
+}
+
+func (td *TdLedger) GetLedgerSkippedBalances(accountIds []string, start time.Time, end time.Time, options *types.QueryOptions) (map[string]*types.PeriodBalance, error) {
+	balances := make(map[string]*types.PeriodBalance)
+
+	for i, line := range td.getLines(accountIds, start, end) {
+		if i >= options.Skip {
+			break
+		}
+
+		if balances[line.AccountId] == nil {
+			balances[line.AccountId] = &types.PeriodBalance{}
+		}
+
+		balances[line.AccountId].Balance += line.Amount
+		balances[line.AccountId].NativeBalance += line.NativeAmount
+	}
+
+	return balances, nil
+}
+
+func TestGetLedger(t *testing.T) {
+	tests := map[string]struct {
+		queryOptions *types.QueryOptions
+		amounts      []int64
+		balances     []int64
+	}{
+		"all lines": {
+			queryOptions: &types.QueryOptions{},
+			amounts:      []int64{3000, -1000},
+			balances:     []int64{5000, 4000},
+		},
+		"first page": {
+			queryOptions: &types.QueryOptions{Limit: 1},
+			amounts:      []int64{3000},
+			balances:     []int64{5000},
+		},
+		"second page": {
+			queryOptions: &types.QueryOptions{Skip: 1, Limit: 1},
+			amounts:      []int64{-1000},
+			balances:     []int64{4000},
+		},
+		"past the end": {
+			queryOptions: &types.QueryOptions{Skip: 5, Limit: 1},
+			amounts:      []int64{},
+			balances:     []int64{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdLedger{&TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}}
+		model := NewModel(td, nil, types.Config{})
+
+		// Assets includes Checking and Savings
+		ledger, err := model.GetLedger("1", "1", "2", &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31"}, test.queryOptions)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2000), ledger.OpeningBalance)
+		assert.Equal(t, int64(4000), ledger.ClosingBalance)
+		assert.Equal(t, int64(4000), ledger.ClosingNativeBalance)
+
+		amounts := make([]int64, 0)
+		balances := make([]int64, 0)
+
+		for _, line := range ledger.Lines {
+			amounts = append(amounts, line.Amount)
+			balances = append(balances, line.Balance)
+		}
+
+		assert.Equal(t, test.amounts, amounts)
+		assert.Equal(t, test.balances, balances)
+	}
+}
+
+func TestGetLedgerAllDates(t *testing.T) {
+	td := &TdLedger{&TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}}
+	model := NewModel(td, nil, types.Config{})
+
+	ledger, err := model.GetLedger("1", "1", "7", &types.ReportOptions{}, &types.QueryOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), ledger.OpeningBalance)
+	assert.Equal(t, 2, len(ledger.Lines))
+	assert.Equal(t, int64(5000), ledger.Lines[1].Balance)
+	assert.Equal(t, int64(5000), ledger.ClosingBalance)
+}
+
+func TestGetLedgerPermission(t *testing.T) {
+	// User only has access to Credit Card
+	td := &TdLedger{&TdReport{permissioned: []string{"9"}, splits: getReportTestSplits()}}
+	model := NewModel(td, nil, types.Config{})
+
+	_, err := model.GetLedger("1", "1", "2", &types.ReportOptions{}, &types.QueryOptions{})
+
+	assert.Equal(t, errors.New("user does not have permission to access account 2"), err)
+}
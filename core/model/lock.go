@@ -0,0 +1,11 @@
+package model
+
+type LockInterface interface {
+	RunWithLock(string, func() error) (bool, error)
+}
+
+// RunWithLock runs run unless another server is already running something
+// under the same name. It returns whether run was run.
+func (model *Model) RunWithLock(name string, run func() error) (bool, error) {
+	return model.db.RunWithLock(name, run)
+}
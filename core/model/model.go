@@ -24,6 +24,22 @@ type Interface interface {
 	ApiKeyInterface
 	SystemHealthInteface
 	BudgetInterface
+	ReportInterface
+	CashFlowInterface
+	LedgerInterface
+	BalanceHistoryInterface
+	BudgetVarianceInterface
+	PeriodInterface
+	ClosingInterface
+	RecurringTransactionInterface
+	TemplateInterface
+	ImportInterface
+	RuleInterface
+	ReconciliationInterface
+	ContactInterface
+	TagInterface
+	DimensionInterface
+	LockInterface
 }
 
 func NewModel(db db.Datastore, bcrypt util.Bcrypt, config types.Config) *Model {
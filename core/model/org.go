@@ -10,6 +10,7 @@ import (
 type OrgInterface interface {
 	CreateOrg(*types.Org, string) error
 	UpdateOrg(*types.Org, string) error
+	UpdateLockDate(string, *time.Time, string) (*types.Org, error)
 	GetOrg(string, string) (*types.Org, error)
 	GetOrgs(string) ([]*types.Org, error)
 	CreateInvite(*types.Invite, string) error
@@ -27,6 +28,14 @@ func (model *Model) CreateOrg(org *types.Org, userId string) error {
 		return errors.New("currency required")
 	}
 
+	if org.FiscalYearStart == 0 {
+		org.FiscalYearStart = 1
+	}
+
+	if org.FiscalYearStart < 1 || org.FiscalYearStart > 12 {
+		return errors.New("fiscalYearStart must be a month from 1 to 12")
+	}
+
 	accounts := make([]*types.Account, 6)
 
 	id, err := util.NewGuid()
@@ -123,7 +132,7 @@ func (model *Model) CreateOrg(org *types.Org, userId string) error {
 }
 
 func (model *Model) UpdateOrg(org *types.Org, userId string) error {
-	_, err := model.GetOrg(org.Id, userId)
+	original, err := model.GetOrg(org.Id, userId)
 
 	if err != nil {
 		// user doesn't have access to org
@@ -134,9 +143,40 @@ func (model *Model) UpdateOrg(org *types.Org, userId string) error {
 		return errors.New("name required")
 	}
 
+	if org.FiscalYearStart == 0 {
+		org.FiscalYearStart = original.FiscalYearStart
+	}
+
+	if org.FiscalYearStart < 1 || org.FiscalYearStart > 12 {
+		return errors.New("fiscalYearStart must be a month from 1 to 12")
+	}
+
+	// lock date is changed separately by an admin
+	org.LockDate = original.LockDate
+
 	return model.db.UpdateOrg(org)
 }
 
+func (model *Model) UpdateLockDate(orgId string, lockDate *time.Time, userId string) (*types.Org, error) {
+	isAdmin, err := model.isOrgAdmin(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if isAdmin == false {
+		return nil, errors.New("Must be org admin to change lock date")
+	}
+
+	err = model.db.UpdateLockDate(orgId, lockDate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model.GetOrg(orgId, userId)
+}
+
 func (model *Model) GetOrg(orgId string, userId string) (*types.Org, error) {
 	return model.db.GetOrg(orgId, userId)
 }
@@ -164,6 +204,22 @@ func (model *Model) UserBelongsToOrg(userId string, orgId string) (bool, error)
 	return belongs, nil
 }
 
+func (model *Model) isOrgAdmin(orgId string, userId string) (bool, error) {
+	admins, err := model.db.GetOrgAdmins(orgId)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, admin := range admins {
+		if admin.Id == userId {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 func (model *Model) CreateInvite(invite *types.Invite, userId string) error {
 	admins, err := model.db.GetOrgAdmins(invite.OrgId)
 
@@ -15,10 +15,11 @@ type TdOrg struct {
 func (td *TdOrg) GetOrg(orgId string, userId string) (*types.Org, error) {
 	if userId == "1" {
 		return &types.Org{
-			Id:        "1",
-			Name:      "MyOrg",
-			Currency:  "USD",
-			Precision: 2,
+			Id:              "1",
+			Name:            "MyOrg",
+			Currency:        "USD",
+			Precision:       2,
+			FiscalYearStart: 1,
 		}, nil
 	} else {
 		return nil, errors.New("not found")
@@ -59,6 +60,15 @@ func TestUpdateOrg(t *testing.T) {
 			},
 			userId: "1",
 		},
+		"bad fiscal year start": {
+			err: errors.New("fiscalYearStart must be a month from 1 to 12"),
+			org: &types.Org{
+				Id:              "1",
+				Name:            "MyOrg2",
+				FiscalYearStart: 13,
+			},
+			userId: "1",
+		},
 	}
 
 	for name, test := range tests {
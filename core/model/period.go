@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"time"
+)
+
+type PeriodInterface interface {
+	GetAccountingPeriods(string, string) ([]*types.AccountingPeriod, error)
+	CreateAccountingPeriod(*types.AccountingPeriod, string) error
+	CloseAccountingPeriod(string, string, string) (*types.AccountingPeriod, error)
+	ReopenAccountingPeriod(string, string, string) (*types.AccountingPeriod, error)
+}
+
+func (model *Model) GetAccountingPeriods(orgId string, userId string) ([]*types.AccountingPeriod, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	return model.db.GetAccountingPeriods(orgId)
+}
+
+func (model *Model) CreateAccountingPeriod(period *types.AccountingPeriod, userId string) error {
+	if period.Id == "" {
+		return errors.New("id required")
+	}
+
+	if period.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if period.Start.IsZero() || period.End.IsZero() {
+		return errors.New("start and end required")
+	}
+
+	if !period.Start.Before(period.End) {
+		return errors.New("start must be before end")
+	}
+
+	isAdmin, err := model.isOrgAdmin(period.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if isAdmin == false {
+		return errors.New("Must be org admin to manage periods")
+	}
+
+	periods, err := model.db.GetAccountingPeriods(period.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range periods {
+		if period.Start.Before(existing.End) && existing.Start.Before(period.End) {
+			return errors.New("period overlaps an existing period")
+		}
+	}
+
+	period.UserId = userId
+
+	return model.db.InsertAccountingPeriod(period)
+}
+
+func (model *Model) CloseAccountingPeriod(orgId string, id string, userId string) (*types.AccountingPeriod, error) {
+	return model.setAccountingPeriodClosed(orgId, id, userId, true)
+}
+
+func (model *Model) ReopenAccountingPeriod(orgId string, id string, userId string) (*types.AccountingPeriod, error) {
+	return model.setAccountingPeriodClosed(orgId, id, userId, false)
+}
+
+func (model *Model) setAccountingPeriodClosed(orgId string, id string, userId string, closed bool) (*types.AccountingPeriod, error) {
+	isAdmin, err := model.isOrgAdmin(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if isAdmin == false {
+		return nil, errors.New("Must be org admin to manage periods")
+	}
+
+	period, err := model.db.GetAccountingPeriod(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if period.OrgId != orgId {
+		return nil, errors.New("Period not found")
+	}
+
+	if period.Closed == closed {
+		if closed {
+			return nil, errors.New("period is already closed")
+		}
+
+		return nil, errors.New("period is not closed")
+	}
+
+	period.Closed = closed
+	period.UserId = userId
+
+	err = model.db.UpdateAccountingPeriod(period)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return period, nil
+}
+
+// checkLocked returns an error if any of dates is before the org's lock date
+// or falls within a closed accounting period
+func (model *Model) checkLocked(orgId string, userId string, dates ...time.Time) error {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	periods, err := model.db.GetAccountingPeriods(orgId)
+
+	if err != nil {
+		return err
+	}
+
+	return checkLockedDates(org, periods, dates...)
+}
+
+// checkLockedDates is checkLocked for an org and periods that are already loaded
+func checkLockedDates(org *types.Org, periods []*types.AccountingPeriod, dates ...time.Time) error {
+	for _, date := range dates {
+		if org.LockDate != nil && date.Before(*org.LockDate) {
+			return errors.New("transaction date is before the lock date")
+		}
+
+		for _, period := range periods {
+			if period.Closed && !date.Before(period.Start) && date.Before(period.End) {
+				return errors.New("transaction date is in a closed period")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,139 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdPeriod struct {
+	db.Datastore
+	periods []*types.AccountingPeriod
+}
+
+func (td *TdPeriod) GetOrgAdmins(orgId string) ([]*types.User, error) {
+	return []*types.User{&types.User{Id: "1"}}, nil
+}
+
+func (td *TdPeriod) GetAccountingPeriods(orgId string) ([]*types.AccountingPeriod, error) {
+	return td.periods, nil
+}
+
+func (td *TdPeriod) GetAccountingPeriod(id string) (*types.AccountingPeriod, error) {
+	for _, period := range td.periods {
+		if period.Id == id {
+			return period, nil
+		}
+	}
+
+	return nil, errors.New("Period not found")
+}
+
+func (td *TdPeriod) InsertAccountingPeriod(period *types.AccountingPeriod) error {
+	td.periods = append(td.periods, period)
+	return nil
+}
+
+func (td *TdPeriod) UpdateAccountingPeriod(period *types.AccountingPeriod) error {
+	return nil
+}
+
+func newTdPeriod() *TdPeriod {
+	return &TdPeriod{
+		periods: []*types.AccountingPeriod{
+			&types.AccountingPeriod{
+				Id:     "1",
+				OrgId:  "1",
+				Start:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Closed: false,
+			},
+		},
+	}
+}
+
+func TestCreateAccountingPeriod(t *testing.T) {
+	tests := map[string]struct {
+		userId string
+		start  time.Time
+		end    time.Time
+		err    error
+	}{
+		"successful": {
+			userId: "1",
+			start:  time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			err:    nil,
+		},
+		"not admin": {
+			userId: "2",
+			start:  time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			err:    errors.New("Must be org admin to manage periods"),
+		},
+		"end before start": {
+			userId: "1",
+			start:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			err:    errors.New("start must be before end"),
+		},
+		"overlap": {
+			userId: "1",
+			start:  time.Date(2018, time.December, 1, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2019, time.January, 1, 0, 0, 0, 0, time.UTC),
+			err:    errors.New("period overlaps an existing period"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdPeriod()
+		model := NewModel(td, nil, types.Config{})
+
+		period := &types.AccountingPeriod{
+			Id:    "2",
+			OrgId: "1",
+			Start: test.start,
+			End:   test.end,
+		}
+
+		err := model.CreateAccountingPeriod(period, test.userId)
+
+		assert.Equal(t, test.err, err)
+
+		if test.err == nil {
+			assert.Equal(t, 2, len(td.periods))
+			assert.Equal(t, test.userId, period.UserId)
+		}
+	}
+}
+
+func TestCloseAndReopenAccountingPeriod(t *testing.T) {
+	td := newTdPeriod()
+	model := NewModel(td, nil, types.Config{})
+
+	_, err := model.CloseAccountingPeriod("1", "1", "2")
+	assert.Equal(t, errors.New("Must be org admin to manage periods"), err)
+
+	_, err = model.CloseAccountingPeriod("2", "1", "1")
+	assert.Equal(t, errors.New("Period not found"), err)
+
+	period, err := model.CloseAccountingPeriod("1", "1", "1")
+	assert.Nil(t, err)
+	assert.True(t, period.Closed)
+
+	_, err = model.CloseAccountingPeriod("1", "1", "1")
+	assert.Equal(t, errors.New("period is already closed"), err)
+
+	period, err = model.ReopenAccountingPeriod("1", "1", "1")
+	assert.Nil(t, err)
+	assert.False(t, period.Closed)
+
+	_, err = model.ReopenAccountingPeriod("1", "1", "1")
+	assert.Equal(t, errors.New("period is not closed"), err)
+}
@@ -0,0 +1,384 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/ws"
+)
+
+type ReconciliationInterface interface {
+	GetReconciliations(string, string) ([]*types.Reconciliation, error)
+	GetReconciliation(string, string, string) (*types.Reconciliation, error)
+	CreateReconciliation(*types.Reconciliation) error
+	UpdateReconciliation(*types.Reconciliation) error
+	DeleteReconciliation(string, string, string) error
+	SetReconciliationSplits(string, string, string, *types.ReconciliationSplits) (*types.Reconciliation, error)
+	FinishReconciliation(string, string, string) (*types.Reconciliation, error)
+}
+
+// GetReconciliations returns the reconciliations of the accounts in an org the
+// user has access to, latest statement first
+func (model *Model) GetReconciliations(orgId string, userId string) ([]*types.Reconciliation, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations, err := model.db.GetReconciliations(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*types.Reconciliation, 0)
+
+	for _, reconciliation := range reconciliations {
+		if !model.accountsContainWriteAccess(userAccounts, reconciliation.AccountId) {
+			continue
+		}
+
+		err = model.setReconciliationBalances(reconciliation)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filtered = append(filtered, reconciliation)
+	}
+
+	return filtered, nil
+}
+
+func (model *Model) GetReconciliation(orgId string, id string, userId string) (*types.Reconciliation, error) {
+	reconciliation, err := model.getReconciliation(orgId, id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = model.setReconciliationBalances(reconciliation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliation, nil
+}
+
+// CreateReconciliation starts reconciling an account against a statement.
+// Only one reconciliation per account can be open at a time.
+func (model *Model) CreateReconciliation(reconciliation *types.Reconciliation) error {
+	if reconciliation.Id == "" {
+		return errors.New("id required")
+	}
+
+	if reconciliation.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if reconciliation.AccountId == "" {
+		return errors.New("accountId required")
+	}
+
+	if reconciliation.StatementDate.IsZero() {
+		return errors.New("statementDate required")
+	}
+
+	userAccounts, err := model.GetAccounts(reconciliation.OrgId, reconciliation.UserId, "")
+
+	if err != nil {
+		return err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, reconciliation.AccountId) {
+		return errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", reconciliation.AccountId))
+	}
+
+	account := model.getAccountFromList(userAccounts, reconciliation.AccountId)
+
+	if account.HasChildren == true {
+		return errors.New("Cannot reconcile a parent account")
+	}
+
+	existing, err := model.db.GetReconciliations(reconciliation.OrgId)
+
+	if err != nil {
+		return err
+	}
+
+	for _, other := range existing {
+		if other.AccountId == reconciliation.AccountId && other.Finished == false {
+			return errors.New("account already has an open reconciliation")
+		}
+	}
+
+	reconciliation.Finished = false
+
+	err = model.db.InsertReconciliation(reconciliation)
+
+	if err != nil {
+		return err
+	}
+
+	return model.setReconciliationBalances(reconciliation)
+}
+
+// UpdateReconciliation changes the statement date and balance of an open
+// reconciliation
+func (model *Model) UpdateReconciliation(reconciliation *types.Reconciliation) error {
+	original, err := model.getOpenReconciliation(reconciliation.OrgId, reconciliation.Id, reconciliation.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	if reconciliation.StatementDate.IsZero() {
+		return errors.New("statementDate required")
+	}
+
+	reconciliation.UserId = original.UserId
+	reconciliation.AccountId = original.AccountId
+	reconciliation.Inserted = original.Inserted
+	reconciliation.Finished = false
+
+	err = model.db.UpdateReconciliation(reconciliation)
+
+	if err != nil {
+		return err
+	}
+
+	return model.setReconciliationBalances(reconciliation)
+}
+
+// DeleteReconciliation abandons an open reconciliation. Splits keep their
+// cleared status.
+func (model *Model) DeleteReconciliation(orgId string, id string, userId string) error {
+	reconciliation, err := model.getReconciliation(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	if reconciliation.Finished == true {
+		return errors.New("a finished reconciliation cannot be deleted")
+	}
+
+	return model.db.DeleteReconciliation(id)
+}
+
+// SetReconciliationSplits marks the splits of transactions in the account of
+// an open reconciliation as cleared or uncleared
+func (model *Model) SetReconciliationSplits(orgId string, id string, userId string, splits *types.ReconciliationSplits) (*types.Reconciliation, error) {
+	reconciliation, err := model.getOpenReconciliation(orgId, id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if splits.Status != "cleared" && splits.Status != "uncleared" {
+		return nil, errors.New("status must be cleared or uncleared")
+	}
+
+	transactions := make([]*types.Transaction, len(splits.TransactionIds))
+
+	for i, transactionId := range splits.TransactionIds {
+		transaction, err := model.getTransactionById(transactionId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if transaction.OrgId != orgId || transaction.Deleted == true {
+			return nil, errors.New("Transaction not found")
+		}
+
+		found := false
+
+		for _, split := range transaction.Splits {
+			if split.AccountId != reconciliation.AccountId {
+				continue
+			}
+
+			if split.Status == "reconciled" {
+				return nil, errors.New(fmt.Sprintf("transaction %s is already reconciled", transactionId))
+			}
+
+			found = true
+		}
+
+		if found == false {
+			return nil, errors.New(fmt.Sprintf("transaction %s has no split in the account", transactionId))
+		}
+
+		transactions[i] = transaction
+	}
+
+	err = model.db.UpdateSplitStatus(reconciliation.AccountId, splits.TransactionIds, splits.Status)
+
+	if err != nil {
+		return nil, err
+	}
+
+	model.pushSplitStatusChanges(orgId, transactions)
+
+	err = model.setReconciliationBalances(reconciliation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliation, nil
+}
+
+// FinishReconciliation reconciles the cleared splits of the account once the
+// cleared balance matches the statement balance. Reconciled splits cannot be
+// changed afterwards.
+func (model *Model) FinishReconciliation(orgId string, id string, userId string) (*types.Reconciliation, error) {
+	reconciliation, err := model.getOpenReconciliation(orgId, id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = model.setReconciliationBalances(reconciliation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reconciliation.Difference != 0 {
+		return nil, errors.New("cleared balance does not match statement balance")
+	}
+
+	// the transactions with cleared splits are about to be reconciled
+	transactions := make([]*types.Transaction, len(reconciliation.TransactionIds))
+
+	for i, transactionId := range reconciliation.TransactionIds {
+		transactions[i], err = model.getTransactionById(transactionId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = model.db.FinishReconciliation(reconciliation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	model.pushSplitStatusChanges(orgId, transactions)
+
+	err = model.setReconciliationBalances(reconciliation)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reconciliation, nil
+}
+
+// getReconciliation returns a reconciliation of an org whose account the user
+// can write to, without balances
+func (model *Model) getReconciliation(orgId string, id string, userId string) (*types.Reconciliation, error) {
+	reconciliation, err := model.db.GetReconciliation(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reconciliation.OrgId != orgId {
+		return nil, errors.New("Reconciliation not found")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, reconciliation.AccountId) {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", reconciliation.AccountId))
+	}
+
+	return reconciliation, nil
+}
+
+func (model *Model) getOpenReconciliation(orgId string, id string, userId string) (*types.Reconciliation, error) {
+	reconciliation, err := model.getReconciliation(orgId, id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reconciliation.Finished == true {
+		return nil, errors.New("reconciliation is finished")
+	}
+
+	return reconciliation, nil
+}
+
+// setReconciliationBalances fills in the cleared balance, the difference from
+// the statement balance and the transactions with cleared splits. A finished
+// reconciliation matched its statement and its splits are now reconciled.
+func (model *Model) setReconciliationBalances(reconciliation *types.Reconciliation) error {
+	if reconciliation.Finished == true {
+		reconciliation.ClearedBalance = reconciliation.StatementBalance
+		reconciliation.Difference = 0
+		reconciliation.TransactionIds = make([]string, 0)
+		return nil
+	}
+
+	balance, err := model.db.GetClearedBalance(reconciliation.AccountId, reconciliation.StatementDate)
+
+	if err != nil {
+		return err
+	}
+
+	transactionIds, err := model.db.GetClearedTransactionIds(reconciliation.AccountId, reconciliation.StatementDate)
+
+	if err != nil {
+		return err
+	}
+
+	reconciliation.ClearedBalance = balance
+	reconciliation.Difference = reconciliation.StatementBalance - balance
+	reconciliation.TransactionIds = transactionIds
+
+	return nil
+}
+
+// pushSplitStatusChanges notifies web socket subscribers of transactions whose
+// split status changed. The new version of each transaction is loaded from the
+// db.
+func (model *Model) pushSplitStatusChanges(orgId string, transactions []*types.Transaction) {
+	// TODO only get user ids that have permission to access transaction
+	userIds, err := model.db.GetOrgUserIds(orgId)
+
+	if err != nil {
+		return
+	}
+
+	for _, original := range transactions {
+		transaction, err := model.getTransactionById(original.Id)
+
+		if err != nil {
+			continue
+		}
+
+		ws.PushTransaction(original, userIds, "delete")
+		ws.PushTransaction(transaction, userIds, "create")
+	}
+}
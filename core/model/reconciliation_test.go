@@ -0,0 +1,392 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdReconciliation struct {
+	*TdTransaction
+	reconciliations []*types.Reconciliation
+	clearedBalance  int64
+	statuses        map[string]string
+	dates           map[string]time.Time
+	finished        []string
+	updated         map[string]*types.Transaction
+}
+
+func (td *TdReconciliation) GetReconciliations(orgId string) ([]*types.Reconciliation, error) {
+	return td.reconciliations, nil
+}
+
+func (td *TdReconciliation) GetReconciliation(id string) (*types.Reconciliation, error) {
+	for _, reconciliation := range td.reconciliations {
+		if reconciliation.Id == id {
+			return reconciliation, nil
+		}
+	}
+
+	return nil, errors.New("Reconciliation not found")
+}
+
+func (td *TdReconciliation) InsertReconciliation(reconciliation *types.Reconciliation) error {
+	td.reconciliations = append(td.reconciliations, reconciliation)
+	return nil
+}
+
+func (td *TdReconciliation) GetClearedBalance(accountId string, statementDate time.Time) (int64, error) {
+	return td.clearedBalance, nil
+}
+
+func (td *TdReconciliation) GetClearedTransactionIds(accountId string, statementDate time.Time) ([]string, error) {
+	ids := make([]string, 0)
+
+	for id, status := range td.statuses {
+		if status == "cleared" && td.dates[id].Before(statementDate) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func (td *TdReconciliation) UpdateSplitStatus(accountId string, transactionIds []string, status string) error {
+	for _, id := range transactionIds {
+		td.statuses[id] = status
+	}
+
+	return nil
+}
+
+func (td *TdReconciliation) FinishReconciliation(reconciliation *types.Reconciliation) error {
+	td.finished = append(td.finished, reconciliation.Id)
+	reconciliation.Finished = true
+	return nil
+}
+
+func (td *TdReconciliation) DeleteAndInsertTransaction(oldId string, transaction *types.Transaction) error {
+	td.updated[oldId] = transaction
+	return nil
+}
+
+func newTdReconciliation() *TdReconciliation {
+	return &TdReconciliation{
+		TdTransaction: &TdTransaction{},
+		reconciliations: []*types.Reconciliation{
+			&types.Reconciliation{
+				Id:               "4",
+				OrgId:            "2",
+				AccountId:        "1",
+				StatementDate:    time.Date(2018, time.January, 31, 0, 0, 0, 0, time.UTC),
+				StatementBalance: 5000,
+				Finished:         true,
+			},
+			&types.Reconciliation{
+				Id:               "5",
+				OrgId:            "2",
+				AccountId:        "1",
+				StatementDate:    time.Date(2018, time.February, 28, 0, 0, 0, 0, time.UTC),
+				StatementBalance: 7500,
+			},
+		},
+		statuses: map[string]string{"7": "cleared", "10": "cleared"},
+		dates: map[string]time.Time{
+			"7":  time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+			"10": time.Date(2018, time.March, 5, 0, 0, 0, 0, time.UTC),
+		},
+		updated: make(map[string]*types.Transaction),
+	}
+}
+
+func TestCreateReconciliation(t *testing.T) {
+	statementDate := time.Date(2018, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		err            error
+		reconciliation *types.Reconciliation
+	}{
+		"successful": {
+			err: nil,
+			reconciliation: &types.Reconciliation{
+				Id:               "6",
+				OrgId:            "2",
+				UserId:           "3",
+				AccountId:        "2",
+				StatementDate:    statementDate,
+				StatementBalance: -2000,
+			},
+		},
+		"no statement date": {
+			err: errors.New("statementDate required"),
+			reconciliation: &types.Reconciliation{
+				Id:        "6",
+				OrgId:     "2",
+				UserId:    "3",
+				AccountId: "2",
+			},
+		},
+		"no permission": {
+			err: errors.New("user does not have permission to access account 3"),
+			reconciliation: &types.Reconciliation{
+				Id:            "6",
+				OrgId:         "2",
+				UserId:        "3",
+				AccountId:     "3",
+				StatementDate: statementDate,
+			},
+		},
+		"already open": {
+			err: errors.New("account already has an open reconciliation"),
+			reconciliation: &types.Reconciliation{
+				Id:            "6",
+				OrgId:         "2",
+				UserId:        "3",
+				AccountId:     "1",
+				StatementDate: statementDate,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdReconciliation()
+		td.clearedBalance = -1500
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateReconciliation(test.reconciliation)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, 3, len(td.reconciliations))
+			assert.Equal(t, int64(-1500), test.reconciliation.ClearedBalance)
+			assert.Equal(t, int64(-500), test.reconciliation.Difference)
+		}
+	}
+}
+
+func TestSetReconciliationSplits(t *testing.T) {
+	transactions := map[string]*types.Transaction{
+		"7": &types.Transaction{
+			Id:    "7",
+			OrgId: "2",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "7", AccountId: "1", Amount: 2500, NativeAmount: 2500, Status: "cleared"},
+				&types.Split{TransactionId: "7", AccountId: "2", Amount: -2500, NativeAmount: -2500, Status: "uncleared"},
+			},
+		},
+		"8": &types.Transaction{
+			Id:    "8",
+			OrgId: "2",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "8", AccountId: "1", Amount: 5000, NativeAmount: 5000, Status: "reconciled"},
+				&types.Split{TransactionId: "8", AccountId: "2", Amount: -5000, NativeAmount: -5000, Status: "uncleared"},
+			},
+		},
+		"9": &types.Transaction{
+			Id:    "9",
+			OrgId: "2",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "9", AccountId: "2", Amount: 1000, NativeAmount: 1000, Status: "uncleared"},
+				&types.Split{TransactionId: "9", AccountId: "2", Amount: -1000, NativeAmount: -1000, Status: "uncleared"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		err      error
+		splits   *types.ReconciliationSplits
+		statuses map[string]string
+	}{
+		"uncleared": {
+			err:      nil,
+			splits:   &types.ReconciliationSplits{TransactionIds: []string{"7"}, Status: "uncleared"},
+			statuses: map[string]string{"7": "uncleared", "10": "cleared"},
+		},
+		"bad status": {
+			err:      errors.New("status must be cleared or uncleared"),
+			splits:   &types.ReconciliationSplits{TransactionIds: []string{"7"}, Status: "reconciled"},
+			statuses: map[string]string{"7": "cleared", "10": "cleared"},
+		},
+		"already reconciled": {
+			err:      errors.New("transaction 8 is already reconciled"),
+			splits:   &types.ReconciliationSplits{TransactionIds: []string{"7", "8"}, Status: "uncleared"},
+			statuses: map[string]string{"7": "cleared", "10": "cleared"},
+		},
+		"other account": {
+			err:      errors.New("transaction 9 has no split in the account"),
+			splits:   &types.ReconciliationSplits{TransactionIds: []string{"9"}, Status: "cleared"},
+			statuses: map[string]string{"7": "cleared", "10": "cleared"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdReconciliation()
+
+		for id, transaction := range transactions {
+			td.On("GetTransactionById", id).Return(transaction, nil)
+		}
+
+		model := NewModel(td, nil, types.Config{})
+
+		reconciliation, err := model.SetReconciliationSplits("2", "5", "3", test.splits)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.statuses, td.statuses)
+
+		if err == nil {
+			assert.Equal(t, []string{}, reconciliation.TransactionIds)
+		}
+	}
+}
+
+func TestFinishReconciliation(t *testing.T) {
+	tests := map[string]struct {
+		err            error
+		id             string
+		clearedBalance int64
+	}{
+		"successful": {
+			err:            nil,
+			id:             "5",
+			clearedBalance: 7500,
+		},
+		"difference": {
+			err:            errors.New("cleared balance does not match statement balance"),
+			id:             "5",
+			clearedBalance: 7000,
+		},
+		"already finished": {
+			err:            errors.New("reconciliation is finished"),
+			id:             "4",
+			clearedBalance: 5000,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdReconciliation()
+		td.clearedBalance = test.clearedBalance
+		td.On("GetTransactionById", "7").Return(&types.Transaction{Id: "7", OrgId: "2"}, nil)
+		model := NewModel(td, nil, types.Config{})
+
+		reconciliation, err := model.FinishReconciliation("2", test.id, "3")
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, []string{"5"}, td.finished)
+			assert.Equal(t, true, reconciliation.Finished)
+			assert.Equal(t, int64(0), reconciliation.Difference)
+
+			// only the transaction dated before the statement is reconciled
+			td.AssertCalled(t, "GetTransactionById", "7")
+			td.AssertNotCalled(t, "GetTransactionById", "10")
+		} else {
+			assert.Equal(t, 0, len(td.finished))
+		}
+	}
+}
+
+func TestUpdateTransactionReconciled(t *testing.T) {
+	date := time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC)
+
+	original := &types.Transaction{
+		Id:    "1",
+		OrgId: "2",
+		Date:  date,
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000, Status: "reconciled"},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000, Status: "uncleared"},
+		},
+	}
+
+	tests := map[string]struct {
+		err    error
+		date   time.Time
+		splits []*types.Split
+	}{
+		"other split changed": {
+			err:  nil,
+			date: date,
+			splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1000, NativeAmount: 1000, Status: "reconciled"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -600, NativeAmount: -600, Status: "cleared"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -400, NativeAmount: -400},
+			},
+		},
+		"amount changed": {
+			err:  errors.New("reconciled splits cannot be changed"),
+			date: date,
+			splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1200, NativeAmount: 1200, Status: "reconciled"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -1200, NativeAmount: -1200},
+			},
+		},
+		"unreconciled": {
+			err:  errors.New("reconciled splits cannot be changed"),
+			date: date,
+			splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1000, NativeAmount: 1000, Status: "cleared"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		},
+		"date changed": {
+			err:  errors.New("reconciled splits cannot be changed"),
+			date: date.AddDate(0, 0, 1),
+			splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1000, NativeAmount: 1000, Status: "reconciled"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		},
+		"other split reconciled": {
+			err:  errors.New("splits can only be reconciled by finishing a reconciliation"),
+			date: date,
+			splits: []*types.Split{
+				&types.Split{TransactionId: "2", AccountId: "1", Amount: 1000, NativeAmount: 1000, Status: "reconciled"},
+				&types.Split{TransactionId: "2", AccountId: "2", Amount: -1000, NativeAmount: -1000, Status: "reconciled"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := newTdReconciliation()
+		td.On("GetTransactionById", "1").Return(original, nil)
+		td.On("GetReversals", "1").Return([]*types.Transaction{}, nil)
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:     "2",
+			OrgId:  "2",
+			UserId: "3",
+			Date:   test.date,
+			Splits: test.splits,
+		}
+
+		err := model.UpdateTransaction("1", transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, transaction, td.updated["1"])
+			assert.Equal(t, "uncleared", transaction.Splits[2].Status)
+		}
+	}
+
+	td := newTdReconciliation()
+	td.On("GetTransactionById", "1").Return(original, nil)
+	model := NewModel(td, nil, types.Config{})
+
+	err := model.DeleteTransaction("1", "3", "2")
+
+	assert.Equal(t, errors.New("cannot delete a transaction with reconciled splits"), err)
+}
@@ -0,0 +1,215 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// recurrenceRule is the subset of iCalendar RRULE supported by recurring
+// transactions, e.g. "FREQ=MONTHLY;BYMONTHDAY=1" or "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR"
+type recurrenceRule struct {
+	freq       string
+	interval   int
+	byMonthDay int
+	byDay      []time.Weekday
+}
+
+const maxRecurrenceInterval = 1000
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+func parseRecurrenceRule(rule string) (*recurrenceRule, error) {
+	r := &recurrenceRule{interval: 1}
+
+	if rule == "" {
+		return nil, errors.New("rule required")
+	}
+
+	for _, part := range strings.Split(strings.ToUpper(rule), ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+
+		if len(keyValue) != 2 {
+			return nil, errors.New(fmt.Sprintf("invalid rule part %s", part))
+		}
+
+		key, value := keyValue[0], keyValue[1]
+
+		switch key {
+		case "FREQ":
+			if value != "DAILY" && value != "WEEKLY" && value != "MONTHLY" && value != "YEARLY" {
+				return nil, errors.New("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+
+			r.freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return nil, errors.New(fmt.Sprintf("INTERVAL must be between 1 and %d", maxRecurrenceInterval))
+			}
+
+			r.interval = interval
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+
+			if err != nil || day == 0 || day < -1 || day > 31 {
+				return nil, errors.New("BYMONTHDAY must be between 1 and 31 or -1 for the last day")
+			}
+
+			r.byMonthDay = day
+		case "BYDAY":
+			for _, name := range strings.Split(value, ",") {
+				weekday, ok := recurrenceWeekdays[name]
+
+				if !ok {
+					return nil, errors.New(fmt.Sprintf("invalid BYDAY %s", name))
+				}
+
+				r.byDay = append(r.byDay, weekday)
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("unsupported rule part %s", key))
+		}
+	}
+
+	if r.freq == "" {
+		return nil, errors.New("FREQ required")
+	}
+
+	if r.byMonthDay != 0 && r.freq != "MONTHLY" {
+		return nil, errors.New("BYMONTHDAY requires FREQ=MONTHLY")
+	}
+
+	if len(r.byDay) > 0 && r.freq != "WEEKLY" {
+		return nil, errors.New("BYDAY requires FREQ=WEEKLY")
+	}
+
+	// order days within a week starting on Monday
+	sort.Slice(r.byDay, func(i, j int) bool {
+		return daysSinceMonday(r.byDay[i]) < daysSinceMonday(r.byDay[j])
+	})
+
+	return r, nil
+}
+
+// next returns the first occurrence on or after date. start is the first
+// possible occurrence and anchors the interval, the time of day and, unless
+// the rule says otherwise, the day of the week or month.
+func (r *recurrenceRule) next(start time.Time, date time.Time) time.Time {
+	for period := r.firstPeriod(start, date); ; period++ {
+		for _, occurrence := range r.occurrences(start, period) {
+			if !occurrence.Before(start) && !occurrence.Before(date) {
+				return occurrence
+			}
+		}
+	}
+}
+
+// firstPeriod returns a period that has no occurrences after the first
+// occurrence on or after date, so that next doesn't have to go through every
+// period since start. It may be a period or two early.
+func (r *recurrenceRule) firstPeriod(start time.Time, date time.Time) int {
+	date = date.In(start.Location())
+
+	if !date.After(start) {
+		return 0
+	}
+
+	var units int
+
+	switch r.freq {
+	case "DAILY":
+		units = daysBetween(start, date)
+	case "WEEKLY":
+		units = daysBetween(start, date) / 7
+	case "MONTHLY":
+		units = monthsBetween(start, date)
+	default:
+		units = monthsBetween(start, date) / 12
+	}
+
+	// occurrences can come before start within its period, e.g. BYMONTHDAY
+	period := units/r.interval - 1
+
+	if period < 0 {
+		return 0
+	}
+
+	return period
+}
+
+// occurrences returns the occurrences in the nth period (day, week, month or
+// year depending on the rule) after start in chronological order
+func (r *recurrenceRule) occurrences(start time.Time, period int) []time.Time {
+	n := period * r.interval
+
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{start.AddDate(0, 0, n)}
+	case "WEEKLY":
+		if len(r.byDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*n)}
+		}
+
+		monday := start.AddDate(0, 0, 7*n-daysSinceMonday(start.Weekday()))
+		occurrences := make([]time.Time, len(r.byDay))
+
+		for i, weekday := range r.byDay {
+			occurrences[i] = monday.AddDate(0, 0, daysSinceMonday(weekday))
+		}
+
+		return occurrences
+	case "MONTHLY":
+		day := start.Day()
+
+		if r.byMonthDay != 0 {
+			day = r.byMonthDay
+		}
+
+		return []time.Time{getDayOfMonth(start, n, day)}
+	default:
+		return []time.Time{getDayOfMonth(start, 12*n, start.Day())}
+	}
+}
+
+// daysBetween returns the number of calendar days from start to date
+func daysBetween(start time.Time, date time.Time) int {
+	from := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// monthsBetween returns the number of calendar months from start to date
+func monthsBetween(start time.Time, date time.Time) int {
+	return (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+}
+
+func daysSinceMonday(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// getDayOfMonth returns day of the month months after start at the same time
+// of day. Days past the end of the month, and -1, mean the last day.
+func getDayOfMonth(start time.Time, months int, day int) time.Time {
+	first := time.Date(start.Year(), start.Month()+time.Month(months), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	if day == -1 || day > lastDay {
+		day = lastDay
+	}
+
+	return first.AddDate(0, 0, day-1)
+}
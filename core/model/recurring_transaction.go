@@ -0,0 +1,364 @@
+package model
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"log"
+	"strconv"
+	"time"
+)
+
+// recurringRetryPeriod is how long after its date a failing occurrence of a
+// recurring transaction is retried before it is skipped
+const recurringRetryPeriod = 24 * time.Hour
+
+// maxRecurringCatchUp limits how many occurrences of a recurring transaction
+// are created in one run. The rest are created by the following runs.
+const maxRecurringCatchUp = 100
+
+// maxRecurringErrorLength is the size of the recurringtransaction.lastError
+// column
+const maxRecurringErrorLength = 300
+
+type RecurringTransactionInterface interface {
+	GetRecurringTransactions(string, string) ([]*types.RecurringTransaction, error)
+	GetRecurringTransaction(string, string, string) (*types.RecurringTransaction, error)
+	CreateRecurringTransaction(*types.RecurringTransaction) error
+	UpdateRecurringTransaction(*types.RecurringTransaction) error
+	DeleteRecurringTransaction(string, string, string) error
+	ProcessRecurringTransactions(time.Time) error
+}
+
+// GetRecurringTransactions returns the recurring transactions of an org that
+// only use accounts the user has access to
+func (model *Model) GetRecurringTransactions(orgId string, userId string) ([]*types.RecurringTransaction, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	recurrings, err := model.db.GetRecurringTransactions(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*types.RecurringTransaction, 0)
+
+	for _, recurring := range recurrings {
+		allowed := true
+
+		for _, split := range recurring.Splits {
+			if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
+				allowed = false
+				break
+			}
+		}
+
+		if allowed {
+			filtered = append(filtered, recurring)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (model *Model) GetRecurringTransaction(orgId string, id string, userId string) (*types.RecurringTransaction, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	recurring, err := model.db.GetRecurringTransaction(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if recurring.OrgId != orgId {
+		return nil, errors.New("Recurring transaction not found")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, split := range recurring.Splits {
+		if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
+			return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", split.AccountId))
+		}
+	}
+
+	return recurring, nil
+}
+
+func (model *Model) CreateRecurringTransaction(recurring *types.RecurringTransaction) error {
+	recurring.LastRun = nil
+	recurring.LastError = ""
+
+	err := model.checkRecurringTransaction(recurring)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertRecurringTransaction(recurring)
+}
+
+func (model *Model) UpdateRecurringTransaction(recurring *types.RecurringTransaction) error {
+	// GetRecurringTransaction checks that the user can access the current splits
+	original, err := model.GetRecurringTransaction(recurring.OrgId, recurring.Id, recurring.UserId)
+
+	if err != nil {
+		return err
+	}
+
+	recurring.Inserted = original.Inserted
+	recurring.LastRun = original.LastRun
+	// the schedule is fixed so earlier errors no longer apply
+	recurring.LastError = ""
+
+	err = model.checkRecurringTransaction(recurring)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.UpdateRecurringTransaction(recurring)
+}
+
+func (model *Model) DeleteRecurringTransaction(orgId string, id string, userId string) error {
+	// GetRecurringTransaction checks that the recurring transaction belongs to the org
+	_, err := model.GetRecurringTransaction(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.DeleteRecurringTransaction(id)
+}
+
+// ProcessRecurringTransactions creates a transaction for every occurrence of a
+// recurring transaction that is due on or before date. An occurrence that still
+// fails a day after its date is skipped and its error is saved in lastError.
+func (model *Model) ProcessRecurringTransactions(date time.Time) error {
+	recurrings, err := model.db.GetDueRecurringTransactions(date)
+
+	if err != nil {
+		return err
+	}
+
+	for _, recurring := range recurrings {
+		for i := 0; i < maxRecurringCatchUp && recurring.NextRun != nil && !recurring.NextRun.After(date); i++ {
+			err = model.runRecurringTransaction(recurring)
+
+			if err == nil {
+				continue
+			}
+
+			log.Printf("Failed to run recurring transaction %s: %s\n", recurring.Id, err.Error())
+
+			// keep retrying for a while in case the error is temporary, then skip
+			// the occurrence so that later ones aren't held up
+			if date.Sub(*recurring.NextRun) < recurringRetryPeriod {
+				break
+			}
+
+			err = model.skipRecurringOccurrence(recurring, err)
+
+			if err != nil {
+				log.Printf("Failed to skip occurrence of recurring transaction %s: %s\n", recurring.Id, err.Error())
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// skipRecurringOccurrence moves recurring past its next run without creating a
+// transaction and records why. If the following run can't be worked out the
+// recurring transaction is stopped.
+func (model *Model) skipRecurringOccurrence(recurring *types.RecurringTransaction, cause error) error {
+	occurrence := *recurring.NextRun
+
+	recurring.LastError = fmt.Sprintf("occurrence on %s skipped: %s", occurrence.Format("2006-01-02"), cause.Error())
+
+	if lastError := []rune(recurring.LastError); len(lastError) > maxRecurringErrorLength {
+		recurring.LastError = string(lastError[:maxRecurringErrorLength])
+	}
+
+	log.Printf("Recurring transaction %s %s\n", recurring.Id, recurring.LastError)
+
+	recurring.LastRun = &occurrence
+	recurring.NextRun = nil
+
+	rule, err := parseRecurrenceRule(recurring.Rule)
+
+	if err == nil {
+		var location *time.Location
+		location, err = model.getRecurringLocation(recurring)
+
+		if err == nil {
+			recurring.NextRun = getRecurringNextRun(recurring, rule, location)
+		}
+	}
+
+	return model.db.UpdateRecurringTransactionRun(recurring)
+}
+
+// runRecurringTransaction creates the transaction of the next run of recurring
+// and moves it to the following run
+func (model *Model) runRecurringTransaction(recurring *types.RecurringTransaction) error {
+	rule, err := parseRecurrenceRule(recurring.Rule)
+
+	if err != nil {
+		return err
+	}
+
+	location, err := model.getRecurringLocation(recurring)
+
+	if err != nil {
+		return err
+	}
+
+	occurrence := *recurring.NextRun
+	transaction := getRecurringOccurrence(recurring, occurrence)
+
+	// The id is derived from the occurrence so an occurrence that was created
+	// before nextRun could be saved is not created twice
+	_, err = model.getTransactionById(transaction.Id)
+
+	if err != nil {
+		err = model.CreateTransaction(transaction)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	recurring.LastRun = &occurrence
+	recurring.NextRun = getRecurringNextRun(recurring, rule, location)
+
+	return model.db.UpdateRecurringTransactionRun(recurring)
+}
+
+// checkRecurringTransaction validates recurring and sets its next run
+func (model *Model) checkRecurringTransaction(recurring *types.RecurringTransaction) error {
+	if recurring.Id == "" {
+		return errors.New("id required")
+	}
+
+	if recurring.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if recurring.Start.IsZero() {
+		return errors.New("start required")
+	}
+
+	if recurring.End != nil && recurring.End.Before(recurring.Start) {
+		return errors.New("end must not be before start")
+	}
+
+	rule, err := parseRecurrenceRule(recurring.Rule)
+
+	if err != nil {
+		return err
+	}
+
+	// splits are held to the same rules as the transactions they create
+	err = model.checkSplits(getRecurringOccurrence(recurring, recurring.Start))
+
+	if err != nil {
+		return err
+	}
+
+	location, err := model.getRecurringLocation(recurring)
+
+	if err != nil {
+		return err
+	}
+
+	recurring.NextRun = getRecurringNextRun(recurring, rule, location)
+
+	return nil
+}
+
+func (model *Model) getRecurringLocation(recurring *types.RecurringTransaction) (*time.Location, error) {
+	org, err := model.GetOrg(recurring.OrgId, recurring.UserId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(org.Timezone)
+}
+
+// getRecurringNextRun returns the first occurrence after the day of the last
+// run, or nil if there are no more occurrences before the end date
+func getRecurringNextRun(recurring *types.RecurringTransaction, rule *recurrenceRule, location *time.Location) *time.Time {
+	start := recurring.Start.In(location)
+	date := start
+
+	if recurring.LastRun != nil {
+		lastRun := recurring.LastRun.In(location)
+		date = time.Date(lastRun.Year(), lastRun.Month(), lastRun.Day()+1, 0, 0, 0, 0, location)
+	}
+
+	next := rule.next(start, date)
+
+	if recurring.End != nil && next.After(*recurring.End) {
+		return nil
+	}
+
+	return &next
+}
+
+// getRecurringOccurrence returns the transaction recurring creates on date
+func getRecurringOccurrence(recurring *types.RecurringTransaction, date time.Time) *types.Transaction {
+	hash := md5.Sum([]byte(recurring.Id + strconv.FormatInt(util.TimeToMs(date), 10)))
+
+	transaction := &types.Transaction{
+		Id:          hex.EncodeToString(hash[:]),
+		OrgId:       recurring.OrgId,
+		UserId:      recurring.UserId,
+		Date:        date,
+		Description: recurring.Description,
+		Data:        recurring.Data,
+		Splits:      make([]*types.Split, len(recurring.Splits)),
+	}
+
+	for i, split := range recurring.Splits {
+		transaction.Splits[i] = &types.Split{
+			TransactionId: transaction.Id,
+			AccountId:     split.AccountId,
+			Amount:        split.Amount,
+			NativeAmount:  split.NativeAmount,
+		}
+	}
+
+	return transaction
+}
@@ -0,0 +1,383 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+type TdRecurring struct {
+	*TdTransaction
+	recurrings []*types.RecurringTransaction
+	runs       []*types.RecurringTransaction
+}
+
+func (td *TdRecurring) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "2"}}, nil
+}
+
+func (td *TdRecurring) InsertRecurringTransaction(recurring *types.RecurringTransaction) error {
+	td.recurrings = append(td.recurrings, recurring)
+	return nil
+}
+
+func (td *TdRecurring) GetDueRecurringTransactions(date time.Time) ([]*types.RecurringTransaction, error) {
+	due := make([]*types.RecurringTransaction, 0)
+
+	for _, recurring := range td.recurrings {
+		if recurring.NextRun != nil && !recurring.NextRun.After(date) {
+			due = append(due, recurring)
+		}
+	}
+
+	return due, nil
+}
+
+func (td *TdRecurring) UpdateRecurringTransactionRun(recurring *types.RecurringTransaction) error {
+	run := *recurring
+	td.runs = append(td.runs, &run)
+	return nil
+}
+
+func TestRecurrenceRule(t *testing.T) {
+	tests := map[string]struct {
+		rule        string
+		start       time.Time
+		occurrences []time.Time
+		err         error
+	}{
+		"daily": {
+			rule:  "FREQ=DAILY",
+			start: time.Date(2018, time.March, 30, 9, 0, 0, 0, time.UTC),
+			occurrences: []time.Time{
+				time.Date(2018, time.March, 30, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, time.March, 31, 9, 0, 0, 0, time.UTC),
+				time.Date(2018, time.April, 1, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		"every other week on monday and friday": {
+			rule:  "freq=weekly;interval=2;byday=fr,mo",
+			start: time.Date(2018, time.March, 7, 0, 0, 0, 0, time.UTC),
+			occurrences: []time.Time{
+				time.Date(2018, time.March, 9, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.March, 19, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.March, 23, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"monthly on day 31": {
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			start: time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+			occurrences: []time.Time{
+				time.Date(2018, time.January, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.February, 28, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.March, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"quarterly on the last day": {
+			rule:  "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=-1",
+			start: time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+			occurrences: []time.Time{
+				time.Date(2018, time.February, 28, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.May, 31, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.August, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"yearly": {
+			rule:  "FREQ=YEARLY",
+			start: time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC),
+			occurrences: []time.Time{
+				time.Date(2016, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2017, time.February, 28, 0, 0, 0, 0, time.UTC),
+				time.Date(2018, time.February, 28, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"missing freq": {
+			rule: "INTERVAL=2",
+			err:  errors.New("FREQ required"),
+		},
+		"unsupported part": {
+			rule: "FREQ=DAILY;COUNT=3",
+			err:  errors.New("unsupported rule part COUNT"),
+		},
+		"byday without weekly": {
+			rule: "FREQ=MONTHLY;BYDAY=MO",
+			err:  errors.New("BYDAY requires FREQ=WEEKLY"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		rule, err := parseRecurrenceRule(test.rule)
+
+		assert.Equal(t, test.err, err)
+
+		if err != nil {
+			continue
+		}
+
+		occurrences := make([]time.Time, 0)
+		date := test.start
+
+		for len(occurrences) < len(test.occurrences) {
+			occurrence := rule.next(test.start, date)
+			occurrences = append(occurrences, occurrence)
+			date = occurrence.AddDate(0, 0, 1)
+		}
+
+		assert.Equal(t, test.occurrences, occurrences)
+	}
+}
+
+func TestCreateRecurringTransaction(t *testing.T) {
+	end := time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		recurring *types.RecurringTransaction
+		nextRun   *time.Time
+		err       error
+	}{
+		"successful": {
+			recurring: &types.RecurringTransaction{
+				Id:     "1",
+				OrgId:  "2",
+				UserId: "3",
+				Rule:   "FREQ=MONTHLY;BYMONTHDAY=1",
+				Start:  time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+				},
+			},
+			nextRun: &end,
+			err:     nil,
+		},
+		"ended": {
+			recurring: &types.RecurringTransaction{
+				Id:     "1",
+				OrgId:  "2",
+				UserId: "3",
+				Rule:   "FREQ=MONTHLY;BYMONTHDAY=2",
+				Start:  time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+				End:    &end,
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+				},
+			},
+			nextRun: nil,
+			err:     nil,
+		},
+		"unbalanced splits": {
+			recurring: &types.RecurringTransaction{
+				Id:     "1",
+				OrgId:  "2",
+				UserId: "3",
+				Rule:   "FREQ=DAILY",
+				Start:  time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -500, NativeAmount: -500},
+				},
+			},
+			err: errors.New("splits must add up to 0"),
+		},
+		"end before start": {
+			recurring: &types.RecurringTransaction{
+				Id:     "1",
+				OrgId:  "2",
+				UserId: "3",
+				Rule:   "FREQ=DAILY",
+				Start:  time.Date(2018, time.March, 15, 0, 0, 0, 0, time.UTC),
+				End:    &end,
+			},
+			err: errors.New("end must not be before start"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdRecurring{TdTransaction: &TdTransaction{}}
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateRecurringTransaction(test.recurring)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.nextRun, test.recurring.NextRun)
+			assert.Equal(t, 1, len(td.recurrings))
+		}
+	}
+}
+
+func TestProcessRecurringTransactions(t *testing.T) {
+	td := &TdRecurring{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	end := time.Date(2018, time.April, 30, 0, 0, 0, 0, time.UTC)
+
+	recurring := &types.RecurringTransaction{
+		Id:          "1",
+		OrgId:       "2",
+		UserId:      "3",
+		Description: "rent",
+		Rule:        "FREQ=MONTHLY;BYMONTHDAY=1",
+		Start:       time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+		End:         &end,
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	err := model.CreateRecurringTransaction(recurring)
+
+	assert.Nil(t, err)
+
+	// the February occurrence was already created by an interrupted run
+	february := getRecurringOccurrence(recurring, time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC))
+	march := getRecurringOccurrence(recurring, time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC))
+	april := getRecurringOccurrence(recurring, time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC))
+
+	td.On("GetTransactionById", february.Id).Return(february, nil)
+	td.On("GetTransactionById", march.Id).Return((*types.Transaction)(nil), errors.New("not found"))
+	td.On("GetTransactionById", april.Id).Return((*types.Transaction)(nil), errors.New("not found"))
+
+	err = model.ProcessRecurringTransactions(time.Date(2018, time.March, 15, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(td.inserted))
+	assert.Equal(t, march.Id, td.inserted[0].Id)
+	assert.Equal(t, march.Date, td.inserted[0].Date)
+	assert.Equal(t, 2, len(td.runs))
+	assert.Equal(t, time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC), *td.runs[1].LastRun)
+	assert.Equal(t, time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC), *td.runs[1].NextRun)
+
+	err = model.ProcessRecurringTransactions(time.Date(2018, time.May, 15, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(td.inserted))
+	assert.Equal(t, april.Id, td.inserted[1].Id)
+	assert.Equal(t, "rent", td.inserted[1].Description)
+	assert.Nil(t, recurring.NextRun)
+}
+
+func TestProcessRecurringTransactionsFailing(t *testing.T) {
+	td := &TdRecurring{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	// January is in a closed period so its occurrence can never be created
+	recurring := &types.RecurringTransaction{
+		Id:          "1",
+		OrgId:       "2",
+		UserId:      "3",
+		Description: "rent",
+		Rule:        "FREQ=MONTHLY;BYMONTHDAY=1",
+		Start:       time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	err := model.CreateRecurringTransaction(recurring)
+
+	assert.Nil(t, err)
+
+	january := getRecurringOccurrence(recurring, time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC))
+	february := getRecurringOccurrence(recurring, time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC))
+
+	td.On("GetTransactionById", january.Id).Return((*types.Transaction)(nil), errors.New("not found"))
+	td.On("GetTransactionById", february.Id).Return((*types.Transaction)(nil), errors.New("not found"))
+
+	// retried while the error could be temporary
+	err = model.ProcessRecurringTransactions(time.Date(2018, time.January, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(td.inserted))
+	assert.Equal(t, 0, len(td.runs))
+
+	// skipped afterwards so that later occurrences are still created
+	err = model.ProcessRecurringTransactions(time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(td.inserted))
+	assert.Equal(t, february.Id, td.inserted[0].Id)
+	assert.Equal(t, 2, len(td.runs))
+	assert.Equal(t, time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC), *td.runs[0].LastRun)
+	assert.Equal(t, "occurrence on 2018-01-01 skipped: transaction date is in a closed period", td.runs[0].LastError)
+	assert.Equal(t, time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC), *recurring.NextRun)
+}
+
+func TestRecurrenceRuleNextFromFirstPeriod(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	start := time.Date(2016, time.January, 31, 9, 0, 0, 0, location)
+
+	rules := []string{
+		"FREQ=DAILY;INTERVAL=3",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+		"FREQ=WEEKLY;BYDAY=SU",
+		"FREQ=MONTHLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;INTERVAL=5;BYMONTHDAY=-1",
+		"FREQ=YEARLY",
+	}
+
+	for _, ruleString := range rules {
+		t.Logf("Running test case: %s", ruleString)
+
+		rule, err := parseRecurrenceRule(ruleString)
+
+		assert.Nil(t, err)
+
+		// scanning from the first period must give the same occurrence
+		for date := start.AddDate(0, 0, -3); date.Before(start.AddDate(3, 0, 0)); date = date.Add(17 * time.Hour) {
+			want := time.Time{}
+
+			for period := 0; want.IsZero(); period++ {
+				for _, occurrence := range rule.occurrences(start, period) {
+					if want.IsZero() && !occurrence.Before(start) && !occurrence.Before(date) {
+						want = occurrence
+					}
+				}
+			}
+
+			assert.Equal(t, want, rule.next(start, date), date.String())
+		}
+	}
+}
+
+func TestProcessRecurringTransactionsCatchUp(t *testing.T) {
+	td := &TdRecurring{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	recurring := &types.RecurringTransaction{
+		Id:          "1",
+		OrgId:       "2",
+		UserId:      "3",
+		Description: "coffee",
+		Rule:        "FREQ=DAILY",
+		Start:       time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 300, NativeAmount: 300},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -300, NativeAmount: -300},
+		},
+	}
+
+	err := model.CreateRecurringTransaction(recurring)
+
+	assert.Nil(t, err)
+
+	td.On("GetTransactionById", mock.Anything).Return((*types.Transaction)(nil), errors.New("not found"))
+
+	// the rest of the missed occurrences are left for later runs
+	err = model.ProcessRecurringTransactions(time.Date(2018, time.December, 31, 0, 0, 0, 0, time.UTC))
+
+	assert.Nil(t, err)
+	assert.Equal(t, maxRecurringCatchUp, len(td.inserted))
+	assert.Equal(t, recurring.Start.AddDate(0, 0, maxRecurringCatchUp), *recurring.NextRun)
+}
@@ -0,0 +1,818 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+)
+
+type ReportInterface interface {
+	GetTrialBalance(string, string, *types.ReportOptions) (*types.TrialBalance, error)
+	GetIncomeStatement(string, string, *types.ReportOptions) (*types.IncomeStatement, error)
+	GetBalanceSheet(string, string, *types.ReportOptions, string) (*types.BalanceSheet, error)
+}
+
+const reportDateFormat = "2006-01-02"
+
+// maxReportPeriods keeps a single request from asking for an unbounded
+// number of columns
+const maxReportPeriods = 120
+
+const (
+	valuationCost   = "cost"
+	valuationMarket = "market"
+)
+
+type reportBalance struct {
+	balance       int64
+	nativeBalance int64
+}
+
+// periodBalances holds split sums for each account bucketed by boundaries.
+// Bucket 0 holds everything before the first boundary and bucket i everything
+// from boundary i-1 up to boundary i.
+type periodBalances struct {
+	boundaries []time.Time
+	balances   map[string][]*types.PeriodBalance
+}
+
+func (model *Model) GetTrialBalance(orgId string, userId string, options *types.ReportOptions) (*types.TrialBalance, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// TODO how do we make date an optional parameter
+	// instead of resorting to this hack?
+	dates, err := model.getReportDates(org, options, time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		return nil, err
+	}
+
+	filters, pivotValues, err := model.getDimensionFilters(orgId, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+	columns := make([]map[string]*reportBalance, 0, len(dates)*len(filters))
+	columnDates := make([]time.Time, 0, cap(columns))
+
+	// pivoted reports repeat every date for each pivot value
+	for _, filter := range filters {
+		balances, err := model.getFilteredPeriodBalances(accounts, dates, filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, date := range dates {
+			columns = append(columns, model.rollUpBalances(accounts, accountMap, balances.asOf(date)))
+			columnDates = append(columnDates, date)
+		}
+	}
+
+	trialBalance := &types.TrialBalance{
+		OrgId:              orgId,
+		Dates:              columnDates,
+		PivotValues:        repeatPivotValues(pivotValues, len(dates)),
+		Lines:              make([]*types.TrialBalanceLine, 0, len(accounts)),
+		NativeDebitTotals:  make([]int64, len(columns)),
+		NativeCreditTotals: make([]int64, len(columns)),
+	}
+
+	model.walkAccounts(accountMap, func(account *types.Account, depth int) {
+		line := &types.TrialBalanceLine{
+			AccountId:    account.Id,
+			Name:         account.Name,
+			Parent:       account.Parent,
+			Depth:        depth,
+			Currency:     account.Currency,
+			Precision:    account.Precision,
+			DebitBalance: account.DebitBalance,
+			Leaf:         !account.HasChildren,
+			Columns:      make([]*types.TrialBalanceColumn, len(columns)),
+		}
+
+		for i, totals := range columns {
+			total := totals[account.Id]
+			column := &types.TrialBalanceColumn{}
+
+			column.Debit, column.Credit = debitCredit(account.DebitBalance, total.balance)
+			column.NativeDebit, column.NativeCredit = debitCredit(account.DebitBalance, total.nativeBalance)
+
+			// parents already include their children so only count leaves
+			if line.Leaf {
+				trialBalance.NativeDebitTotals[i] += column.NativeDebit
+				trialBalance.NativeCreditTotals[i] += column.NativeCredit
+			}
+
+			line.Columns[i] = column
+		}
+
+		trialBalance.Lines = append(trialBalance.Lines, line)
+	})
+
+	return trialBalance, nil
+}
+
+func (model *Model) GetIncomeStatement(orgId string, userId string, options *types.ReportOptions) (*types.IncomeStatement, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := model.getReportPeriods(org, options, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filters, pivotValues, err := model.getDimensionFilters(orgId, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, len(periods)*2)
+
+	for _, period := range periods {
+		times = append(times, period.Start, period.End)
+	}
+
+	closingTransactions, err := model.getClosingTransactions(orgId, periods)
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+	columns := make([]map[string]*reportBalance, 0, len(periods)*len(filters))
+	columnPeriods := make([]*types.ReportPeriod, 0, cap(columns))
+
+	// pivoted reports repeat every period for each pivot value
+	for _, filter := range filters {
+		balances, err := model.getFilteredPeriodBalances(accounts, times, filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		closingEntries := filterTransactionSplits(closingTransactions, filter)
+
+		for _, period := range periods {
+			leafBalances := balances.between(period.Start, period.End)
+			model.removeClosingEntries(leafBalances, closingEntries, period)
+			columns = append(columns, model.rollUpBalances(accounts, accountMap, leafBalances))
+			columnPeriods = append(columnPeriods, period)
+		}
+	}
+
+	incomeNode := model.getTopLevelAccountByName(accountMap, "Income")
+
+	if incomeNode == nil {
+		return nil, errors.New("Income account not found")
+	}
+
+	expensesNode := model.getTopLevelAccountByName(accountMap, "Expenses")
+
+	if expensesNode == nil {
+		return nil, errors.New("Expenses account not found")
+	}
+
+	// income has a credit balance so flip the sign to show it as positive
+	income := model.makeReportSection(incomeNode, columns, -1)
+	expenses := model.makeReportSection(expensesNode, columns, 1)
+
+	netIncome := make([]int64, len(columns))
+
+	for i := range columns {
+		netIncome[i] = income.Totals[i] - expenses.Totals[i]
+	}
+
+	return &types.IncomeStatement{
+		OrgId:       orgId,
+		Periods:     columnPeriods,
+		PivotValues: repeatPivotValues(pivotValues, len(periods)),
+		Income:      income,
+		Expenses:    expenses,
+		NetIncome:   netIncome,
+	}, nil
+}
+
+func (model *Model) GetBalanceSheet(orgId string, userId string, options *types.ReportOptions, valuation string) (*types.BalanceSheet, error) {
+	if valuation == "" {
+		valuation = valuationCost
+	}
+
+	if valuation != valuationCost && valuation != valuationMarket {
+		return nil, errors.New("invalid valuation")
+	}
+
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := model.getReportDates(org, options, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	filters, pivotValues, err := model.getDimensionFilters(orgId, options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+
+	assetsNode := model.getTopLevelAccountByName(accountMap, "Assets")
+	liabilitiesNode := model.getTopLevelAccountByName(accountMap, "Liabilities")
+	equityNode := model.getTopLevelAccountByName(accountMap, "Equity")
+	incomeNode := model.getTopLevelAccountByName(accountMap, "Income")
+	expensesNode := model.getTopLevelAccountByName(accountMap, "Expenses")
+
+	if assetsNode == nil || liabilitiesNode == nil || equityNode == nil || incomeNode == nil || expensesNode == nil {
+		return nil, errors.New("Assets, Liabilities, Equity, Income and Expenses accounts are required")
+	}
+
+	// Earnings are split into those from previous years and the current year
+	yearStarts := make([]time.Time, len(dates))
+
+	for i, date := range dates {
+		// dates are exclusive so a balance sheet as of midnight at the start of
+		// a fiscal year still belongs to the year that just ended
+		yearStarts[i] = getFiscalYearStart(org, date.Add(-time.Millisecond), location)
+	}
+
+	times := append(append([]time.Time{}, dates...), yearStarts...)
+
+	earningsAccounts := model.getSubtreeAccounts(accountMap, incomeNode, expensesNode)
+	balanceSheetAccounts := model.getSubtreeAccounts(accountMap, assetsNode, liabilitiesNode, equityNode)
+
+	columns := make([]map[string]*reportBalance, 0, len(dates)*len(filters))
+	columnDates := make([]time.Time, 0, cap(columns))
+	earnings := make([]int64, cap(columns))
+	retainedEarnings := make([]int64, cap(columns))
+	unrealizedGains := make([]int64, cap(columns))
+
+	// pivoted reports repeat every date for each pivot value
+	for _, filter := range filters {
+		balances, err := model.getFilteredPeriodBalances(accounts, times, filter)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i, date := range dates {
+			column := len(columns)
+			leafBalances := balances.asOf(date)
+			priorBalances := balances.asOf(yearStarts[i])
+
+			for _, account := range earningsAccounts {
+				if balance, ok := leafBalances[account.Id]; ok {
+					earnings[column] -= balance.nativeBalance
+				}
+
+				if balance, ok := priorBalances[account.Id]; ok {
+					retainedEarnings[column] -= balance.nativeBalance
+				}
+			}
+
+			if valuation == valuationMarket {
+				unrealizedGains[column], err = model.revalueBalances(balanceSheetAccounts, leafBalances, date)
+
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			columns = append(columns, model.rollUpBalances(accounts, accountMap, leafBalances))
+			columnDates = append(columnDates, date)
+		}
+	}
+
+	balanceSheet := &types.BalanceSheet{
+		OrgId:                     orgId,
+		Dates:                     columnDates,
+		PivotValues:               repeatPivotValues(pivotValues, len(dates)),
+		Valuation:                 valuation,
+		Assets:                    model.makeReportSection(assetsNode, columns, 1),
+		Liabilities:               model.makeReportSection(liabilitiesNode, columns, -1),
+		Equity:                    model.makeReportSection(equityNode, columns, -1),
+		TotalLiabilitiesAndEquity: make([]int64, len(columns)),
+	}
+
+	currentYearEarnings := make([]int64, len(columns))
+
+	for i := range columns {
+		currentYearEarnings[i] = earnings[i] - retainedEarnings[i]
+	}
+
+	model.addComputedReportLine(balanceSheet.Equity, org, "Retained Earnings", retainedEarnings)
+	model.addComputedReportLine(balanceSheet.Equity, org, "Current Year Earnings", currentYearEarnings)
+
+	if valuation == valuationMarket {
+		model.addComputedReportLine(balanceSheet.Equity, org, "Unrealized Gains", unrealizedGains)
+	}
+
+	for i := range columns {
+		balanceSheet.TotalLiabilitiesAndEquity[i] = balanceSheet.Liabilities.Totals[i] + balanceSheet.Equity.Totals[i]
+	}
+
+	return balanceSheet, nil
+}
+
+// repeatPivotValues lists the pivot value of each column of a report where
+// each pivot value has n columns. It returns nil if the report is not pivoted.
+func repeatPivotValues(pivotValues []string, n int) []string {
+	if pivotValues == nil {
+		return nil
+	}
+
+	columns := make([]string, 0, len(pivotValues)*n)
+
+	for _, pivotValue := range pivotValues {
+		for i := 0; i < n; i++ {
+			columns = append(columns, pivotValue)
+		}
+	}
+
+	return columns
+}
+
+// revalueBalances replaces the native balances of accounts with their value
+// at the price nearest to date. It returns the difference from cost, which is
+// shown as an unrealized gain or loss.
+func (model *Model) revalueBalances(accounts []*types.Account, balances map[string]*reportBalance, date time.Time) (int64, error) {
+	revalued := make([]*types.Account, 0, len(accounts))
+
+	for _, account := range accounts {
+		if balance, ok := balances[account.Id]; ok {
+			accountCopy := *account
+			accountCopy.Balance = &balance.balance
+			accountCopy.NativeBalance = nil
+			revalued = append(revalued, &accountCopy)
+		}
+	}
+
+	err := model.db.AddNativeBalancesNearestInTime(revalued, date)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var gains int64
+
+	for _, account := range revalued {
+		if account.NativeBalance != nil {
+			balance := balances[account.Id]
+			gains += *account.NativeBalance - balance.nativeBalance
+			balance.nativeBalance = *account.NativeBalance
+		}
+	}
+
+	return gains, nil
+}
+
+// getSubtreeAccounts lists the accounts of nodes and all of their descendants
+func (model *Model) getSubtreeAccounts(accountMap map[string]*types.AccountNode, nodes ...*types.AccountNode) []*types.Account {
+	accounts := make([]*types.Account, 0)
+
+	for _, node := range nodes {
+		accounts = append(accounts, node.Account)
+		accounts = append(accounts, model.getChildren(node.Account.Id, accountMap)...)
+	}
+
+	return accounts
+}
+
+// getReportDates returns the dates of the columns of a report showing
+// balances as of a date. Each period contributes its end date. Without any
+// periods options.Date is used, or defaultDate if that is not set either.
+func (model *Model) getReportDates(org *types.Org, options *types.ReportOptions, defaultDate time.Time) ([]time.Time, error) {
+	if len(options.Periods) == 0 && options.Start == "" && options.End == "" {
+		if options.Date != 0 {
+			return []time.Time{util.MsToTime(options.Date)}, nil
+		}
+
+		return []time.Time{defaultDate}, nil
+	}
+
+	periods, err := model.getReportPeriods(org, options, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]time.Time, len(periods))
+
+	for i, period := range periods {
+		dates[i] = period.End
+	}
+
+	return dates, nil
+}
+
+// getReportPeriods converts the calendar dates in options to the start and
+// (exclusive) end of each period in the org's timezone. If an interval is
+// given each period is split into consecutive periods of that length.
+func (model *Model) getReportPeriods(org *types.Org, options *types.ReportOptions, requireStart bool) ([]*types.ReportPeriod, error) {
+	location, err := time.LoadLocation(org.Timezone)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dateRanges := options.Periods
+
+	if len(dateRanges) == 0 {
+		dateRanges = []*types.DateRange{&types.DateRange{Start: options.Start, End: options.End}}
+	}
+
+	periods := make([]*types.ReportPeriod, 0, len(dateRanges))
+
+	for _, dateRange := range dateRanges {
+		period, err := getReportPeriod(dateRange, location, requireStart || options.Interval != "")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if options.Interval == "" {
+			periods = append(periods, period)
+		} else {
+			for i := 0; len(periods) <= maxReportPeriods; i++ {
+				start, err := addReportInterval(period.Start, options.Interval, i)
+
+				if err != nil {
+					return nil, err
+				}
+
+				if !start.Before(period.End) {
+					break
+				}
+
+				end, _ := addReportInterval(period.Start, options.Interval, i+1)
+
+				if end.After(period.End) {
+					end = period.End
+				}
+
+				periods = append(periods, &types.ReportPeriod{Start: start, End: end})
+			}
+		}
+
+		if len(periods) > maxReportPeriods {
+			return nil, errors.New(fmt.Sprintf("reports are limited to %d periods", maxReportPeriods))
+		}
+	}
+
+	return periods, nil
+}
+
+func getReportPeriod(dateRange *types.DateRange, location *time.Location, requireStart bool) (*types.ReportPeriod, error) {
+	if dateRange.End == "" || (requireStart && dateRange.Start == "") {
+		return nil, errors.New("start and end required")
+	}
+
+	period := &types.ReportPeriod{}
+
+	var err error
+
+	if dateRange.Start != "" {
+		period.Start, err = time.ParseInLocation(reportDateFormat, dateRange.Start, location)
+
+		if err != nil {
+			return nil, errors.New("invalid start")
+		}
+	}
+
+	period.End, err = time.ParseInLocation(reportDateFormat, dateRange.End, location)
+
+	if err != nil {
+		return nil, errors.New("invalid end")
+	}
+
+	// end date is inclusive
+	period.End = period.End.AddDate(0, 0, 1)
+
+	if !period.Start.Before(period.End) {
+		return nil, errors.New("start must not be after end")
+	}
+
+	return period, nil
+}
+
+// getFiscalYearStart returns the start of the org's fiscal year containing date
+func getFiscalYearStart(org *types.Org, date time.Time, location *time.Location) time.Time {
+	month := getFiscalYearStartMonth(org)
+	localDate := date.In(location)
+	year := localDate.Year()
+
+	if localDate.Month() < month {
+		year--
+	}
+
+	return time.Date(year, month, 1, 0, 0, 0, 0, location)
+}
+
+// getFiscalYearStartMonth treats an unset fiscal year start as January
+func getFiscalYearStartMonth(org *types.Org) time.Month {
+	month := time.Month(org.FiscalYearStart)
+
+	if month < time.January || month > time.December {
+		return time.January
+	}
+
+	return month
+}
+
+// addReportInterval adds n intervals to start. Months are added to the original
+// start date rather than one at a time so that month ends do not drift.
+func addReportInterval(start time.Time, interval string, n int) (time.Time, error) {
+	switch interval {
+	case "day":
+		return start.AddDate(0, 0, n), nil
+	case "week":
+		return start.AddDate(0, 0, 7*n), nil
+	case "month":
+		return start.AddDate(0, n, 0), nil
+	case "quarter":
+		return start.AddDate(0, 3*n, 0), nil
+	case "year":
+		return start.AddDate(n, 0, 0), nil
+	default:
+		return time.Time{}, errors.New("invalid interval")
+	}
+}
+
+// getPeriodBalances sums the splits of accounts between each of times in a
+// single query
+func (model *Model) getPeriodBalances(accounts []*types.Account, times []time.Time) (*periodBalances, error) {
+	boundaries := getBoundaries(times)
+
+	balances, err := model.db.GetPeriodBalances(accounts, boundaries)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &periodBalances{boundaries: boundaries, balances: balances}, nil
+}
+
+// getBoundaries sorts times and removes duplicates and zero times
+func getBoundaries(times []time.Time) []time.Time {
+	boundaries := make([]time.Time, 0, len(times))
+
+	for _, t := range times {
+		if !t.IsZero() {
+			boundaries = append(boundaries, t)
+		}
+	}
+
+	sort.Slice(boundaries, func(i, j int) bool {
+		return boundaries[i].Before(boundaries[j])
+	})
+
+	unique := boundaries[:0]
+
+	for i, boundary := range boundaries {
+		if i == 0 || !boundary.Equal(boundaries[i-1]) {
+			unique = append(unique, boundary)
+		}
+	}
+
+	return unique
+}
+
+// between sums each account's splits from start up to end. Both must be
+// boundaries. A zero start includes everything before end.
+func (pb *periodBalances) between(start time.Time, end time.Time) map[string]*reportBalance {
+	totals := make(map[string]*reportBalance)
+
+	// nothing comes before the beginning of time
+	if end.IsZero() {
+		return totals
+	}
+
+	first := 0
+
+	if !start.IsZero() {
+		first = pb.index(start) + 1
+	}
+
+	last := pb.index(end)
+
+	for id, buckets := range pb.balances {
+		total := &reportBalance{}
+
+		for i := first; i <= last && i < len(buckets); i++ {
+			total.balance += buckets[i].Balance
+			total.nativeBalance += buckets[i].NativeBalance
+		}
+
+		totals[id] = total
+	}
+
+	return totals
+}
+
+func (pb *periodBalances) asOf(date time.Time) map[string]*reportBalance {
+	return pb.between(time.Time{}, date)
+}
+
+func (pb *periodBalances) index(t time.Time) int {
+	return sort.Search(len(pb.boundaries), func(i int) bool {
+		return !pb.boundaries[i].Before(t)
+	})
+}
+
+// makeReportSection lists the account of node and all of its descendants with
+// one amount per column. Amounts are multiplied by sign so that credit balance
+// sections can be shown as positive numbers.
+func (model *Model) makeReportSection(node *types.AccountNode, columns []map[string]*reportBalance, sign int64) *types.ReportSection {
+	section := &types.ReportSection{
+		Lines:  make([]*types.ReportLine, 0),
+		Totals: make([]int64, len(columns)),
+	}
+
+	model.walkAccountNodes([]*types.AccountNode{node}, 0, func(account *types.Account, depth int) {
+		line := &types.ReportLine{
+			AccountId:     account.Id,
+			Name:          account.Name,
+			Parent:        account.Parent,
+			Depth:         depth,
+			Currency:      account.Currency,
+			Precision:     account.Precision,
+			DebitBalance:  account.DebitBalance,
+			Amounts:       make([]int64, len(columns)),
+			NativeAmounts: make([]int64, len(columns)),
+		}
+
+		for i, totals := range columns {
+			line.Amounts[i] = sign * totals[account.Id].balance
+			line.NativeAmounts[i] = sign * totals[account.Id].nativeBalance
+		}
+
+		section.Lines = append(section.Lines, line)
+	})
+
+	for i, totals := range columns {
+		section.Totals[i] = sign * totals[node.Account.Id].nativeBalance
+	}
+
+	return section
+}
+
+// addComputedReportLine appends a line that is not backed by an account, such
+// as retained earnings, to section and includes it in the section's totals.
+func (model *Model) addComputedReportLine(section *types.ReportSection, org *types.Org, name string, nativeAmounts []int64) {
+	top := section.Lines[0]
+
+	section.Lines = append(section.Lines, &types.ReportLine{
+		Name:          name,
+		Parent:        top.AccountId,
+		Depth:         1,
+		Currency:      org.Currency,
+		Precision:     org.Precision,
+		DebitBalance:  top.DebitBalance,
+		Amounts:       nativeAmounts,
+		NativeAmounts: nativeAmounts,
+	})
+
+	for i, nativeAmount := range nativeAmounts {
+		if top.Currency == org.Currency {
+			top.Amounts[i] += nativeAmount
+		}
+
+		top.NativeAmounts[i] += nativeAmount
+		section.Totals[i] += nativeAmount
+	}
+}
+
+// getTopLevelAccountByName finds a child of the root account such as the
+// Assets, Liabilities, Equity, Income and Expenses accounts created with the org.
+func (model *Model) getTopLevelAccountByName(accountMap map[string]*types.AccountNode, name string) *types.AccountNode {
+	for _, node := range accountMap {
+		if node.Parent != nil && node.Parent.Parent == nil && node.Account.Name == name {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// rollUpBalances totals each account's own balance with the balances of all
+// of its descendants. Native balances are always rolled up but balances in the
+// account's own currency only include descendants sharing that currency.
+func (model *Model) rollUpBalances(accounts []*types.Account, accountMap map[string]*types.AccountNode, balances map[string]*reportBalance) map[string]*reportBalance {
+	totals := make(map[string]*reportBalance)
+
+	for _, account := range accounts {
+		totals[account.Id] = &reportBalance{}
+	}
+
+	for _, account := range accounts {
+		balance, ok := balances[account.Id]
+
+		if !ok {
+			continue
+		}
+
+		for node := accountMap[account.Id]; node != nil; node = node.Parent {
+			total := totals[node.Account.Id]
+
+			if node.Account.Currency == account.Currency {
+				total.balance += balance.balance
+			}
+
+			total.nativeBalance += balance.nativeBalance
+		}
+	}
+
+	return totals
+}
+
+// walkAccounts visits every account depth first so that parents come before
+// their children. Siblings are visited in name order.
+func (model *Model) walkAccounts(accountMap map[string]*types.AccountNode, visit func(*types.Account, int)) {
+	roots := make([]*types.AccountNode, 0)
+
+	for _, node := range accountMap {
+		if node.Parent == nil {
+			roots = append(roots, node)
+		}
+	}
+
+	model.walkAccountNodes(roots, 0, visit)
+}
+
+func (model *Model) walkAccountNodes(nodes []*types.AccountNode, depth int, visit func(*types.Account, int)) {
+	sorted := make([]*types.AccountNode, len(nodes))
+	copy(sorted, nodes)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Account.Name < sorted[j].Account.Name
+	})
+
+	for _, node := range sorted {
+		visit(node.Account, depth)
+		model.walkAccountNodes(node.Children, depth+1, visit)
+	}
+}
+
+// debitCredit splits a balance (debits positive, credits negative) into debit
+// and credit columns. Balances on the account's normal side stay in that
+// column and abnormal balances move to the opposite column.
+func debitCredit(debitBalance bool, balance int64) (debit int64, credit int64) {
+	if debitBalance {
+		if balance >= 0 {
+			return balance, 0
+		}
+		return 0, -balance
+	}
+
+	if balance <= 0 {
+		return 0, -balance
+	}
+	return balance, 0
+}
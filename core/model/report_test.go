@@ -0,0 +1,378 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type TdReport struct {
+	db.Datastore
+	permissioned []string
+	splits       []*reportTestSplit
+	queries      int
+}
+
+type reportTestSplit struct {
+	accountId string
+	date      time.Time
+	amount    int64
+}
+
+func (td *TdReport) GetOrg(orgId string, userId string) (*types.Org, error) {
+	return &types.Org{Id: orgId, Currency: "USD", Precision: 2, Timezone: "America/New_York"}, nil
+}
+
+func (td *TdReport) GetClosings(orgId string) ([]*types.Closing, error) {
+	return []*types.Closing{}, nil
+}
+
+func (td *TdReport) GetPermissionedAccountIds(orgId string, userId string, tokenId string) ([]string, error) {
+	return td.permissioned, nil
+}
+
+func (td *TdReport) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
+	return getReportTestAccounts(), nil
+}
+
+func (td *TdReport) GetPeriodBalances(accounts []*types.Account, boundaries []time.Time) (map[string][]*types.PeriodBalance, error) {
+	td.queries++
+
+	balances := make(map[string][]*types.PeriodBalance)
+
+	for _, account := range accounts {
+		for _, split := range td.splits {
+			if split.accountId != account.Id {
+				continue
+			}
+
+			bucket := sort.Search(len(boundaries), func(i int) bool {
+				return split.date.Before(boundaries[i])
+			})
+
+			if bucket == len(boundaries) {
+				continue
+			}
+
+			if balances[account.Id] == nil {
+				balances[account.Id] = make([]*types.PeriodBalance, len(boundaries))
+
+				for i := range boundaries {
+					balances[account.Id][i] = &types.PeriodBalance{}
+				}
+			}
+
+			balances[account.Id][bucket].Balance += split.amount
+			balances[account.Id][bucket].NativeBalance += split.amount
+		}
+	}
+
+	return balances, nil
+}
+
+func (td *TdReport) AddNativeBalancesNearestInTime(accounts []*types.Account, date time.Time) error {
+	// Savings went up in value
+	for _, account := range accounts {
+		if account.Id == "8" {
+			nativeBalance := *account.Balance - 200
+			account.NativeBalance = &nativeBalance
+		}
+	}
+
+	return nil
+}
+
+func getReportTestAccounts() []*types.Account {
+	return []*types.Account{
+		&types.Account{Id: "1", Name: "Root", Parent: "", Currency: "USD", DebitBalance: true},
+		&types.Account{Id: "2", Name: "Assets", Parent: "1", Currency: "USD", DebitBalance: true},
+		&types.Account{Id: "3", Name: "Liabilities", Parent: "1", Currency: "USD", DebitBalance: false},
+		&types.Account{Id: "4", Name: "Equity", Parent: "1", Currency: "USD", DebitBalance: false},
+		&types.Account{Id: "5", Name: "Income", Parent: "1", Currency: "USD", DebitBalance: false},
+		&types.Account{Id: "6", Name: "Expenses", Parent: "1", Currency: "USD", DebitBalance: true},
+		&types.Account{Id: "7", Name: "Checking", Parent: "2", Currency: "USD", DebitBalance: true},
+		&types.Account{Id: "8", Name: "Savings", Parent: "2", Currency: "USD", DebitBalance: true},
+		&types.Account{Id: "9", Name: "Credit Card", Parent: "3", Currency: "USD", DebitBalance: false},
+		&types.Account{Id: "10", Name: "Salary", Parent: "5", Currency: "USD", DebitBalance: false},
+		&types.Account{Id: "11", Name: "Groceries", Parent: "6", Currency: "USD", DebitBalance: true},
+	}
+}
+
+func getReportTestSplits() []*reportTestSplit {
+	location, _ := time.LoadLocation("America/New_York")
+	lastYear := time.Date(2017, time.December, 15, 0, 0, 0, 0, location)
+	thisYear := time.Date(2018, time.March, 15, 0, 0, 0, 0, location)
+
+	return []*reportTestSplit{
+		&reportTestSplit{"7", lastYear, 2000},
+		&reportTestSplit{"10", lastYear, -3000},
+		&reportTestSplit{"11", lastYear, 1000},
+		&reportTestSplit{"7", thisYear, 3000},
+		&reportTestSplit{"8", thisYear, -1000},
+		&reportTestSplit{"9", thisYear, -500},
+		&reportTestSplit{"10", thisYear, -2000},
+		&reportTestSplit{"11", thisYear, 500},
+	}
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+	model := NewModel(td, nil, types.Config{})
+
+	trialBalance, err := model.GetTrialBalance("1", "1", &types.ReportOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 11, len(trialBalance.Lines))
+	assert.Equal(t, 1, len(trialBalance.Dates))
+
+	lines := make(map[string]*types.TrialBalanceColumn)
+
+	for _, line := range trialBalance.Lines {
+		lines[line.AccountId] = line.Columns[0]
+	}
+
+	// tree order with siblings sorted by name
+	assert.Equal(t, "Root", trialBalance.Lines[0].Name)
+	assert.Equal(t, "Assets", trialBalance.Lines[1].Name)
+	assert.Equal(t, "Checking", trialBalance.Lines[2].Name)
+	assert.Equal(t, 2, trialBalance.Lines[2].Depth)
+
+	// children roll up into parents
+	assert.Equal(t, int64(4000), lines["2"].Debit)
+	assert.Equal(t, false, trialBalance.Lines[1].Leaf)
+	assert.Equal(t, int64(0), lines["1"].Debit)
+	assert.Equal(t, int64(0), lines["1"].Credit)
+
+	// abnormal balances move to the other column
+	assert.Equal(t, int64(1000), lines["8"].Credit)
+	assert.Equal(t, int64(500), lines["9"].Credit)
+	assert.Equal(t, int64(5000), lines["10"].NativeCredit)
+	assert.Equal(t, int64(1500), lines["11"].NativeDebit)
+
+	assert.Equal(t, []int64{6500}, trialBalance.NativeDebitTotals)
+	assert.Equal(t, []int64{6500}, trialBalance.NativeCreditTotals)
+}
+
+func TestGetTrialBalanceRestricted(t *testing.T) {
+	// User only has access to Assets
+	td := &TdReport{permissioned: []string{"2"}, splits: getReportTestSplits()}
+	model := NewModel(td, nil, types.Config{})
+
+	trialBalance, err := model.GetTrialBalance("1", "1", &types.ReportOptions{})
+
+	assert.Nil(t, err)
+
+	for _, line := range trialBalance.Lines {
+		assert.NotEqual(t, "Credit Card", line.Name)
+		assert.NotEqual(t, "Salary", line.Name)
+		assert.NotEqual(t, "Groceries", line.Name)
+
+		if line.Name == "Root" {
+			assert.Equal(t, int64(4000), line.Columns[0].Debit)
+		}
+	}
+
+	assert.Equal(t, []int64{5000}, trialBalance.NativeDebitTotals)
+	assert.Equal(t, []int64{1000}, trialBalance.NativeCreditTotals)
+}
+
+func TestGetTrialBalancePeriods(t *testing.T) {
+	td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+	model := NewModel(td, nil, types.Config{})
+
+	trialBalance, err := model.GetTrialBalance("1", "1", &types.ReportOptions{
+		Periods: []*types.DateRange{
+			&types.DateRange{End: "2017-12-31"},
+			&types.DateRange{End: "2018-12-31"},
+		},
+	})
+
+	assert.Nil(t, err)
+
+	// balances as of the end of each period
+	location, _ := time.LoadLocation("America/New_York")
+	assert.True(t, time.Date(2018, time.January, 1, 0, 0, 0, 0, location).Equal(trialBalance.Dates[0]))
+	assert.True(t, time.Date(2019, time.January, 1, 0, 0, 0, 0, location).Equal(trialBalance.Dates[1]))
+
+	assert.Equal(t, []int64{3000, 6500}, trialBalance.NativeDebitTotals)
+	assert.Equal(t, []int64{3000, 6500}, trialBalance.NativeCreditTotals)
+	assert.Equal(t, 1, td.queries)
+}
+
+func TestGetIncomeStatement(t *testing.T) {
+	td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+	model := NewModel(td, nil, types.Config{})
+
+	incomeStatement, err := model.GetIncomeStatement("1", "1", &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31"})
+
+	assert.Nil(t, err)
+
+	// period boundaries are in the org's timezone
+	location, _ := time.LoadLocation("America/New_York")
+	assert.True(t, time.Date(2018, time.January, 1, 0, 0, 0, 0, location).Equal(incomeStatement.Periods[0].Start))
+	assert.True(t, time.Date(2019, time.January, 1, 0, 0, 0, 0, location).Equal(incomeStatement.Periods[0].End))
+
+	assert.Equal(t, 2, len(incomeStatement.Income.Lines))
+	assert.Equal(t, "Income", incomeStatement.Income.Lines[0].Name)
+	assert.Equal(t, []int64{2000}, incomeStatement.Income.Lines[1].Amounts)
+	assert.Equal(t, 1, incomeStatement.Income.Lines[1].Depth)
+	assert.Equal(t, []int64{2000}, incomeStatement.Income.Totals)
+	assert.Equal(t, []int64{500}, incomeStatement.Expenses.Totals)
+	assert.Equal(t, []int64{1500}, incomeStatement.NetIncome)
+}
+
+func TestGetIncomeStatementPeriods(t *testing.T) {
+	tests := map[string]struct {
+		options   *types.ReportOptions
+		income    []int64
+		netIncome []int64
+	}{
+		"monthly": {
+			options:   &types.ReportOptions{Start: "2018-01-01", End: "2018-06-30", Interval: "month"},
+			income:    []int64{0, 0, 2000, 0, 0, 0},
+			netIncome: []int64{0, 0, 1500, 0, 0, 0},
+		},
+		"quarterly": {
+			options:   &types.ReportOptions{Start: "2017-10-01", End: "2018-06-30", Interval: "quarter"},
+			income:    []int64{3000, 2000, 0},
+			netIncome: []int64{2000, 1500, 0},
+		},
+		"this year vs last year": {
+			options: &types.ReportOptions{
+				Periods: []*types.DateRange{
+					&types.DateRange{Start: "2018-01-01", End: "2018-12-31"},
+					&types.DateRange{Start: "2017-01-01", End: "2017-12-31"},
+				},
+			},
+			income:    []int64{2000, 3000},
+			netIncome: []int64{1500, 2000},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+		model := NewModel(td, nil, types.Config{})
+
+		incomeStatement, err := model.GetIncomeStatement("1", "1", test.options)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(test.income), len(incomeStatement.Periods))
+		assert.Equal(t, test.income, incomeStatement.Income.Totals)
+		assert.Equal(t, test.netIncome, incomeStatement.NetIncome)
+
+		// every column comes from a single grouped query
+		assert.Equal(t, 1, td.queries)
+	}
+}
+
+func TestGetIncomeStatementErrors(t *testing.T) {
+	tests := map[string]struct {
+		err     error
+		options *types.ReportOptions
+	}{
+		"missing dates": {
+			err:     errors.New("start and end required"),
+			options: &types.ReportOptions{Start: "2018-01-01"},
+		},
+		"bad date": {
+			err:     errors.New("invalid end"),
+			options: &types.ReportOptions{Start: "2018-01-01", End: "12/31/2018"},
+		},
+		"reversed dates": {
+			err:     errors.New("start must not be after end"),
+			options: &types.ReportOptions{Start: "2018-02-01", End: "2018-01-01"},
+		},
+		"bad interval": {
+			err:     errors.New("invalid interval"),
+			options: &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31", Interval: "fortnight"},
+		},
+		"too many periods": {
+			err:     errors.New("reports are limited to 120 periods"),
+			options: &types.ReportOptions{Start: "2018-01-01", End: "2018-12-31", Interval: "day"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+		model := NewModel(td, nil, types.Config{})
+
+		_, err := model.GetIncomeStatement("1", "1", test.options)
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestGetBalanceSheet(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+	date := util.TimeToMs(time.Date(2018, time.June, 1, 0, 0, 0, 0, location))
+
+	for _, valuation := range []string{"", "market"} {
+		t.Logf("Running test case: %s", valuation)
+
+		td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+		model := NewModel(td, nil, types.Config{})
+
+		balanceSheet, err := model.GetBalanceSheet("1", "1", &types.ReportOptions{Date: date}, valuation)
+
+		assert.Nil(t, err)
+
+		equity := make(map[string]*types.ReportLine)
+
+		for _, line := range balanceSheet.Equity.Lines {
+			equity[line.Name] = line
+		}
+
+		assert.Equal(t, []int64{2000}, equity["Retained Earnings"].NativeAmounts)
+		assert.Equal(t, []int64{1500}, equity["Current Year Earnings"].NativeAmounts)
+		assert.Equal(t, "", equity["Retained Earnings"].AccountId)
+		assert.Equal(t, []int64{500}, balanceSheet.Liabilities.Totals)
+
+		if valuation == "market" {
+			assert.Equal(t, []int64{-200}, equity["Unrealized Gains"].NativeAmounts)
+			assert.Equal(t, []int64{3800}, balanceSheet.Assets.Totals)
+		} else {
+			assert.Nil(t, equity["Unrealized Gains"])
+			assert.Equal(t, []int64{4000}, balanceSheet.Assets.Totals)
+		}
+
+		// report balances
+		assert.Equal(t, balanceSheet.Assets.Totals, balanceSheet.TotalLiabilitiesAndEquity)
+		assert.Equal(t, balanceSheet.Equity.Totals, equity["Equity"].NativeAmounts)
+	}
+}
+
+func TestGetBalanceSheetPeriods(t *testing.T) {
+	td := &TdReport{permissioned: []string{"1"}, splits: getReportTestSplits()}
+	model := NewModel(td, nil, types.Config{})
+
+	balanceSheet, err := model.GetBalanceSheet("1", "1", &types.ReportOptions{
+		Periods: []*types.DateRange{
+			&types.DateRange{End: "2017-12-31"},
+			&types.DateRange{End: "2018-12-31"},
+		},
+	}, "")
+
+	assert.Nil(t, err)
+
+	equity := make(map[string]*types.ReportLine)
+
+	for _, line := range balanceSheet.Equity.Lines {
+		equity[line.Name] = line
+	}
+
+	// 2017 earnings are current at the end of 2017 and retained after
+	assert.Equal(t, []int64{0, 2000}, equity["Retained Earnings"].NativeAmounts)
+	assert.Equal(t, []int64{2000, 1500}, equity["Current Year Earnings"].NativeAmounts)
+	assert.Equal(t, []int64{2000, 4000}, balanceSheet.Assets.Totals)
+	assert.Equal(t, balanceSheet.Assets.Totals, balanceSheet.TotalLiabilitiesAndEquity)
+	assert.Equal(t, 1, td.queries)
+}
@@ -0,0 +1,388 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"regexp"
+)
+
+type RuleInterface interface {
+	GetRules(string, string) ([]*types.Rule, error)
+	GetRule(string, string, string) (*types.Rule, error)
+	CreateRule(*types.Rule, string) error
+	UpdateRule(*types.Rule, string) error
+	DeleteRule(string, string, string) error
+	ApplyRules(string, string, string) (*types.RuleResult, error)
+}
+
+// compiledRule is a rule with its description pattern ready to match
+type compiledRule struct {
+	rule    *types.Rule
+	pattern *regexp.Regexp
+}
+
+// GetRules returns the rules of an org that target accounts the user has
+// access to in the order they are applied
+func (model *Model) GetRules(orgId string, userId string) ([]*types.Rule, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := model.db.GetRules(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*types.Rule, 0)
+
+	for _, rule := range rules {
+		if model.accountsContainWriteAccess(userAccounts, rule.AccountId) {
+			filtered = append(filtered, rule)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (model *Model) GetRule(orgId string, id string, userId string) (*types.Rule, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	rule, err := model.db.GetRule(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.OrgId != orgId {
+		return nil, errors.New("Rule not found")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, rule.AccountId) {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", rule.AccountId))
+	}
+
+	return rule, nil
+}
+
+func (model *Model) CreateRule(rule *types.Rule, userId string) error {
+	err := model.checkRule(rule, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertRule(rule)
+}
+
+func (model *Model) UpdateRule(rule *types.Rule, userId string) error {
+	// GetRule checks that the user can access the current account
+	original, err := model.GetRule(rule.OrgId, rule.Id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkRule(rule, userId)
+
+	if err != nil {
+		return err
+	}
+
+	rule.Inserted = original.Inserted
+
+	return model.db.UpdateRule(rule)
+}
+
+func (model *Model) DeleteRule(orgId string, id string, userId string) error {
+	// GetRule checks that the rule belongs to the org
+	_, err := model.GetRule(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.DeleteRule(id)
+}
+
+// ApplyRules recategorizes the transactions that have one split in account
+// accountId, such as a suspense account, and one other split. The first rule
+// that matches the description and the amount of the other split replaces
+// accountId and, if it has a payee, the description.
+func (model *Model) ApplyRules(orgId string, accountId string, userId string) (*types.RuleResult, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, accountId) {
+		return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", accountId))
+	}
+
+	rules, err := model.getCompiledRules(orgId, userAccounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := model.db.GetTransactionsByAccount(accountId, &types.QueryOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.RuleResult{
+		Transactions: make([]*types.Transaction, 0),
+		Errors:       make([]*types.TransactionError, 0),
+	}
+
+	matched := 0
+
+	for _, transaction := range transactions {
+		if len(transaction.Splits) != 2 || transaction.Splits[0].AccountId == transaction.Splits[1].AccountId {
+			continue
+		}
+
+		source := transaction.Splits[0]
+
+		if source.AccountId == accountId {
+			source = transaction.Splits[1]
+		}
+
+		rule := matchRule(rules, transaction.Description, source.Amount, accountId)
+
+		if rule == nil {
+			continue
+		}
+
+		categorized, err := getCategorizedTransaction(orgId, userId, transaction, accountId, rule)
+
+		if err == nil {
+			err = model.UpdateTransaction(transaction.Id, categorized)
+		}
+
+		if err != nil {
+			result.Errors = append(result.Errors, &types.TransactionError{
+				Index: matched,
+				Id:    transaction.Id,
+				Error: err.Error(),
+			})
+		} else {
+			result.Transactions = append(result.Transactions, categorized)
+		}
+
+		matched++
+	}
+
+	return result, nil
+}
+
+func (model *Model) checkRule(rule *types.Rule, userId string) error {
+	if rule.Id == "" {
+		return errors.New("id required")
+	}
+
+	if rule.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if rule.Name == "" {
+		return errors.New("name required")
+	}
+
+	if rule.AccountId == "" {
+		return errors.New("accountId required")
+	}
+
+	_, err := compileRulePattern(rule.DescriptionPattern)
+
+	if err != nil {
+		return err
+	}
+
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return errors.New("minAmount must not be greater than maxAmount")
+	}
+
+	org, err := model.GetOrg(rule.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	userAccounts, err := model.GetAccounts(rule.OrgId, userId, "")
+
+	if err != nil {
+		return err
+	}
+
+	if !model.accountsContainWriteAccess(userAccounts, rule.AccountId) {
+		return errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", rule.AccountId))
+	}
+
+	account := model.getAccountFromList(userAccounts, rule.AccountId)
+
+	if account.HasChildren == true {
+		return errors.New("Cannot use parent account for split")
+	}
+
+	// rules categorize imported splits, which are in the org currency
+	if account.Currency != org.Currency {
+		return errors.New("rules must use accounts in the org currency")
+	}
+
+	return nil
+}
+
+// getCompiledRules returns the rules of an org that target accounts in
+// userAccounts the user can write to
+func (model *Model) getCompiledRules(orgId string, userAccounts []*types.Account) ([]*compiledRule, error) {
+	rules, err := model.db.GetRules(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := make([]*compiledRule, 0)
+
+	for _, rule := range rules {
+		if !model.accountsContainWriteAccess(userAccounts, rule.AccountId) {
+			continue
+		}
+
+		pattern, err := compileRulePattern(rule.DescriptionPattern)
+
+		if err != nil {
+			return nil, err
+		}
+
+		compiled = append(compiled, &compiledRule{rule, pattern})
+	}
+
+	return compiled, nil
+}
+
+// compileRulePattern compiles a case insensitive description pattern. An
+// empty pattern matches every description.
+func compileRulePattern(pattern string) (*regexp.Regexp, error) {
+	compiled, err := regexp.Compile("(?i)" + pattern)
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("invalid descriptionPattern: %s", err.Error()))
+	}
+
+	return compiled, nil
+}
+
+// matchRule returns the first rule that matches description and amount and
+// does not target excludeAccountId, or nil if none does
+func matchRule(rules []*compiledRule, description string, amount int64, excludeAccountId string) *types.Rule {
+	for _, r := range rules {
+		if r.rule.AccountId == excludeAccountId {
+			continue
+		}
+
+		if r.rule.MinAmount != nil && amount < *r.rule.MinAmount {
+			continue
+		}
+
+		if r.rule.MaxAmount != nil && amount > *r.rule.MaxAmount {
+			continue
+		}
+
+		if r.pattern.MatchString(description) {
+			return r.rule
+		}
+	}
+
+	return nil
+}
+
+// getCategorizedTransaction returns a copy of transaction under a new id with
+// the split for accountId moved to the account of rule
+func getCategorizedTransaction(orgId string, userId string, transaction *types.Transaction, accountId string, rule *types.Rule) (*types.Transaction, error) {
+	id, err := util.NewGuid()
+
+	if err != nil {
+		return nil, err
+	}
+
+	categorized := &types.Transaction{
+		Id:              id,
+		OrgId:           orgId,
+		UserId:          userId,
+		Date:            transaction.Date,
+		Description:     transaction.Description,
+		ContactId:       transaction.ContactId,
+		Tags:            transaction.Tags,
+		Data:            transaction.Data,
+		AutoReverseDate: transaction.AutoReverseDate,
+		Splits:          make([]*types.Split, len(transaction.Splits)),
+	}
+
+	if rule.Payee != "" {
+		categorized.Description = rule.Payee
+	}
+
+	for i, split := range transaction.Splits {
+		categorized.Splits[i] = &types.Split{
+			TransactionId: id,
+			AccountId:     split.AccountId,
+			Amount:        split.Amount,
+			NativeAmount:  split.NativeAmount,
+			ExternalId:    split.ExternalId,
+			Status:        split.Status,
+			Memo:          split.Memo,
+			Quantity:      split.Quantity,
+			UnitPrice:     split.UnitPrice,
+			Dimensions:    split.Dimensions,
+		}
+
+		// the rule account is in the org currency
+		if split.AccountId == accountId {
+			categorized.Splits[i].AccountId = rule.AccountId
+			categorized.Splits[i].Amount = split.NativeAmount
+		}
+	}
+
+	return categorized, nil
+}
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdRule struct {
+	*TdImport
+	transactions []*types.Transaction
+	updated      map[string]*types.Transaction
+}
+
+func (td *TdRule) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "2"}}, nil
+}
+
+func (td *TdRule) InsertRule(rule *types.Rule) error {
+	td.rules = append(td.rules, rule)
+	return nil
+}
+
+func (td *TdRule) GetTransactionsByAccount(accountId string, options *types.QueryOptions) ([]*types.Transaction, error) {
+	return td.transactions, nil
+}
+
+func (td *TdRule) DeleteAndInsertTransaction(oldId string, transaction *types.Transaction) error {
+	td.updated[oldId] = transaction
+	return nil
+}
+
+func ruleAmount(amount int64) *int64 {
+	return &amount
+}
+
+func TestCreateRule(t *testing.T) {
+	tests := map[string]struct {
+		rule *types.Rule
+		err  error
+	}{
+		"successful": {
+			rule: &types.Rule{Id: "5", OrgId: "2", Name: "groceries", DescriptionPattern: "corner store|bakery", AccountId: "2"},
+			err:  nil,
+		},
+		"invalid pattern": {
+			rule: &types.Rule{Id: "5", OrgId: "2", Name: "groceries", DescriptionPattern: "corner (store", AccountId: "2"},
+			err:  errors.New("invalid descriptionPattern: error parsing regexp: missing closing ): `(?i)corner (store`"),
+		},
+		"amount range": {
+			rule: &types.Rule{Id: "5", OrgId: "2", Name: "groceries", MinAmount: ruleAmount(-100), MaxAmount: ruleAmount(-200), AccountId: "2"},
+			err:  errors.New("minAmount must not be greater than maxAmount"),
+		},
+		"no access": {
+			rule: &types.Rule{Id: "5", OrgId: "2", Name: "groceries", AccountId: "5"},
+			err:  errors.New("user does not have permission to access account 5"),
+		},
+		"foreign currency": {
+			rule: &types.Rule{Id: "5", OrgId: "2", Name: "travel", AccountId: "4"},
+			err:  errors.New("rules must use accounts in the org currency"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdRule{TdImport: &TdImport{TdTransaction: &TdTransaction{}}}
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.CreateRule(test.rule, "3")
+
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	td := &TdRule{
+		TdImport: &TdImport{
+			TdTransaction: &TdTransaction{},
+			rules: []*types.Rule{
+				&types.Rule{Id: "5", Priority: 1, DescriptionPattern: "^corner store", MaxAmount: ruleAmount(-1), AccountId: "2", Payee: "Corner Store"},
+				&types.Rule{Id: "6", Priority: 2, DescriptionPattern: "refund", AccountId: "5"},
+			},
+		},
+		updated: make(map[string]*types.Transaction),
+	}
+
+	model := NewModel(td, nil, types.Config{})
+
+	date := time.Date(2018, time.February, 3, 0, 0, 0, 0, time.UTC)
+
+	td.transactions = []*types.Transaction{
+		&types.Transaction{
+			Id:          "7",
+			Date:        date,
+			Description: "CORNER STORE #12",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "7", AccountId: "1", Amount: -1250, NativeAmount: -1250, ExternalId: "A1"},
+				&types.Split{TransactionId: "7", AccountId: "3", Amount: 1250, NativeAmount: 1250},
+			},
+		},
+		&types.Transaction{
+			Id:          "8",
+			Date:        date,
+			Description: "CORNER STORE #12 REFUND",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "8", AccountId: "1", Amount: 1250, NativeAmount: 1250, ExternalId: "A2"},
+				&types.Split{TransactionId: "8", AccountId: "3", Amount: -1250, NativeAmount: -1250},
+			},
+		},
+		&types.Transaction{
+			Id:          "9",
+			Date:        time.Date(2018, time.January, 3, 0, 0, 0, 0, time.UTC),
+			Description: "Corner store",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "9", AccountId: "1", Amount: -1250, NativeAmount: -1250, ExternalId: "A0"},
+				&types.Split{TransactionId: "9", AccountId: "3", Amount: 1250, NativeAmount: 1250},
+			},
+		},
+	}
+
+	td.On("GetTransactionById", "7").Return(td.transactions[0], nil)
+	td.On("GetTransactionById", "9").Return(td.transactions[2], nil)
+	td.On("GetReversals", "7").Return([]*types.Transaction{}, nil)
+	td.On("GetReversals", "9").Return([]*types.Transaction{}, nil)
+
+	result, err := model.ApplyRules("2", "3", "3")
+
+	assert.Nil(t, err)
+
+	// the refund is an inflow and the only other rule uses an account the user
+	// can't write to
+	assert.Equal(t, 1, len(result.Transactions))
+	assert.Equal(t, td.updated["7"], result.Transactions[0])
+	assert.Equal(t, "Corner Store", result.Transactions[0].Description)
+	assert.Equal(t, []*types.Split{
+		&types.Split{TransactionId: result.Transactions[0].Id, AccountId: "1", Amount: -1250, NativeAmount: -1250, ExternalId: "A1", Status: "uncleared"},
+		&types.Split{TransactionId: result.Transactions[0].Id, AccountId: "2", Amount: 1250, NativeAmount: 1250, Status: "uncleared"},
+	}, result.Transactions[0].Splits)
+
+	// January is closed
+	assert.Equal(t, []*types.TransactionError{
+		&types.TransactionError{Index: 1, Id: "9", Error: "transaction date is in a closed period"},
+	}, result.Errors)
+}
+
+func TestImportCsvRules(t *testing.T) {
+	td := &TdImport{
+		TdTransaction: &TdTransaction{},
+		rules: []*types.Rule{
+			&types.Rule{Id: "5", DescriptionPattern: "corner", AccountId: "2", Payee: "Corner Store"},
+		},
+	}
+
+	model := NewModel(td, nil, types.Config{})
+
+	result, err := model.ImportCsv("2", "1", "3", &types.CsvImport{
+		Data:            "2018-02-03,POS CORNER #12,-12.50,Travel\n2018-02-04,Salary,2000,\n",
+		Mapping:         &types.CsvMapping{DateColumn: 1, DescriptionColumn: 2, AmountColumn: 3, CategoryColumn: 4},
+		OffsetAccountId: "3",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Corner Store", result.Transactions[0].Description)
+	assert.Equal(t, "2", result.Transactions[0].Splits[1].AccountId)
+	assert.Equal(t, "Salary", result.Transactions[1].Description)
+	assert.Equal(t, "3", result.Transactions[1].Splits[1].AccountId)
+}
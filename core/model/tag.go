@@ -0,0 +1,172 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+)
+
+type TagInterface interface {
+	GetTagReport(string, string, *types.ReportOptions) (*types.TagReport, error)
+}
+
+// maxTagLength is the size of the transactiontag.tag column
+const maxTagLength = 100
+
+// GetTagReport sums income and expenses for each tag in each period. A
+// transaction with several tags counts towards each of them. Amounts are in
+// the Org's currency with income and expenses both shown as positive.
+func (model *Model) GetTagReport(orgId string, userId string, options *types.ReportOptions) (*types.TagReport, error) {
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := model.getReportPeriods(org, options, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Only accounts the user has access to are included
+	accounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	accountMap := model.makeAccountMap(accounts)
+	incomeNode := model.getTopLevelAccountByName(accountMap, "Income")
+	expensesNode := model.getTopLevelAccountByName(accountMap, "Expenses")
+
+	if incomeNode == nil || expensesNode == nil {
+		return nil, errors.New("Income and Expenses accounts are required")
+	}
+
+	// income has a credit balance so flip the sign to show it as positive
+	signs := make(map[string]int64)
+	accountIds := make([]string, 0)
+
+	for _, account := range model.getSubtreeAccounts(accountMap, incomeNode) {
+		signs[account.Id] = -1
+		accountIds = append(accountIds, account.Id)
+	}
+
+	for _, account := range model.getSubtreeAccounts(accountMap, expensesNode) {
+		signs[account.Id] = 1
+		accountIds = append(accountIds, account.Id)
+	}
+
+	start := periods[0].Start
+	end := periods[0].End
+
+	for _, period := range periods {
+		if period.Start.Before(start) {
+			start = period.Start
+		}
+
+		if period.End.After(end) {
+			end = period.End
+		}
+	}
+
+	transactions, err := model.db.GetTransactionsByOrg(orgId, &types.QueryOptions{
+		StartDate: int(util.TimeToMs(start)),
+		EndDate:   int(util.TimeToMs(end)),
+	}, accountIds)
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.TagReport{
+		OrgId:   orgId,
+		Periods: periods,
+		Lines:   make([]*types.TagReportLine, 0),
+	}
+
+	// tags are case insensitive
+	lines := make(map[string]*types.TagReportLine)
+
+	for _, transaction := range transactions {
+		for _, tag := range transaction.Tags {
+			line, ok := lines[strings.ToLower(tag)]
+
+			if !ok {
+				line = &types.TagReportLine{
+					Tag:       tag,
+					Income:    make([]int64, len(periods)),
+					Expenses:  make([]int64, len(periods)),
+					NetIncome: make([]int64, len(periods)),
+				}
+
+				lines[strings.ToLower(tag)] = line
+				report.Lines = append(report.Lines, line)
+			}
+
+			addTagReportAmounts(line, transaction, periods, signs)
+		}
+	}
+
+	sort.Slice(report.Lines, func(i, j int) bool {
+		return strings.ToLower(report.Lines[i].Tag) < strings.ToLower(report.Lines[j].Tag)
+	})
+
+	return report, nil
+}
+
+// addTagReportAmounts adds the income and expense splits of transaction to the
+// columns of the periods it falls in
+func addTagReportAmounts(line *types.TagReportLine, transaction *types.Transaction, periods []*types.ReportPeriod, signs map[string]int64) {
+	for i, period := range periods {
+		if transaction.Date.Before(period.Start) || !transaction.Date.Before(period.End) {
+			continue
+		}
+
+		for _, split := range transaction.Splits {
+			switch signs[split.AccountId] {
+			case -1:
+				line.Income[i] -= split.NativeAmount
+				line.NetIncome[i] -= split.NativeAmount
+			case 1:
+				line.Expenses[i] += split.NativeAmount
+				line.NetIncome[i] -= split.NativeAmount
+			}
+		}
+	}
+}
+
+// checkTags trims the tags of a transaction and drops empty and repeated ones.
+// Tags that only differ in case are the same tag.
+func checkTags(transaction *types.Transaction) error {
+	if len(transaction.Tags) == 0 {
+		return nil
+	}
+
+	tags := make([]string, 0, len(transaction.Tags))
+	seen := make(map[string]bool)
+
+	for _, tag := range transaction.Tags {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+
+		if len(tag) > maxTagLength {
+			return errors.New(fmt.Sprintf("tag must be at most %d characters", maxTagLength))
+		}
+
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+
+	transaction.Tags = tags
+
+	return nil
+}
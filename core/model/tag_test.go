@@ -0,0 +1,102 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTagReport(t *testing.T) {
+	location, _ := time.LoadLocation("America/New_York")
+
+	transaction := func(month time.Month, day int, tags []string, splits ...*types.Split) *types.Transaction {
+		return &types.Transaction{
+			Id:     strings.Join(tags, ","),
+			OrgId:  "1",
+			Date:   time.Date(2018, month, day, 0, 0, 0, 0, location),
+			Tags:   tags,
+			Splits: splits,
+		}
+	}
+
+	split := func(accountId string, amount int64) *types.Split {
+		return &types.Split{AccountId: accountId, Amount: amount, NativeAmount: amount}
+	}
+
+	td := &TdCashFlow{
+		TdReport: &TdReport{permissioned: []string{"1"}},
+		transactions: []*types.Transaction{
+			transaction(time.January, 10, []string{"Conference"}, split("7", 1500), split("10", -1500)),
+			transaction(time.January, 20, []string{"conference", "Travel"}, split("7", -800), split("11", 800)),
+			transaction(time.February, 5, []string{}, split("7", -500), split("11", 500)),
+			// transfers have no income or expenses
+			transaction(time.February, 10, []string{"Travel"}, split("7", -1000), split("8", 1000)),
+			transaction(time.February, 15, []string{"Travel"}, split("9", -300), split("11", 300)),
+		},
+	}
+
+	model := NewModel(td, nil, types.Config{})
+
+	report, err := model.GetTagReport("1", "1", &types.ReportOptions{Start: "2018-01-01", End: "2018-02-28", Interval: "month"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(report.Periods))
+	assert.Equal(t, []*types.TagReportLine{
+		&types.TagReportLine{Tag: "Conference", Income: []int64{1500, 0}, Expenses: []int64{800, 0}, NetIncome: []int64{700, 0}},
+		&types.TagReportLine{Tag: "Travel", Income: []int64{0, 0}, Expenses: []int64{800, 300}, NetIncome: []int64{-800, -300}},
+	}, report.Lines)
+}
+
+func TestCreateTransactionTags(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		tags []string
+		want []string
+	}{
+		"no tags": {
+			err:  nil,
+			tags: nil,
+			want: nil,
+		},
+		"cleaned up": {
+			err:  nil,
+			tags: []string{" trip ", "Trip", "", "food"},
+			want: []string{"trip", "food"},
+		},
+		"too long": {
+			err:  errors.New("tag must be at most 100 characters"),
+			tags: []string{strings.Repeat("a", 101)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{}
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:     "1",
+			OrgId:  "2",
+			UserId: "3",
+			Date:   time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+			Tags:   test.tags,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		}
+
+		err := model.CreateTransaction(transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, test.want, transaction.Tags)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
+	"math"
+)
+
+type TemplateInterface interface {
+	GetTemplates(string, string) ([]*types.Template, error)
+	GetTemplate(string, string, string) (*types.Template, error)
+	CreateTemplate(*types.Template, string) error
+	UpdateTemplate(*types.Template, string) error
+	DeleteTemplate(string, string, string) error
+	InstantiateTemplate(string, string, string, *types.TemplateInstance) (*types.Transaction, error)
+}
+
+// percentTolerance allows for floating point error when adding up percents
+const percentTolerance = 0.000001
+
+// GetTemplates returns the templates of an org that only use accounts the user
+// has access to
+func (model *Model) GetTemplates(orgId string, userId string) ([]*types.Template, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := model.db.GetTemplates(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*types.Template, 0)
+
+	for _, template := range templates {
+		allowed := true
+
+		for _, split := range template.Splits {
+			if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
+				allowed = false
+				break
+			}
+		}
+
+		if allowed {
+			filtered = append(filtered, template)
+		}
+	}
+
+	return filtered, nil
+}
+
+func (model *Model) GetTemplate(orgId string, id string, userId string) (*types.Template, error) {
+	belongs, err := model.UserBelongsToOrg(userId, orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if belongs == false {
+		return nil, errors.New("User does not belong to org")
+	}
+
+	template, err := model.db.GetTemplate(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if template.OrgId != orgId {
+		return nil, errors.New("Template not found")
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, split := range template.Splits {
+		if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
+			return nil, errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", split.AccountId))
+		}
+	}
+
+	return template, nil
+}
+
+func (model *Model) CreateTemplate(template *types.Template, userId string) error {
+	err := model.checkTemplate(template, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.InsertTemplate(template)
+}
+
+func (model *Model) UpdateTemplate(template *types.Template, userId string) error {
+	// GetTemplate checks that the user can access the current splits
+	original, err := model.GetTemplate(template.OrgId, template.Id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkTemplate(template, userId)
+
+	if err != nil {
+		return err
+	}
+
+	template.Inserted = original.Inserted
+
+	return model.db.UpdateTemplate(template)
+}
+
+func (model *Model) DeleteTemplate(orgId string, id string, userId string) error {
+	// GetTemplate checks that the template belongs to the org
+	_, err := model.GetTemplate(orgId, id, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return model.db.DeleteTemplate(id)
+}
+
+// InstantiateTemplate creates a transaction from template id for the total
+// amount of instance
+func (model *Model) InstantiateTemplate(orgId string, id string, userId string, instance *types.TemplateInstance) (*types.Transaction, error) {
+	template, err := model.GetTemplate(orgId, id, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &types.Transaction{
+		Id:          instance.Id,
+		OrgId:       orgId,
+		UserId:      userId,
+		Date:        instance.Date,
+		Description: instance.Description,
+		Data:        template.Data,
+		Splits:      getTemplateSplits(template, instance.Amount),
+	}
+
+	if transaction.Description == "" {
+		transaction.Description = template.Description
+	}
+
+	for _, split := range transaction.Splits {
+		split.TransactionId = transaction.Id
+	}
+
+	err = model.CreateTransaction(transaction)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+func (model *Model) checkTemplate(template *types.Template, userId string) error {
+	if template.Id == "" {
+		return errors.New("id required")
+	}
+
+	if template.OrgId == "" {
+		return errors.New("orgId required")
+	}
+
+	if template.Name == "" {
+		return errors.New("name required")
+	}
+
+	if len(template.Splits) < 2 {
+		return errors.New("at least 2 splits are required")
+	}
+
+	org, err := model.GetOrg(template.OrgId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	userAccounts, err := model.GetAccounts(template.OrgId, userId, "")
+
+	if err != nil {
+		return err
+	}
+
+	var amount int64
+	var percent float64
+	balancing := 0
+
+	for _, split := range template.Splits {
+		if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
+			return errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", split.AccountId))
+		}
+
+		account := model.getAccountFromList(userAccounts, split.AccountId)
+
+		if account.HasChildren == true {
+			return errors.New("Cannot use parent account for split")
+		}
+
+		// amounts are in the org currency so they are also the native amounts
+		if account.Currency != org.Currency {
+			return errors.New("template splits must use accounts in the org currency")
+		}
+
+		switch {
+		case split.Amount != nil && split.Percent != nil:
+			return errors.New(fmt.Sprintf("split for account %s cannot have both an amount and a percent", split.AccountId))
+		case split.Amount != nil:
+			amount += *split.Amount
+		case split.Percent != nil:
+			percent += *split.Percent
+		default:
+			balancing++
+		}
+	}
+
+	if balancing > 1 {
+		return errors.New("only one split can be left blank to balance the template")
+	}
+
+	if balancing == 0 && amount != 0 {
+		return errors.New("amounts must add up to 0")
+	}
+
+	if balancing == 0 && math.Abs(percent) > percentTolerance {
+		return errors.New("percents must add up to 0")
+	}
+
+	return nil
+}
+
+// getTemplateSplits returns balanced splits for template and a total amount.
+// Percent splits are rounded to the nearest unit. A split without an amount or
+// percent takes whatever balances the transaction. Otherwise the percent split
+// that rounding moved furthest from its exact value (the first one on a tie)
+// absorbs the difference.
+func getTemplateSplits(template *types.Template, amount int64) []*types.Split {
+	splits := make([]*types.Split, len(template.Splits))
+
+	var sum int64
+	adjust := -1
+	balancing := -1
+	largestError := -1.0
+
+	for i, templateSplit := range template.Splits {
+		var value int64
+
+		switch {
+		case templateSplit.Amount != nil:
+			value = *templateSplit.Amount
+		case templateSplit.Percent != nil:
+			exact := float64(amount) * *templateSplit.Percent / 100
+			value = util.Round64(exact)
+
+			if roundingError := math.Abs(exact - float64(value)); roundingError > largestError {
+				adjust = i
+				largestError = roundingError
+			}
+		default:
+			balancing = i
+		}
+
+		splits[i] = &types.Split{
+			AccountId:    templateSplit.AccountId,
+			Amount:       value,
+			NativeAmount: value,
+		}
+
+		sum += value
+	}
+
+	if balancing != -1 {
+		adjust = balancing
+	}
+
+	if adjust != -1 {
+		splits[adjust].Amount -= sum
+		splits[adjust].NativeAmount -= sum
+	}
+
+	return splits
+}
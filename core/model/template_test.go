@@ -0,0 +1,229 @@
+package model
+
+import (
+	"errors"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type TdTemplate struct {
+	*TdTransaction
+	templates []*types.Template
+}
+
+func (td *TdTemplate) GetOrgs(userId string) ([]*types.Org, error) {
+	return []*types.Org{&types.Org{Id: "2"}}, nil
+}
+
+func (td *TdTemplate) GetPermissionedAccountIds(userId string, orgId string, tokenId string) ([]string, error) {
+	return []string{"1", "2", "3", "4"}, nil
+}
+
+func (td *TdTemplate) GetAccountsByOrgId(orgId string) ([]*types.Account, error) {
+	return []*types.Account{
+		&types.Account{Id: "1", Currency: "USD"},
+		&types.Account{Id: "2", Currency: "USD"},
+		&types.Account{Id: "3", Currency: "USD"},
+		&types.Account{Id: "4", Currency: "EUR"},
+	}, nil
+}
+
+func (td *TdTemplate) GetTemplate(id string) (*types.Template, error) {
+	for _, template := range td.templates {
+		if template.Id == id {
+			return template, nil
+		}
+	}
+
+	return nil, errors.New("Template not found")
+}
+
+func (td *TdTemplate) InsertTemplate(template *types.Template) error {
+	td.templates = append(td.templates, template)
+	return nil
+}
+
+func templateAmount(amount int64) *int64 {
+	return &amount
+}
+
+func templatePercent(percent float64) *float64 {
+	return &percent
+}
+
+func TestGetTemplateSplits(t *testing.T) {
+	tests := map[string]struct {
+		splits  []*types.TemplateSplit
+		amount  int64
+		amounts []int64
+	}{
+		"even percents": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(60)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(40)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+			},
+			amount:  10000,
+			amounts: []int64{6000, 4000, -10000},
+		},
+		"largest rounding error absorbs the difference": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(33.33)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(33.33)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(33.34)},
+				&types.TemplateSplit{AccountId: "4", Percent: templatePercent(-100)},
+			},
+			amount:  100,
+			amounts: []int64{33, 33, 34, -100},
+		},
+		"first split wins a tie": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(50)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(50)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+			},
+			amount:  101,
+			amounts: []int64{50, 51, -101},
+		},
+		"balancing split": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Amount: templateAmount(500)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(33.33)},
+				&types.TemplateSplit{AccountId: "3"},
+			},
+			amount:  1000,
+			amounts: []int64{500, 333, -833},
+		},
+		"negative amount": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(50)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(50)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+			},
+			amount:  -101,
+			amounts: []int64{-50, -51, 101},
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		splits := getTemplateSplits(&types.Template{Splits: test.splits}, test.amount)
+
+		amounts := make([]int64, len(splits))
+
+		for i, split := range splits {
+			assert.Equal(t, split.Amount, split.NativeAmount)
+			amounts[i] = split.Amount
+		}
+
+		assert.Equal(t, test.amounts, amounts)
+	}
+}
+
+func TestCreateTemplate(t *testing.T) {
+	tests := map[string]struct {
+		splits []*types.TemplateSplit
+		err    error
+	}{
+		"successful": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(60)},
+				&types.TemplateSplit{AccountId: "2", Percent: templatePercent(40)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+			},
+			err: nil,
+		},
+		"percents do not balance": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(60)},
+				&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+			},
+			err: errors.New("percents must add up to 0"),
+		},
+		"two balancing splits": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Percent: templatePercent(60)},
+				&types.TemplateSplit{AccountId: "2"},
+				&types.TemplateSplit{AccountId: "3"},
+			},
+			err: errors.New("only one split can be left blank to balance the template"),
+		},
+		"amount and percent": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Amount: templateAmount(100), Percent: templatePercent(60)},
+				&types.TemplateSplit{AccountId: "3"},
+			},
+			err: errors.New("split for account 1 cannot have both an amount and a percent"),
+		},
+		"foreign currency": {
+			splits: []*types.TemplateSplit{
+				&types.TemplateSplit{AccountId: "1", Amount: templateAmount(100)},
+				&types.TemplateSplit{AccountId: "4", Amount: templateAmount(-100)},
+			},
+			err: errors.New("template splits must use accounts in the org currency"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTemplate{TdTransaction: &TdTransaction{}}
+		model := NewModel(td, nil, types.Config{})
+
+		template := &types.Template{
+			Id:     "5",
+			OrgId:  "2",
+			Name:   "phone bill",
+			Splits: test.splits,
+		}
+
+		err := model.CreateTemplate(template, "3")
+
+		assert.Equal(t, test.err, err)
+	}
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	td := &TdTemplate{TdTransaction: &TdTransaction{}}
+	model := NewModel(td, nil, types.Config{})
+
+	template := &types.Template{
+		Id:          "5",
+		OrgId:       "2",
+		Name:        "phone bill",
+		Description: "Phone bill",
+		Splits: []*types.TemplateSplit{
+			&types.TemplateSplit{AccountId: "1", Percent: templatePercent(50)},
+			&types.TemplateSplit{AccountId: "2", Percent: templatePercent(50)},
+			&types.TemplateSplit{AccountId: "3", Percent: templatePercent(-100)},
+		},
+	}
+
+	err := model.CreateTemplate(template, "3")
+
+	assert.Nil(t, err)
+
+	instance := &types.TemplateInstance{
+		Id:     "6",
+		Date:   time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Amount: 4999,
+	}
+
+	transaction, err := model.InstantiateTemplate("2", "5", "3", instance)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+	assert.Equal(t, "Phone bill", transaction.Description)
+	assert.Equal(t, []*types.Split{
+		&types.Split{TransactionId: "6", AccountId: "1", Amount: 2499, NativeAmount: 2499, Status: "uncleared"},
+		&types.Split{TransactionId: "6", AccountId: "2", Amount: 2500, NativeAmount: 2500, Status: "uncleared"},
+		&types.Split{TransactionId: "6", AccountId: "3", Amount: -4999, NativeAmount: -4999, Status: "uncleared"},
+	}, transaction.Splits)
+
+	_, err = model.InstantiateTemplate("7", "5", "3", instance)
+
+	assert.Equal(t, errors.New("User does not belong to org"), err)
+}
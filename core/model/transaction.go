@@ -4,34 +4,67 @@ import (
 	"errors"
 	"fmt"
 	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/util"
 	"github.com/openaccounting/oa-server/core/ws"
+	"log"
 	"time"
 )
 
+// maxBatchTransactions limits how many transactions are inserted in a single
+// db transaction
+const maxBatchTransactions = 5000
+
+// maxExternalIdLength is the size of the split.externalId column
+const maxExternalIdLength = 100
+
+// maxMemoLength is the size of the split.memo column
+const maxMemoLength = 300
+
+// autoReverseRetryPeriod is how long after its autoReverseDate a failing
+// auto-reversal is retried before it is given up
+const autoReverseRetryPeriod = 24 * time.Hour
+
 type TransactionInterface interface {
 	CreateTransaction(*types.Transaction) error
+	CreateTransactions(string, string, []*types.Transaction) ([]*types.TransactionError, error)
 	UpdateTransaction(string, *types.Transaction) error
 	GetTransactionsByAccount(string, string, string, *types.QueryOptions) ([]*types.Transaction, error)
 	GetTransactionsByOrg(string, string, *types.QueryOptions) ([]*types.Transaction, error)
 	DeleteTransaction(string, string, string) error
+	ReverseTransaction(string, string, string, *types.Transaction) error
+	ProcessAutoReversals(time.Time) error
 }
 
 func (model *Model) CreateTransaction(transaction *types.Transaction) (err error) {
-	err = model.checkSplits(transaction)
+	org, err := model.GetOrg(transaction.OrgId, transaction.UserId)
 
 	if err != nil {
 		return
 	}
 
-	if transaction.Id == "" {
-		return errors.New("id required")
+	userAccounts, err := model.GetAccounts(transaction.OrgId, transaction.UserId, "")
+
+	if err != nil {
+		return
 	}
 
-	transaction.Inserted = time.Now()
-	transaction.Updated = time.Now()
+	periods, err := model.db.GetAccountingPeriods(transaction.OrgId)
 
-	if transaction.Date.IsZero() {
-		transaction.Date = transaction.Inserted
+	if err != nil {
+		return
+	}
+
+	err = model.checkNewTransaction(transaction, org, userAccounts, periods, nil, nil, time.Now())
+
+	if err != nil {
+		return
+	}
+
+	// possible duplicates are a warning for the user and do not stop the insert
+	transaction.PossibleDuplicates, err = model.getPossibleDuplicates(transaction, "")
+
+	if err != nil {
+		return
 	}
 
 	err = model.db.InsertTransaction(transaction)
@@ -51,6 +84,101 @@ func (model *Model) CreateTransaction(transaction *types.Transaction) (err error
 	return
 }
 
+// CreateTransactions validates all transactions up front and inserts them in a
+// single db transaction. If any transaction is invalid nothing is inserted and
+// an error is returned for each invalid transaction.
+func (model *Model) CreateTransactions(orgId string, userId string, transactions []*types.Transaction) ([]*types.TransactionError, error) {
+	if len(transactions) == 0 {
+		return nil, errors.New("at least 1 transaction is required")
+	}
+
+	if len(transactions) > maxBatchTransactions {
+		return nil, errors.New(fmt.Sprintf("at most %d transactions are allowed", maxBatchTransactions))
+	}
+
+	// load everything needed for validation once rather than per transaction
+	org, err := model.GetOrg(orgId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	userAccounts, err := model.GetAccounts(orgId, userId, "")
+
+	if err != nil {
+		return nil, err
+	}
+
+	periods, err := model.db.GetAccountingPeriods(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	contacts, err := model.db.GetContacts(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	contactIds := getContactIds(contacts)
+
+	dimensions, err := model.db.GetDimensions(orgId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dimensionValues := getDimensionValues(dimensions)
+
+	now := time.Now()
+	ids := make(map[string]bool)
+	transactionErrors := make([]*types.TransactionError, 0)
+
+	for i, transaction := range transactions {
+		transaction.OrgId = orgId
+		transaction.UserId = userId
+
+		err = model.checkNewTransaction(transaction, org, userAccounts, periods, contactIds, dimensionValues, now)
+
+		if err == nil && ids[transaction.Id] {
+			err = errors.New("duplicate id")
+		}
+
+		if err != nil {
+			transactionErrors = append(transactionErrors, &types.TransactionError{
+				Index: i,
+				Id:    transaction.Id,
+				Error: err.Error(),
+			})
+		}
+
+		ids[transaction.Id] = true
+	}
+
+	if len(transactionErrors) > 0 {
+		return transactionErrors, nil
+	}
+
+	err = model.db.InsertTransactions(transactions)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Notify web socket subscribers
+	// TODO only get user ids that have permission to access transaction
+	userIds, err2 := model.db.GetOrgUserIds(orgId)
+
+	if err2 == nil {
+		for _, transaction := range transactions {
+			ws.PushTransaction(transaction, userIds, "create")
+		}
+	}
+
+	return nil, nil
+}
+
 func (model *Model) UpdateTransaction(oldId string, transaction *types.Transaction) (err error) {
 	err = model.checkSplits(transaction)
 
@@ -69,6 +197,89 @@ func (model *Model) UpdateTransaction(oldId string, transaction *types.Transacti
 		return
 	}
 
+	err = model.checkClosingTransaction(original)
+
+	if err != nil {
+		return
+	}
+
+	err = model.checkLocked(transaction.OrgId, transaction.UserId, original.Date, transaction.Date)
+
+	if err != nil {
+		return
+	}
+
+	err = checkReconciledSplits(original, transaction)
+
+	if err != nil {
+		return
+	}
+
+	err = model.checkTransactionContact(transaction, nil)
+
+	if err != nil {
+		return
+	}
+
+	err = checkTags(transaction)
+
+	if err != nil {
+		return
+	}
+
+	err = model.checkSplitDimensions(transaction, original, nil)
+
+	if err != nil {
+		return
+	}
+
+	// the link to a reversed transaction can't be changed
+	transaction.ReversalOf = original.ReversalOf
+
+	if transaction.ReversalOf != "" {
+		// a reversal must keep mirroring the transaction it reverses
+		var reversed *types.Transaction
+		reversed, err = model.getTransactionById(transaction.ReversalOf)
+
+		if err != nil {
+			return
+		}
+
+		err = checkReversalSplits(reversed, transaction)
+
+		if err != nil {
+			return
+		}
+	}
+
+	// existing reversals are relinked to the new version and must still mirror it
+	reversals, err := model.db.GetReversals(oldId)
+
+	if err != nil {
+		return
+	}
+
+	for _, reversal := range reversals {
+		// GetReversals doesn't include splits
+		reversal, err = model.getTransactionById(reversal.Id)
+
+		if err != nil {
+			return
+		}
+
+		err = checkReversalSplits(transaction, reversal)
+
+		if err != nil {
+			return
+		}
+	}
+
+	err = model.checkAutoReverseDate(transaction)
+
+	if err != nil {
+		return
+	}
+
 	transaction.Updated = time.Now()
 	transaction.Inserted = original.Inserted
 
@@ -134,6 +345,12 @@ func (model *Model) DeleteTransaction(id string, userId string, orgId string) (e
 		return
 	}
 
+	err = model.checkClosingTransaction(transaction)
+
+	if err != nil {
+		return
+	}
+
 	userAccounts, err := model.GetAccounts(orgId, userId, "")
 
 	if err != nil {
@@ -144,6 +361,16 @@ func (model *Model) DeleteTransaction(id string, userId string, orgId string) (e
 		if !model.accountsContainWriteAccess(userAccounts, split.AccountId) {
 			return errors.New(fmt.Sprintf("%s %s", "user does not have permission to access account", split.AccountId))
 		}
+
+		if split.Status == "reconciled" {
+			return errors.New("cannot delete a transaction with reconciled splits")
+		}
+	}
+
+	err = model.checkLocked(orgId, userId, transaction.Date)
+
+	if err != nil {
+		return
 	}
 
 	err = model.db.DeleteTransaction(id)
@@ -163,16 +390,134 @@ func (model *Model) DeleteTransaction(id string, userId string, orgId string) (e
 	return
 }
 
+// ReverseTransaction creates reversal as the mirror image of transaction id with
+// every split amount and quantity negated. reversal must have an id and may
+// have a date and description.
+func (model *Model) ReverseTransaction(orgId string, userId string, id string, reversal *types.Transaction) error {
+	original, err := model.getTransactionById(id)
+
+	if err != nil {
+		return err
+	}
+
+	if original.OrgId != orgId {
+		return errors.New("Transaction not found")
+	}
+
+	reversal.OrgId = orgId
+	reversal.UserId = userId
+	reversal.ReversalOf = original.Id
+	reversal.ContactId = original.ContactId
+	reversal.Tags = original.Tags
+	reversal.AutoReverseDate = nil
+	reversal.Splits = make([]*types.Split, len(original.Splits))
+
+	if reversal.Description == "" {
+		reversal.Description = "Reversal of " + original.Description
+	}
+
+	for i, split := range original.Splits {
+		reversal.Splits[i] = &types.Split{
+			TransactionId: reversal.Id,
+			AccountId:     split.AccountId,
+			Amount:        -split.Amount,
+			NativeAmount:  -split.NativeAmount,
+			Memo:          split.Memo,
+			UnitPrice:     split.UnitPrice,
+			Dimensions:    split.Dimensions,
+		}
+
+		if split.Quantity != nil {
+			quantity := -*split.Quantity
+			reversal.Splits[i].Quantity = &quantity
+		}
+	}
+
+	return model.CreateTransaction(reversal)
+}
+
+// ProcessAutoReversals reverses every transaction whose autoReverseDate is on
+// or before date. Transactions that have been reversed before are skipped so it
+// is safe to run repeatedly.
+func (model *Model) ProcessAutoReversals(date time.Time) error {
+	transactions, err := model.db.GetDueAutoReversals(date)
+
+	if err != nil {
+		return err
+	}
+
+	for _, transaction := range transactions {
+		id, err := util.NewGuid()
+
+		if err != nil {
+			return err
+		}
+
+		reversal := &types.Transaction{
+			Id:   id,
+			Date: *transaction.AutoReverseDate,
+		}
+
+		err = model.ReverseTransaction(transaction.OrgId, transaction.UserId, transaction.Id, reversal)
+
+		if err == nil {
+			continue
+		}
+
+		log.Printf("Failed to auto-reverse transaction %s: %s\n", transaction.Id, err.Error())
+
+		// keep retrying for a while in case the error is temporary, then give up
+		// so that a reversal that can never succeed isn't attempted forever
+		if date.Sub(*transaction.AutoReverseDate) < autoReverseRetryPeriod {
+			continue
+		}
+
+		err = model.clearAutoReverseDate(transaction)
+
+		if err != nil {
+			log.Printf("Failed to clear autoReverseDate of transaction %s: %s\n", transaction.Id, err.Error())
+			continue
+		}
+
+		log.Printf("Gave up auto-reversing transaction %s\n", transaction.Id)
+	}
+
+	return nil
+}
+
+// clearAutoReverseDate removes the autoReverseDate of transaction and notifies
+// web socket subscribers
+func (model *Model) clearAutoReverseDate(transaction *types.Transaction) error {
+	updated := time.Now()
+
+	err := model.db.ClearAutoReverseDate(transaction.Id, updated)
+
+	if err != nil {
+		return err
+	}
+
+	cleared := *transaction
+	cleared.AutoReverseDate = nil
+	cleared.Updated = updated
+
+	// Notify web socket subscribers
+	// TODO only get user ids that have permission to access transaction
+	userIds, err2 := model.db.GetOrgUserIds(transaction.OrgId)
+
+	if err2 == nil {
+		ws.PushTransaction(transaction, userIds, "delete")
+		ws.PushTransaction(&cleared, userIds, "create")
+	}
+
+	return nil
+}
+
 func (model *Model) getTransactionById(id string) (*types.Transaction, error) {
 	// TODO if this is made public, make a separate version that checks permission
 	return model.db.GetTransactionById(id)
 }
 
 func (model *Model) checkSplits(transaction *types.Transaction) (err error) {
-	if len(transaction.Splits) < 2 {
-		return errors.New("at least 2 splits are required")
-	}
-
 	org, err := model.GetOrg(transaction.OrgId, transaction.UserId)
 
 	if err != nil {
@@ -185,6 +530,16 @@ func (model *Model) checkSplits(transaction *types.Transaction) (err error) {
 		return
 	}
 
+	return model.checkSplitsWithAccounts(transaction, org, userAccounts)
+}
+
+// checkSplitsWithAccounts is checkSplits for an org and user accounts that are
+// already loaded
+func (model *Model) checkSplitsWithAccounts(transaction *types.Transaction, org *types.Org, userAccounts []*types.Account) error {
+	if len(transaction.Splits) < 2 {
+		return errors.New("at least 2 splits are required")
+	}
+
 	var amount int64 = 0
 
 	for _, split := range transaction.Splits {
@@ -202,6 +557,22 @@ func (model *Model) checkSplits(transaction *types.Transaction) (err error) {
 			return errors.New("nativeAmount must equal amount for native currency splits")
 		}
 
+		if len(split.ExternalId) > maxExternalIdLength {
+			return errors.New(fmt.Sprintf("externalId must be at most %d characters", maxExternalIdLength))
+		}
+
+		if len(split.Memo) > maxMemoLength {
+			return errors.New(fmt.Sprintf("memo must be at most %d characters", maxMemoLength))
+		}
+
+		if split.Status == "" {
+			split.Status = "uncleared"
+		}
+
+		if split.Status != "uncleared" && split.Status != "cleared" && split.Status != "reconciled" {
+			return errors.New("status must be uncleared, cleared or reconciled")
+		}
+
 		amount += split.NativeAmount
 	}
 
@@ -209,5 +580,195 @@ func (model *Model) checkSplits(transaction *types.Transaction) (err error) {
 		return errors.New("splits must add up to 0")
 	}
 
-	return
+	return nil
+}
+
+// checkNewTransaction validates a transaction that is about to be inserted and
+// sets its timestamps. contactIds and dimensionValues are the contacts and
+// dimension values of the org when they have been loaded for a batch,
+// otherwise nil.
+func (model *Model) checkNewTransaction(transaction *types.Transaction, org *types.Org, userAccounts []*types.Account, periods []*types.AccountingPeriod, contactIds map[string]bool, dimensionValues map[string]map[string]*types.DimensionValue, now time.Time) error {
+	err := model.checkSplitsWithAccounts(transaction, org, userAccounts)
+
+	if err != nil {
+		return err
+	}
+
+	err = checkReconciledSplits(nil, transaction)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkTransactionContact(transaction, contactIds)
+
+	if err != nil {
+		return err
+	}
+
+	err = checkTags(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	// a reversal may keep the inactive dimension values of the transaction it
+	// reverses
+	var reversed *types.Transaction
+
+	if transaction.ReversalOf != "" {
+		reversed, err = model.getTransactionById(transaction.ReversalOf)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	err = model.checkSplitDimensions(transaction, reversed, dimensionValues)
+
+	if err != nil {
+		return err
+	}
+
+	if transaction.Id == "" {
+		return errors.New("id required")
+	}
+
+	transaction.Inserted = now
+	transaction.Updated = now
+
+	if transaction.Date.IsZero() {
+		transaction.Date = transaction.Inserted
+	}
+
+	err = checkLockedDates(org, periods, transaction.Date)
+
+	if err != nil {
+		return err
+	}
+
+	err = model.checkAutoReverseDate(transaction)
+
+	if err != nil {
+		return err
+	}
+
+	if reversed != nil {
+		return model.checkReversal(reversed, transaction)
+	}
+
+	return nil
+}
+
+// checkReconciledSplits makes sure an edit of original keeps each of its
+// reconciled splits and its date unchanged, and that no other split is marked
+// reconciled. original is nil for new transactions.
+func checkReconciledSplits(original *types.Transaction, transaction *types.Transaction) error {
+	kept := make(map[*types.Split]bool)
+
+	if original != nil {
+		for _, reconciled := range original.Splits {
+			if reconciled.Status != "reconciled" {
+				continue
+			}
+
+			if !transaction.Date.Equal(original.Date) {
+				return errors.New("reconciled splits cannot be changed")
+			}
+
+			found := false
+
+			for _, split := range transaction.Splits {
+				if kept[split] || split.Status != "reconciled" {
+					continue
+				}
+
+				if split.AccountId == reconciled.AccountId && split.Amount == reconciled.Amount && split.NativeAmount == reconciled.NativeAmount {
+					kept[split] = true
+					found = true
+					break
+				}
+			}
+
+			if found == false {
+				return errors.New("reconciled splits cannot be changed")
+			}
+		}
+	}
+
+	for _, split := range transaction.Splits {
+		if split.Status == "reconciled" && !kept[split] {
+			return errors.New("splits can only be reconciled by finishing a reconciliation")
+		}
+	}
+
+	return nil
+}
+
+func (model *Model) checkAutoReverseDate(transaction *types.Transaction) error {
+	if transaction.AutoReverseDate == nil {
+		return nil
+	}
+
+	if transaction.ReversalOf != "" {
+		return errors.New("a reversal cannot be auto-reversed")
+	}
+
+	if !transaction.AutoReverseDate.After(transaction.Date) {
+		return errors.New("autoReverseDate must be after date")
+	}
+
+	return nil
+}
+
+// checkReversal makes sure transaction is the only reversal of original
+func (model *Model) checkReversal(original *types.Transaction, transaction *types.Transaction) error {
+	if original.OrgId != transaction.OrgId {
+		return errors.New("Transaction not found")
+	}
+
+	if original.Deleted == true {
+		return errors.New("cannot reverse a deleted transaction")
+	}
+
+	if original.ReversalOf != "" {
+		return errors.New("cannot reverse a reversal")
+	}
+
+	reversals, err := model.db.GetReversals(original.Id)
+
+	if err != nil {
+		return err
+	}
+
+	if len(reversals) > 0 {
+		return errors.New("transaction has already been reversed")
+	}
+
+	return checkReversalSplits(original, transaction)
+}
+
+// checkReversalSplits makes sure the splits of reversal cancel out original
+// account by account
+func checkReversalSplits(original *types.Transaction, reversal *types.Transaction) error {
+	amounts := make(map[string]int64)
+	nativeAmounts := make(map[string]int64)
+
+	for _, split := range original.Splits {
+		amounts[split.AccountId] += split.Amount
+		nativeAmounts[split.AccountId] += split.NativeAmount
+	}
+
+	for _, split := range reversal.Splits {
+		amounts[split.AccountId] += split.Amount
+		nativeAmounts[split.AccountId] += split.NativeAmount
+	}
+
+	for accountId := range amounts {
+		if amounts[accountId] != 0 || nativeAmounts[accountId] != 0 {
+			return errors.New("splits must reverse the original transaction")
+		}
+	}
+
+	return nil
 }
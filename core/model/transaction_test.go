@@ -6,6 +6,7 @@ import (
 	"github.com/openaccounting/oa-server/core/model/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"strings"
 	"testing"
 	"time"
 )
@@ -13,16 +14,36 @@ import (
 type TdTransaction struct {
 	db.Datastore
 	mock.Mock
+	inserted []*types.Transaction
+	existing []*types.Transaction
 }
 
 func (td *TdTransaction) GetOrg(orgId string, userId string) (*types.Org, error) {
+	lockDate := time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
 	org := &types.Org{
 		Currency: "USD",
+		LockDate: &lockDate,
 	}
 
 	return org, nil
 }
 
+func (td *TdTransaction) GetAccountingPeriods(orgId string) ([]*types.AccountingPeriod, error) {
+	return []*types.AccountingPeriod{
+		&types.AccountingPeriod{
+			Start:  time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC),
+			End:    time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+			Closed: true,
+		},
+		&types.AccountingPeriod{
+			Start:  time.Date(2018, time.February, 1, 0, 0, 0, 0, time.UTC),
+			End:    time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC),
+			Closed: false,
+		},
+	}, nil
+}
+
 func (td *TdTransaction) GetPermissionedAccountIds(userId string, orgId string, tokenId string) ([]string, error) {
 	return []string{"1", "2"}, nil
 }
@@ -32,6 +53,12 @@ func (td *TdTransaction) GetAccountsByOrgId(orgId string) ([]*types.Account, err
 }
 
 func (td *TdTransaction) InsertTransaction(transaction *types.Transaction) (err error) {
+	td.inserted = append(td.inserted, transaction)
+	return nil
+}
+
+func (td *TdTransaction) InsertTransactions(transactions []*types.Transaction) (err error) {
+	td.inserted = append(td.inserted, transactions...)
 	return nil
 }
 
@@ -45,11 +72,68 @@ func (td *TdTransaction) UpdateTransaction(oldId string, transaction *types.Tran
 	return args.Error(0)
 }
 
+func (td *TdTransaction) GetClosings(orgId string) ([]*types.Closing, error) {
+	return []*types.Closing{}, nil
+}
+
+func (td *TdTransaction) GetContacts(orgId string) ([]*types.Contact, error) {
+	return []*types.Contact{}, nil
+}
+
+func (td *TdTransaction) GetDimensions(orgId string) ([]*types.Dimension, error) {
+	return []*types.Dimension{}, nil
+}
+
 func (td *TdTransaction) GetOrgUserIds(id string) ([]string, error) {
 	return []string{"1"}, nil
 }
 
+func (td *TdTransaction) GetReversals(id string) ([]*types.Transaction, error) {
+	args := td.Called(id)
+	return args.Get(0).([]*types.Transaction), args.Error(1)
+}
+
+func (td *TdTransaction) GetDueAutoReversals(date time.Time) ([]*types.Transaction, error) {
+	args := td.Called(date)
+	return args.Get(0).([]*types.Transaction), args.Error(1)
+}
+
+func (td *TdTransaction) ClearAutoReverseDate(id string, updated time.Time) error {
+	args := td.Called(id)
+	return args.Error(0)
+}
+
+func (td *TdTransaction) DeleteAndInsertTransaction(oldId string, transaction *types.Transaction) error {
+	td.inserted = append(td.inserted, transaction)
+	return nil
+}
+
+func (td *TdTransaction) GetTransactionsWithSplit(accountId string, amount int64, start time.Time, end time.Time) ([]*types.Transaction, error) {
+	transactions := make([]*types.Transaction, 0)
+
+	for _, transaction := range td.existing {
+		if transaction.Date.Before(start) || transaction.Date.After(end) {
+			continue
+		}
+
+		for _, split := range transaction.Splits {
+			if split.AccountId == accountId && split.Amount == amount {
+				transactions = append(transactions, transaction)
+				break
+			}
+		}
+	}
+
+	return transactions, nil
+}
+
+func splitNumber(n float64) *float64 {
+	return &n
+}
+
 func TestCreateTransaction(t *testing.T) {
+	marchFirst := time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+
 	tests := map[string]struct {
 		err error
 		tx  *types.Transaction
@@ -57,72 +141,142 @@ func TestCreateTransaction(t *testing.T) {
 		"successful": {
 			err: nil,
 			tx: &types.Transaction{
-				"1",
-				"2",
-				"3",
-				time.Now(),
-				time.Now(),
-				time.Now(),
-				"description",
-				"",
-				false,
-				[]*types.Split{
-					&types.Split{"1", "1", 1000, 1000},
-					&types.Split{"1", "2", -1000, -1000},
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Now(),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
 				},
 			},
 		},
 		"bad split amounts": {
 			err: errors.New("splits must add up to 0"),
 			tx: &types.Transaction{
-				"1",
-				"2",
-				"3",
-				time.Now(),
-				time.Now(),
-				time.Now(),
-				"description",
-				"",
-				false,
-				[]*types.Split{
-					&types.Split{"1", "1", 1000, 1000},
-					&types.Split{"1", "2", -500, -500},
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Now(),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -500, NativeAmount: -500},
+				},
+			},
+		},
+		"memo too long": {
+			err: errors.New("memo must be at most 300 characters"),
+			tx: &types.Transaction{
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Now(),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000, Memo: strings.Repeat("a", 301)},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
 				},
 			},
 		},
 		"lacking permission": {
 			err: errors.New("user does not have permission to access account 3"),
 			tx: &types.Transaction{
-				"1",
-				"2",
-				"3",
-				time.Now(),
-				time.Now(),
-				time.Now(),
-				"description",
-				"",
-				false,
-				[]*types.Split{
-					&types.Split{"1", "1", 1000, 1000},
-					&types.Split{"1", "3", -1000, -1000},
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Now(),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "3", Amount: -1000, NativeAmount: -1000},
 				},
 			},
 		},
 		"nativeAmount mismatch": {
 			err: errors.New("nativeAmount must equal amount for native currency splits"),
 			tx: &types.Transaction{
-				"1",
-				"2",
-				"3",
-				time.Now(),
-				time.Now(),
-				time.Now(),
-				"description",
-				"",
-				false,
-				[]*types.Split{
-					&types.Split{"1", "1", 1000, 500},
-					&types.Split{"1", "2", -1000, -500},
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Now(),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 500},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -500},
+				},
+			},
+		},
+		"before lock date": {
+			err: errors.New("transaction date is before the lock date"),
+			tx: &types.Transaction{
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Date(2017, time.December, 31, 0, 0, 0, 0, time.UTC),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+				},
+			},
+		},
+		"closed period": {
+			err: errors.New("transaction date is in a closed period"),
+			tx: &types.Transaction{
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+				},
+			},
+		},
+		"open period": {
+			err: nil,
+			tx: &types.Transaction{
+				Id:          "1",
+				OrgId:       "2",
+				UserId:      "3",
+				Date:        time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+				Inserted:    time.Now(),
+				Updated:     time.Now(),
+				Description: "description",
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+				},
+			},
+		},
+		"autoReverseDate before date": {
+			err: errors.New("autoReverseDate must be after date"),
+			tx: &types.Transaction{
+				Id:              "1",
+				OrgId:           "2",
+				UserId:          "3",
+				Date:            time.Date(2018, time.March, 31, 0, 0, 0, 0, time.UTC),
+				AutoReverseDate: &marchFirst,
+				Splits: []*types.Split{
+					&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+					&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
 				},
 			},
 		},
@@ -139,3 +293,450 @@ func TestCreateTransaction(t *testing.T) {
 		assert.Equal(t, err, test.err)
 	}
 }
+
+func TestUpdateTransactionLocked(t *testing.T) {
+	td := &TdTransaction{}
+	model := NewModel(td, nil, types.Config{})
+
+	// moving a transaction out of a closed period is not allowed
+	original := &types.Transaction{
+		Id:    "1",
+		OrgId: "2",
+		Date:  time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	td.On("GetTransactionById", "1").Return(original, nil)
+
+	transaction := &types.Transaction{
+		Id:     "2",
+		OrgId:  "2",
+		UserId: "3",
+		Date:   time.Date(2018, time.February, 15, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "2", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "2", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	err := model.UpdateTransaction("1", transaction)
+
+	assert.Equal(t, errors.New("transaction date is in a closed period"), err)
+}
+
+func TestDeleteTransactionLocked(t *testing.T) {
+	td := &TdTransaction{}
+	model := NewModel(td, nil, types.Config{})
+
+	transaction := &types.Transaction{
+		Id:    "1",
+		OrgId: "2",
+		Date:  time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	td.On("GetTransactionById", "1").Return(transaction, nil)
+
+	err := model.DeleteTransaction("1", "3", "2")
+
+	assert.Equal(t, errors.New("transaction date is before the lock date"), err)
+}
+
+func TestReverseTransaction(t *testing.T) {
+	original := &types.Transaction{
+		Id:          "1",
+		OrgId:       "2",
+		UserId:      "3",
+		Date:        time.Date(2018, time.March, 31, 0, 0, 0, 0, time.UTC),
+		Description: "accrued wages",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000, Memo: "40 hours", Quantity: splitNumber(40), UnitPrice: splitNumber(25)},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	reversal := &types.Transaction{
+		Id:          "4",
+		OrgId:       "2",
+		UserId:      "3",
+		Date:        time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC),
+		Description: "reversal",
+		ReversalOf:  "1",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "4", AccountId: "1", Amount: -1000, NativeAmount: -1000},
+			&types.Split{TransactionId: "4", AccountId: "2", Amount: 1000, NativeAmount: 1000},
+		},
+	}
+
+	tests := map[string]struct {
+		orgId     string
+		original  *types.Transaction
+		reversals []*types.Transaction
+		err       error
+	}{
+		"successful": {
+			orgId:     "2",
+			original:  original,
+			reversals: []*types.Transaction{},
+			err:       nil,
+		},
+		"wrong org": {
+			orgId:     "5",
+			original:  original,
+			reversals: []*types.Transaction{},
+			err:       errors.New("Transaction not found"),
+		},
+		"already reversed": {
+			orgId:     "2",
+			original:  original,
+			reversals: []*types.Transaction{reversal},
+			err:       errors.New("transaction has already been reversed"),
+		},
+		"reversal": {
+			orgId:     "2",
+			original:  reversal,
+			reversals: []*types.Transaction{},
+			err:       errors.New("cannot reverse a reversal"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{}
+		td.On("GetTransactionById", test.original.Id).Return(test.original, nil)
+		td.On("GetReversals", test.original.Id).Return(test.reversals, nil)
+
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:   "6",
+			Date: time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		err := model.ReverseTransaction(test.orgId, "3", test.original.Id, transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, "1", transaction.ReversalOf)
+			assert.Equal(t, "Reversal of accrued wages", transaction.Description)
+			assert.Equal(t, []*types.Split{
+				&types.Split{TransactionId: "6", AccountId: "1", Amount: -1000, NativeAmount: -1000, Status: "uncleared", Memo: "40 hours", Quantity: splitNumber(-40), UnitPrice: splitNumber(25)},
+				&types.Split{TransactionId: "6", AccountId: "2", Amount: 1000, NativeAmount: 1000, Status: "uncleared"},
+			}, transaction.Splits)
+			assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+		}
+	}
+}
+
+func TestProcessAutoReversals(t *testing.T) {
+	now := time.Date(2018, time.April, 2, 0, 0, 0, 0, time.UTC)
+	autoReverseDate := time.Date(2018, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	accrual := &types.Transaction{
+		Id:              "1",
+		OrgId:           "2",
+		UserId:          "3",
+		Date:            time.Date(2018, time.March, 31, 0, 0, 0, 0, time.UTC),
+		Description:     "accrued wages",
+		AutoReverseDate: &autoReverseDate,
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	td := &TdTransaction{}
+	td.On("GetDueAutoReversals", now).Return([]*types.Transaction{accrual}, nil)
+	td.On("GetTransactionById", "1").Return(accrual, nil)
+	td.On("GetReversals", "1").Return([]*types.Transaction{}, nil)
+
+	model := NewModel(td, nil, types.Config{})
+
+	err := model.ProcessAutoReversals(now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(td.inserted))
+
+	reversal := td.inserted[0]
+
+	assert.Equal(t, "1", reversal.ReversalOf)
+	assert.Equal(t, "3", reversal.UserId)
+	assert.Equal(t, autoReverseDate, reversal.Date)
+	assert.Nil(t, reversal.AutoReverseDate)
+	assert.Equal(t, int64(-1000), reversal.Splits[0].Amount)
+	assert.Equal(t, int64(1000), reversal.Splits[1].Amount)
+}
+
+func TestProcessAutoReversalsFailing(t *testing.T) {
+	// the reversal date is in a closed period so the reversal can never succeed
+	autoReverseDate := time.Date(2018, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		now      time.Time
+		clearing bool
+	}{
+		"retried": {
+			now:      time.Date(2018, time.January, 31, 12, 0, 0, 0, time.UTC),
+			clearing: false,
+		},
+		"given up": {
+			now:      time.Date(2018, time.February, 2, 0, 0, 0, 0, time.UTC),
+			clearing: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		accrual := &types.Transaction{
+			Id:              "1",
+			OrgId:           "2",
+			UserId:          "3",
+			Date:            time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC),
+			AutoReverseDate: &autoReverseDate,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+			},
+		}
+
+		td := &TdTransaction{}
+		td.On("GetDueAutoReversals", test.now).Return([]*types.Transaction{accrual}, nil)
+		td.On("GetTransactionById", "1").Return(accrual, nil)
+		td.On("GetReversals", "1").Return([]*types.Transaction{}, nil)
+		td.On("ClearAutoReverseDate", "1").Return(nil)
+
+		model := NewModel(td, nil, types.Config{})
+
+		err := model.ProcessAutoReversals(test.now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(td.inserted))
+
+		if test.clearing {
+			td.AssertCalled(t, "ClearAutoReverseDate", "1")
+		} else {
+			td.AssertNotCalled(t, "ClearAutoReverseDate", "1")
+		}
+	}
+}
+
+func TestUpdateReversal(t *testing.T) {
+	original := &types.Transaction{
+		Id:     "1",
+		OrgId:  "2",
+		UserId: "3",
+		Date:   time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	reversal := &types.Transaction{
+		Id:         "4",
+		OrgId:      "2",
+		UserId:     "3",
+		Date:       time.Date(2018, time.February, 20, 0, 0, 0, 0, time.UTC),
+		ReversalOf: "1",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "4", AccountId: "1", Amount: -1000, NativeAmount: -1000},
+			&types.Split{TransactionId: "4", AccountId: "2", Amount: 1000, NativeAmount: 1000},
+		},
+	}
+
+	tests := map[string]struct {
+		amount int64
+		err    error
+	}{
+		"mirrored": {
+			amount: 1000,
+			err:    nil,
+		},
+		"changed": {
+			amount: 500,
+			err:    errors.New("splits must reverse the original transaction"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{}
+		td.On("GetTransactionById", "1").Return(original, nil)
+		td.On("GetTransactionById", "4").Return(reversal, nil)
+		td.On("GetReversals", "4").Return([]*types.Transaction{}, nil)
+
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:          "5",
+			OrgId:       "2",
+			UserId:      "3",
+			Date:        time.Date(2018, time.February, 21, 0, 0, 0, 0, time.UTC),
+			Description: "edited reversal",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "5", AccountId: "1", Amount: -test.amount, NativeAmount: -test.amount},
+				&types.Split{TransactionId: "5", AccountId: "2", Amount: test.amount, NativeAmount: test.amount},
+			},
+		}
+
+		err := model.UpdateTransaction("4", transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, "1", transaction.ReversalOf)
+			assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+		}
+	}
+}
+
+func TestUpdateReversedTransaction(t *testing.T) {
+	original := &types.Transaction{
+		Id:     "1",
+		OrgId:  "2",
+		UserId: "3",
+		Date:   time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "1", AccountId: "1", Amount: 1000, NativeAmount: 1000},
+			&types.Split{TransactionId: "1", AccountId: "2", Amount: -1000, NativeAmount: -1000},
+		},
+	}
+
+	reversal := &types.Transaction{
+		Id:         "4",
+		OrgId:      "2",
+		UserId:     "3",
+		Date:       time.Date(2018, time.February, 20, 0, 0, 0, 0, time.UTC),
+		ReversalOf: "1",
+		Splits: []*types.Split{
+			&types.Split{TransactionId: "4", AccountId: "1", Amount: -1000, NativeAmount: -1000},
+			&types.Split{TransactionId: "4", AccountId: "2", Amount: 1000, NativeAmount: 1000},
+		},
+	}
+
+	tests := map[string]struct {
+		amount int64
+		err    error
+	}{
+		"still mirrored": {
+			amount: 1000,
+			err:    nil,
+		},
+		"changed": {
+			amount: 1500,
+			err:    errors.New("splits must reverse the original transaction"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{}
+		td.On("GetTransactionById", "1").Return(original, nil)
+		td.On("GetTransactionById", "4").Return(reversal, nil)
+		td.On("GetReversals", "1").Return([]*types.Transaction{&types.Transaction{Id: "4", OrgId: "2", ReversalOf: "1"}}, nil)
+
+		model := NewModel(td, nil, types.Config{})
+
+		transaction := &types.Transaction{
+			Id:          "5",
+			OrgId:       "2",
+			UserId:      "3",
+			Date:        time.Date(2018, time.February, 10, 0, 0, 0, 0, time.UTC),
+			Description: "edited original",
+			Splits: []*types.Split{
+				&types.Split{TransactionId: "5", AccountId: "1", Amount: test.amount, NativeAmount: test.amount},
+				&types.Split{TransactionId: "5", AccountId: "2", Amount: -test.amount, NativeAmount: -test.amount},
+			},
+		}
+
+		err := model.UpdateTransaction("1", transaction)
+
+		assert.Equal(t, test.err, err)
+
+		if err == nil {
+			assert.Equal(t, []*types.Transaction{transaction}, td.inserted)
+		} else {
+			assert.Equal(t, 0, len(td.inserted))
+		}
+	}
+}
+
+func TestCreateTransactions(t *testing.T) {
+	transaction := func(id string, date time.Time, amount int64) *types.Transaction {
+		return &types.Transaction{
+			Id:   id,
+			Date: date,
+			Splits: []*types.Split{
+				&types.Split{TransactionId: id, AccountId: "1", Amount: 1000, NativeAmount: 1000},
+				&types.Split{TransactionId: id, AccountId: "2", Amount: amount, NativeAmount: amount},
+			},
+		}
+	}
+
+	march := time.Date(2018, time.March, 1, 0, 0, 0, 0, time.UTC)
+	january := time.Date(2018, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		transactions []*types.Transaction
+		errors       []*types.TransactionError
+		err          error
+	}{
+		"successful": {
+			transactions: []*types.Transaction{
+				transaction("1", march, -1000),
+				transaction("2", march, -1000),
+			},
+			errors: nil,
+			err:    nil,
+		},
+		"invalid items": {
+			transactions: []*types.Transaction{
+				transaction("1", march, -1000),
+				transaction("2", march, -500),
+				transaction("3", january, -1000),
+				transaction("1", march, -1000),
+			},
+			errors: []*types.TransactionError{
+				&types.TransactionError{Index: 1, Id: "2", Error: "splits must add up to 0"},
+				&types.TransactionError{Index: 2, Id: "3", Error: "transaction date is in a closed period"},
+				&types.TransactionError{Index: 3, Id: "1", Error: "duplicate id"},
+			},
+			err: nil,
+		},
+		"empty": {
+			transactions: []*types.Transaction{},
+			errors:       nil,
+			err:          errors.New("at least 1 transaction is required"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Logf("Running test case: %s", name)
+
+		td := &TdTransaction{}
+		model := NewModel(td, nil, types.Config{})
+
+		transactionErrors, err := model.CreateTransactions("2", "3", test.transactions)
+
+		assert.Equal(t, test.err, err)
+		assert.Equal(t, test.errors, transactionErrors)
+
+		if err == nil && transactionErrors == nil {
+			assert.Equal(t, test.transactions, td.inserted)
+
+			for _, transaction := range td.inserted {
+				assert.Equal(t, "2", transaction.OrgId)
+				assert.Equal(t, "3", transaction.UserId)
+			}
+		} else {
+			assert.Equal(t, 0, len(td.inserted))
+		}
+	}
+}
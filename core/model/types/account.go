@@ -14,6 +14,7 @@ type Account struct {
 	Currency      string    `json:"currency"`
 	Precision     int       `json:"precision"`
 	DebitBalance  bool      `json:"debitBalance"`
+	Cash          bool      `json:"cash"`
 	Balance       *int64    `json:"balance"`
 	NativeBalance *int64    `json:"nativeBalance"`
 	ReadOnly      bool      `json:"readOnly"`
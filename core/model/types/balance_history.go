@@ -0,0 +1,20 @@
+package types
+
+import (
+	"time"
+)
+
+type BalanceHistory struct {
+	OrgId     string          `json:"orgId"`
+	AccountId string          `json:"accountId"`
+	Currency  string          `json:"currency"`
+	Precision int             `json:"precision"`
+	Interval  string          `json:"interval"`
+	Points    []*BalancePoint `json:"points"`
+}
+
+type BalancePoint struct {
+	Date          time.Time `json:"date"`
+	Balance       int64     `json:"balance"`
+	NativeBalance int64     `json:"nativeBalance"`
+}
@@ -5,13 +5,20 @@ import (
 )
 
 type Budget struct {
+	Id       string        `json:"id"`
 	OrgId    string        `json:"orgId"`
 	Inserted time.Time     `json:"inserted"`
+	Updated  time.Time     `json:"updated"`
+	Name     string        `json:"name"`
+	Interval string        `json:"interval"`
+	Start    time.Time     `json:"start"`
+	Periods  int           `json:"periods"`
 	Items    []*BudgetItem `json:"items"`
 }
 
 type BudgetItem struct {
 	OrgId     string `json:"-"`
 	AccountId string `json:"accountId"`
+	Period    int    `json:"period"`
 	Amount    int64  `json:"amount"`
 }
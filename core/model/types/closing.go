@@ -0,0 +1,17 @@
+package types
+
+import (
+	"time"
+)
+
+type Closing struct {
+	Id              string    `json:"id"`
+	OrgId           string    `json:"orgId"`
+	UserId          string    `json:"userId"`
+	Inserted        time.Time `json:"inserted"`
+	Year            int       `json:"year"`
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	EquityAccountId string    `json:"equityAccountId"`
+	TransactionId   string    `json:"transactionId"`
+}
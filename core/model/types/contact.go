@@ -0,0 +1,18 @@
+package types
+
+import (
+	"time"
+)
+
+type Contact struct {
+	Id       string    `json:"id"`
+	OrgId    string    `json:"orgId"`
+	Inserted time.Time `json:"inserted"`
+	Updated  time.Time `json:"updated"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	Email    string    `json:"email"`
+	Phone    string    `json:"phone"`
+	Address  string    `json:"address"`
+	Inactive bool      `json:"inactive"`
+}
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"time"
+)
+
+type Dimension struct {
+	Id       string            `json:"id"`
+	OrgId    string            `json:"orgId"`
+	Inserted time.Time         `json:"inserted"`
+	Updated  time.Time         `json:"updated"`
+	Name     string            `json:"name"`
+	Values   []*DimensionValue `json:"values"`
+}
+
+type DimensionValue struct {
+	Id          string `json:"id"`
+	DimensionId string `json:"-"`
+	Name        string `json:"name"`
+	Inactive    bool   `json:"inactive"`
+}
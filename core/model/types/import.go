@@ -0,0 +1,48 @@
+package types
+
+import (
+	"time"
+)
+
+type CsvMapping struct {
+	OrgId             string    `json:"orgId"`
+	AccountId         string    `json:"accountId"`
+	Inserted          time.Time `json:"inserted"`
+	Updated           time.Time `json:"updated"`
+	Delimiter         string    `json:"delimiter"`
+	HeaderRows        int       `json:"headerRows"`
+	DateColumn        int       `json:"dateColumn"`
+	DateFormat        string    `json:"dateFormat"`
+	AmountColumn      int       `json:"amountColumn"`
+	OutflowColumn     int       `json:"outflowColumn"`
+	AmountSign        string    `json:"amountSign"`
+	DecimalSeparator  string    `json:"decimalSeparator"`
+	DescriptionColumn int       `json:"descriptionColumn"`
+	ReferenceColumn   int       `json:"referenceColumn"`
+	CategoryColumn    int       `json:"categoryColumn"`
+}
+
+type CsvImport struct {
+	Data            string      `json:"data"`
+	Mapping         *CsvMapping `json:"mapping"`
+	SaveMapping     bool        `json:"saveMapping"`
+	OffsetAccountId string      `json:"offsetAccountId"`
+	Create          bool        `json:"create"`
+	AllowDuplicates bool        `json:"allowDuplicates"`
+}
+
+type StatementImport struct {
+	Data            string `json:"data"`
+	DateFormat      string `json:"dateFormat"`
+	OffsetAccountId string `json:"offsetAccountId"`
+	Create          bool   `json:"create"`
+	AllowDuplicates bool   `json:"allowDuplicates"`
+}
+
+type ImportResult struct {
+	Transactions       []*Transaction      `json:"transactions"`
+	PossibleDuplicates []*Transaction      `json:"possibleDuplicates"`
+	Skipped            int                 `json:"skipped"`
+	Created            bool                `json:"created"`
+	Errors             []*TransactionError `json:"errors"`
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+)
+
+type Ledger struct {
+	OrgId                string        `json:"orgId"`
+	AccountId            string        `json:"accountId"`
+	Currency             string        `json:"currency"`
+	Precision            int           `json:"precision"`
+	Start                time.Time     `json:"start"`
+	End                  time.Time     `json:"end"`
+	OpeningBalance       int64         `json:"openingBalance"`
+	OpeningNativeBalance int64         `json:"openingNativeBalance"`
+	Lines                []*LedgerLine `json:"lines"`
+	ClosingBalance       int64         `json:"closingBalance"`
+	ClosingNativeBalance int64         `json:"closingNativeBalance"`
+}
+
+type LedgerLine struct {
+	TransactionId string    `json:"transactionId"`
+	AccountId     string    `json:"accountId"`
+	Date          time.Time `json:"date"`
+	Inserted      time.Time `json:"inserted"`
+	Description   string    `json:"description"`
+	Amount        int64     `json:"amount"`
+	NativeAmount  int64     `json:"nativeAmount"`
+	Balance       int64     `json:"balance"`
+	NativeBalance int64     `json:"nativeBalance"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+type AccountingPeriod struct {
+	Id       string    `json:"id"`
+	OrgId    string    `json:"orgId"`
+	UserId   string    `json:"userId"`
+	Inserted time.Time `json:"inserted"`
+	Updated  time.Time `json:"updated"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Closed   bool      `json:"closed"`
+}
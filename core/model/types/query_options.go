@@ -6,17 +6,19 @@ import (
 )
 
 type QueryOptions struct {
-	Limit                 int    `json:"limit"`
-	Skip                  int    `json:"skip"`
-	SinceInserted         int    `json:"sinceInserted"`
-	SinceUpdated          int    `json:"sinceUpdated"`
-	BeforeInserted        int    `json:"beforeInserted"`
-	BeforeUpdated         int    `json:"beforeUpdated"`
-	StartDate             int    `json:"startDate"`
-	EndDate               int    `json:"endDate"`
-	DescriptionStartsWith string `json:"descriptionStartsWith"`
-	IncludeDeleted        bool   `json:"includeDeleted"`
-	Sort                  string `json:"string"`
+	Limit                 int      `json:"limit"`
+	Skip                  int      `json:"skip"`
+	SinceInserted         int      `json:"sinceInserted"`
+	SinceUpdated          int      `json:"sinceUpdated"`
+	BeforeInserted        int      `json:"beforeInserted"`
+	BeforeUpdated         int      `json:"beforeUpdated"`
+	StartDate             int      `json:"startDate"`
+	EndDate               int      `json:"endDate"`
+	DescriptionStartsWith string   `json:"descriptionStartsWith"`
+	ContactId             string   `json:"contactId"`
+	Tags                  []string `json:"tags"`
+	IncludeDeleted        bool     `json:"includeDeleted"`
+	Sort                  string   `json:"string"`
 }
 
 func QueryOptionsFromURLQuery(urlQuery url.Values) (*QueryOptions, error) {
@@ -92,6 +94,17 @@ func QueryOptionsFromURLQuery(urlQuery url.Values) (*QueryOptions, error) {
 		qo.DescriptionStartsWith = urlQuery.Get("descriptionStartsWith")
 	}
 
+	if urlQuery.Get("contactId") != "" {
+		qo.ContactId = urlQuery.Get("contactId")
+	}
+
+	// tag can be repeated to require several tags
+	for _, tag := range urlQuery["tag"] {
+		if tag != "" {
+			qo.Tags = append(qo.Tags, tag)
+		}
+	}
+
 	if urlQuery.Get("includeDeleted") == "true" {
 		qo.IncludeDeleted = true
 	}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"time"
+)
+
+type Reconciliation struct {
+	Id               string    `json:"id"`
+	OrgId            string    `json:"orgId"`
+	UserId           string    `json:"userId"`
+	AccountId        string    `json:"accountId"`
+	Inserted         time.Time `json:"inserted"`
+	Updated          time.Time `json:"updated"`
+	StatementDate    time.Time `json:"statementDate"`
+	StatementBalance int64     `json:"statementBalance"`
+	Finished         bool      `json:"finished"`
+	ClearedBalance   int64     `json:"clearedBalance"`
+	Difference       int64     `json:"difference"`
+	TransactionIds   []string  `json:"transactionIds"`
+}
+
+type ReconciliationSplits struct {
+	TransactionIds []string `json:"transactionIds"`
+	Status         string   `json:"status"`
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+)
+
+type RecurringTransaction struct {
+	Id          string     `json:"id"`
+	OrgId       string     `json:"orgId"`
+	UserId      string     `json:"userId"`
+	Inserted    time.Time  `json:"inserted"`
+	Updated     time.Time  `json:"updated"`
+	Description string     `json:"description"`
+	Data        string     `json:"data"`
+	Rule        string     `json:"rule"`
+	Start       time.Time  `json:"start"`
+	End         *time.Time `json:"end"`
+	LastRun     *time.Time `json:"lastRun"`
+	NextRun     *time.Time `json:"nextRun"`
+	LastError   string     `json:"lastError"`
+	Splits      []*Split   `json:"splits"`
+}
@@ -0,0 +1,140 @@
+package types
+
+import (
+	"time"
+)
+
+type ReportPeriod struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type PeriodBalance struct {
+	Balance       int64
+	NativeBalance int64
+}
+
+type TrialBalance struct {
+	OrgId              string              `json:"orgId"`
+	Dates              []time.Time         `json:"dates"`
+	PivotValues        []string            `json:"pivotValues"`
+	Lines              []*TrialBalanceLine `json:"lines"`
+	NativeDebitTotals  []int64             `json:"nativeDebitTotals"`
+	NativeCreditTotals []int64             `json:"nativeCreditTotals"`
+}
+
+type TrialBalanceLine struct {
+	AccountId    string                `json:"accountId"`
+	Name         string                `json:"name"`
+	Parent       string                `json:"parent"`
+	Depth        int                   `json:"depth"`
+	Currency     string                `json:"currency"`
+	Precision    int                   `json:"precision"`
+	DebitBalance bool                  `json:"debitBalance"`
+	Leaf         bool                  `json:"leaf"`
+	Columns      []*TrialBalanceColumn `json:"columns"`
+}
+
+type TrialBalanceColumn struct {
+	Debit        int64 `json:"debit"`
+	Credit       int64 `json:"credit"`
+	NativeDebit  int64 `json:"nativeDebit"`
+	NativeCredit int64 `json:"nativeCredit"`
+}
+
+type IncomeStatement struct {
+	OrgId       string          `json:"orgId"`
+	Periods     []*ReportPeriod `json:"periods"`
+	PivotValues []string        `json:"pivotValues"`
+	Income      *ReportSection  `json:"income"`
+	Expenses    *ReportSection  `json:"expenses"`
+	NetIncome   []int64         `json:"netIncome"`
+}
+
+type BalanceSheet struct {
+	OrgId                     string         `json:"orgId"`
+	Dates                     []time.Time    `json:"dates"`
+	PivotValues               []string       `json:"pivotValues"`
+	Valuation                 string         `json:"valuation"`
+	Assets                    *ReportSection `json:"assets"`
+	Liabilities               *ReportSection `json:"liabilities"`
+	Equity                    *ReportSection `json:"equity"`
+	TotalLiabilitiesAndEquity []int64        `json:"totalLiabilitiesAndEquity"`
+}
+
+type ReportSection struct {
+	Lines  []*ReportLine `json:"lines"`
+	Totals []int64       `json:"totals"`
+}
+
+type ReportLine struct {
+	AccountId     string  `json:"accountId"`
+	Name          string  `json:"name"`
+	Parent        string  `json:"parent"`
+	Depth         int     `json:"depth"`
+	Currency      string  `json:"currency"`
+	Precision     int     `json:"precision"`
+	DebitBalance  bool    `json:"debitBalance"`
+	Amounts       []int64 `json:"amounts"`
+	NativeAmounts []int64 `json:"nativeAmounts"`
+}
+
+type CashFlowStatement struct {
+	OrgId       string           `json:"orgId"`
+	Periods     []*ReportPeriod  `json:"periods"`
+	OpeningCash []int64          `json:"openingCash"`
+	Operating   *CashFlowSection `json:"operating"`
+	Investing   *CashFlowSection `json:"investing"`
+	Financing   *CashFlowSection `json:"financing"`
+	Other       *CashFlowSection `json:"other"`
+	NetChange   []int64          `json:"netChange"`
+	ClosingCash []int64          `json:"closingCash"`
+}
+
+type CashFlowSection struct {
+	Lines  []*CashFlowLine `json:"lines"`
+	Totals []int64         `json:"totals"`
+}
+
+type CashFlowLine struct {
+	AccountId     string  `json:"accountId"`
+	Name          string  `json:"name"`
+	NativeAmounts []int64 `json:"nativeAmounts"`
+}
+
+type BudgetVariance struct {
+	OrgId    string                `json:"orgId"`
+	BudgetId string                `json:"budgetId"`
+	Name     string                `json:"name"`
+	Periods  []*ReportPeriod       `json:"periods"`
+	Lines    []*BudgetVarianceLine `json:"lines"`
+}
+
+type BudgetVarianceLine struct {
+	AccountId    string                  `json:"accountId"`
+	Name         string                  `json:"name"`
+	Parent       string                  `json:"parent"`
+	Depth        int                     `json:"depth"`
+	DebitBalance bool                    `json:"debitBalance"`
+	Columns      []*BudgetVarianceColumn `json:"columns"`
+}
+
+type BudgetVarianceColumn struct {
+	Actual   int64    `json:"actual"`
+	Budget   int64    `json:"budget"`
+	Variance int64    `json:"variance"`
+	Percent  *float64 `json:"percent"`
+}
+
+type TagReport struct {
+	OrgId   string           `json:"orgId"`
+	Periods []*ReportPeriod  `json:"periods"`
+	Lines   []*TagReportLine `json:"lines"`
+}
+
+type TagReportLine struct {
+	Tag       string  `json:"tag"`
+	Income    []int64 `json:"income"`
+	Expenses  []int64 `json:"expenses"`
+	NetIncome []int64 `json:"netIncome"`
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ReportOptions holds the periods of a report. Dates are calendar dates
+// formatted as YYYY-MM-DD and are interpreted in the org's timezone. End dates
+// are inclusive.
+//
+// A report has one column per period. Periods can be listed explicitly or
+// generated by splitting Start to End by Interval. Reports that show balances
+// as of a date use the end of each period, or Date if no period is given.
+//
+// DimensionValues limits a report to splits with those dimension values and
+// Pivot repeats its columns for each value of a dimension.
+type ReportOptions struct {
+	Date            int64        `json:"date"`
+	Start           string       `json:"start"`
+	End             string       `json:"end"`
+	Interval        string       `json:"interval"`
+	Periods         []*DateRange `json:"periods"`
+	DimensionValues []string     `json:"dimensionValues"`
+	Pivot           string       `json:"pivot"`
+}
+
+type DateRange struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+func ReportOptionsFromURLQuery(urlQuery url.Values) (*ReportOptions, error) {
+	ro := &ReportOptions{}
+
+	var err error
+
+	if urlQuery.Get("date") != "" {
+		ro.Date, err = strconv.ParseInt(urlQuery.Get("date"), 10, 64)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if urlQuery.Get("start") != "" {
+		ro.Start = urlQuery.Get("start")
+	}
+
+	if urlQuery.Get("end") != "" {
+		ro.End = urlQuery.Get("end")
+	}
+
+	if urlQuery.Get("interval") != "" {
+		ro.Interval = urlQuery.Get("interval")
+	}
+
+	// periods=2018-01-01..2018-12-31,2017-01-01..2017-12-31
+	// The start may be left out for reports that only need the end date.
+	if urlQuery.Get("periods") != "" {
+		for _, period := range strings.Split(urlQuery.Get("periods"), ",") {
+			dates := strings.Split(period, "..")
+
+			switch len(dates) {
+			case 1:
+				ro.Periods = append(ro.Periods, &DateRange{End: dates[0]})
+			case 2:
+				ro.Periods = append(ro.Periods, &DateRange{Start: dates[0], End: dates[1]})
+			default:
+				return nil, errors.New("invalid period " + period)
+			}
+		}
+	}
+
+	// dimensionValue may be repeated
+	for _, valueId := range urlQuery["dimensionValue"] {
+		if valueId != "" {
+			ro.DimensionValues = append(ro.DimensionValues, valueId)
+		}
+	}
+
+	if urlQuery.Get("pivot") != "" {
+		ro.Pivot = urlQuery.Get("pivot")
+	}
+
+	return ro, nil
+}
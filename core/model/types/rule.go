@@ -0,0 +1,28 @@
+package types
+
+import (
+	"time"
+)
+
+type Rule struct {
+	Id                 string    `json:"id"`
+	OrgId              string    `json:"orgId"`
+	Inserted           time.Time `json:"inserted"`
+	Updated            time.Time `json:"updated"`
+	Name               string    `json:"name"`
+	Priority           int       `json:"priority"`
+	DescriptionPattern string    `json:"descriptionPattern"`
+	MinAmount          *int64    `json:"minAmount"`
+	MaxAmount          *int64    `json:"maxAmount"`
+	AccountId          string    `json:"accountId"`
+	Payee              string    `json:"payee"`
+}
+
+type RuleApplication struct {
+	AccountId string `json:"accountId"`
+}
+
+type RuleResult struct {
+	Transactions []*Transaction      `json:"transactions"`
+	Errors       []*TransactionError `json:"errors"`
+}
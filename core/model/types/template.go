@@ -0,0 +1,30 @@
+package types
+
+import (
+	"time"
+)
+
+type Template struct {
+	Id          string           `json:"id"`
+	OrgId       string           `json:"orgId"`
+	Inserted    time.Time        `json:"inserted"`
+	Updated     time.Time        `json:"updated"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Data        string           `json:"data"`
+	Splits      []*TemplateSplit `json:"splits"`
+}
+
+type TemplateSplit struct {
+	TemplateId string   `json:"-"`
+	AccountId  string   `json:"accountId"`
+	Amount     *int64   `json:"amount"`
+	Percent    *float64 `json:"percent"`
+}
+
+type TemplateInstance struct {
+	Id          string    `json:"id"`
+	Date        time.Time `json:"date"`
+	Amount      int64     `json:"amount"`
+	Description string    `json:"description"`
+}
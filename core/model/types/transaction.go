@@ -5,21 +5,38 @@ import (
 )
 
 type Transaction struct {
-	Id          string    `json:"id"`
-	OrgId       string    `json:"orgId"`
-	UserId      string    `json:"userId"`
-	Date        time.Time `json:"date"`
-	Inserted    time.Time `json:"inserted"`
-	Updated     time.Time `json:"updated"`
-	Description string    `json:"description"`
-	Data        string    `json:"data"`
-	Deleted     bool      `json:"deleted"`
-	Splits      []*Split  `json:"splits"`
+	Id                 string     `json:"id"`
+	OrgId              string     `json:"orgId"`
+	UserId             string     `json:"userId"`
+	Date               time.Time  `json:"date"`
+	Inserted           time.Time  `json:"inserted"`
+	Updated            time.Time  `json:"updated"`
+	Description        string     `json:"description"`
+	ContactId          string     `json:"contactId"`
+	Tags               []string   `json:"tags"`
+	Data               string     `json:"data"`
+	ReversalOf         string     `json:"reversalOf"`
+	AutoReverseDate    *time.Time `json:"autoReverseDate"`
+	Deleted            bool       `json:"deleted"`
+	Splits             []*Split   `json:"splits"`
+	PossibleDuplicates []string   `json:"possibleDuplicates"`
 }
 
 type Split struct {
-	TransactionId string `json:"-"`
-	AccountId     string `json:"accountId"`
-	Amount        int64  `json:"amount"`
-	NativeAmount  int64  `json:"nativeAmount"`
+	TransactionId string            `json:"-"`
+	AccountId     string            `json:"accountId"`
+	Amount        int64             `json:"amount"`
+	NativeAmount  int64             `json:"nativeAmount"`
+	ExternalId    string            `json:"externalId"`
+	Status        string            `json:"status"`
+	Memo          string            `json:"memo"`
+	Quantity      *float64          `json:"quantity"`
+	UnitPrice     *float64          `json:"unitPrice"`
+	Dimensions    map[string]string `json:"dimensions"`
+}
+
+type TransactionError struct {
+	Index int    `json:"index"`
+	Id    string `json:"id"`
+	Error string `json:"error"`
 }
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"log"
+	"time"
+
+	"github.com/openaccounting/oa-server/core/model"
+)
+
+// lockName keeps servers sharing a database from running the jobs at the same
+// time and creating the same transactions twice
+const lockName = "oa-server-scheduler"
+
+type job struct {
+	name string
+	run  func(time.Time) error
+}
+
+// Start runs the scheduled jobs once and then every interval in the
+// background. model.Instance must be set before calling Start.
+func Start(interval time.Duration) {
+	jobs := []job{
+		job{"auto-reversals", model.Instance.ProcessAutoReversals},
+		job{"recurring transactions", model.Instance.ProcessRecurringTransactions},
+	}
+
+	go func() {
+		runJobs(jobs)
+
+		for range time.Tick(interval) {
+			runJobs(jobs)
+		}
+	}()
+}
+
+func runJobs(jobs []job) {
+	now := time.Now()
+
+	// if another server holds the lock it runs the jobs this time
+	_, err := model.Instance.RunWithLock(lockName, func() error {
+		for _, j := range jobs {
+			err := j.run(now)
+
+			if err != nil {
+				log.Printf("Scheduled job %s failed: %s\n", j.name, err.Error())
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Scheduled jobs failed: %s\n", err.Error())
+	}
+}
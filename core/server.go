@@ -7,12 +7,14 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/openaccounting/oa-server/core/api"
 	"github.com/openaccounting/oa-server/core/auth"
 	"github.com/openaccounting/oa-server/core/model"
 	"github.com/openaccounting/oa-server/core/model/db"
 	"github.com/openaccounting/oa-server/core/model/types"
+	"github.com/openaccounting/oa-server/core/scheduler"
 	"github.com/openaccounting/oa-server/core/util"
 )
 
@@ -43,6 +45,7 @@ func main() {
 
 	model.NewModel(db, bc, config)
 	auth.NewAuthService(db, bc)
+	scheduler.Start(time.Minute)
 
 	app, err := api.Init(config.ApiPrefix)
 	if err != nil {
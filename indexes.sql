@@ -3,4 +3,23 @@ CREATE INDEX split_accountId_index ON split (accountId);
 CREATE INDEX split_transactionId_index ON split (transactionId);
 CREATE INDEX split_date_index ON split (date);
 CREATE INDEX split_updated_index ON split (updated);
-CREATE INDEX budgetitem_orgId_index ON budgetitem (orgId);
\ No newline at end of file
+CREATE INDEX budgetitem_orgId_index ON budgetitem (orgId);
+CREATE INDEX budget_orgId_index ON budget (orgId);
+CREATE INDEX budgetitem_budgetId_index ON budgetitem (budgetId);
+CREATE INDEX accountingperiod_orgId_index ON accountingperiod (orgId);
+CREATE INDEX transaction_reversalOf_index ON transaction (reversalOf);
+CREATE INDEX transaction_autoReverseDate_index ON transaction (autoReverseDate);
+CREATE INDEX recurringtransaction_orgId_index ON recurringtransaction (orgId);
+CREATE INDEX recurringtransaction_nextRun_index ON recurringtransaction (nextRun);
+CREATE INDEX recurringsplit_recurringTransactionId_index ON recurringsplit (recurringTransactionId);
+CREATE INDEX template_orgId_index ON template (orgId);
+CREATE INDEX templatesplit_templateId_index ON templatesplit (templateId);
+CREATE INDEX split_accountId_externalId_index ON split (accountId, externalId);
+CREATE INDEX rule_orgId_index ON rule (orgId);
+CREATE INDEX reconciliation_orgId_index ON reconciliation (orgId);
+CREATE INDEX contact_orgId_index ON contact (orgId);
+CREATE INDEX transaction_contactId_index ON transaction (contactId);
+CREATE INDEX transactiontag_tag_index ON transactiontag (tag);
+CREATE INDEX dimension_orgId_index ON dimension (orgId);
+CREATE INDEX dimensionvalue_dimensionId_index ON dimensionvalue (dimensionId);
+CREATE INDEX splitdimension_dimensionId_valueId_index ON splitdimension (dimensionId, valueId);
\ No newline at end of file
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"log"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("Usage: migrate10.go <upgrade/downgrade>")
+	}
+
+	command := os.Args[1]
+
+	if command != "upgrade" && command != "downgrade" {
+		log.Fatal("Usage: migrate10.go <upgrade/downgrade>")
+	}
+
+	//filename is the path to the json config file
+	var config types.Config
+	file, err := os.Open("./config.json")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connectionString := config.User + ":" + config.Password + "@/" + config.Database
+	db, err := db.NewDB(connectionString)
+
+	if command == "upgrade" {
+		err = upgrade(db)
+	} else {
+		err = downgrade(db)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("done")
+}
+
+func upgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "CREATE TABLE recurringtransaction (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, description VARCHAR(300) NOT NULL, data TEXT NOT NULL, rule VARCHAR(200) NOT NULL, start BIGINT UNSIGNED NOT NULL, end BIGINT UNSIGNED, lastRun BIGINT UNSIGNED, nextRun BIGINT UNSIGNED, PRIMARY KEY(id)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "CREATE TABLE recurringsplit (id INT UNSIGNED NOT NULL AUTO_INCREMENT, recurringTransactionId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, amount BIGINT NOT NULL, nativeAmount BIGINT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "CREATE INDEX recurringtransaction_orgId_index ON recurringtransaction (orgId)"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	query4 := "CREATE INDEX recurringtransaction_nextRun_index ON recurringtransaction (nextRun)"
+
+	if _, err = tx.Exec(query4); err != nil {
+		return
+	}
+
+	query5 := "CREATE INDEX recurringsplit_recurringTransactionId_index ON recurringsplit (recurringTransactionId)"
+
+	if _, err = tx.Exec(query5); err != nil {
+		return
+	}
+
+	return
+}
+
+func downgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "DROP TABLE recurringsplit"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "DROP TABLE recurringtransaction"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"log"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("Usage: migrate12.go <upgrade/downgrade>")
+	}
+
+	command := os.Args[1]
+
+	if command != "upgrade" && command != "downgrade" {
+		log.Fatal("Usage: migrate12.go <upgrade/downgrade>")
+	}
+
+	//filename is the path to the json config file
+	var config types.Config
+	file, err := os.Open("./config.json")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connectionString := config.User + ":" + config.Password + "@/" + config.Database
+	db, err := db.NewDB(connectionString)
+
+	if command == "upgrade" {
+		err = upgrade(db)
+	} else {
+		err = downgrade(db)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("done")
+}
+
+func upgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "ALTER TABLE split ADD COLUMN externalId VARCHAR(100) AFTER nativeAmount"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "CREATE INDEX split_accountId_externalId_index ON split (accountId, externalId)"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "CREATE TABLE csvmapping (orgId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, delimiter VARCHAR(4) NOT NULL, headerRows INT UNSIGNED NOT NULL, dateColumn INT UNSIGNED NOT NULL, dateFormat VARCHAR(50) NOT NULL, amountColumn INT UNSIGNED NOT NULL, outflowColumn INT UNSIGNED NOT NULL, amountSign VARCHAR(10) NOT NULL, decimalSeparator VARCHAR(1) NOT NULL, descriptionColumn INT UNSIGNED NOT NULL, referenceColumn INT UNSIGNED NOT NULL, categoryColumn INT UNSIGNED NOT NULL, PRIMARY KEY(accountId)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	return
+}
+
+func downgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "DROP TABLE csvmapping"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "ALTER TABLE split DROP INDEX split_accountId_externalId_index, DROP COLUMN externalId"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	return
+}
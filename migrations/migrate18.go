@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"log"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("Usage: migrate18.go <upgrade/downgrade>")
+	}
+
+	command := os.Args[1]
+
+	if command != "upgrade" && command != "downgrade" {
+		log.Fatal("Usage: migrate18.go <upgrade/downgrade>")
+	}
+
+	//filename is the path to the json config file
+	var config types.Config
+	file, err := os.Open("./config.json")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connectionString := config.User + ":" + config.Password + "@/" + config.Database
+	db, err := db.NewDB(connectionString)
+
+	if command == "upgrade" {
+		err = upgrade(db)
+	} else {
+		err = downgrade(db)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("done")
+}
+
+func upgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "CREATE TABLE dimension (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "CREATE TABLE dimensionvalue (id BINARY(16) NOT NULL, dimensionId BINARY(16) NOT NULL, name VARCHAR(100) NOT NULL, inactive BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "CREATE TABLE splitdimension (transactionId BINARY(16) NOT NULL, position INT UNSIGNED NOT NULL, dimensionId BINARY(16) NOT NULL, valueId BINARY(16) NOT NULL, PRIMARY KEY(transactionId, position, dimensionId)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	query4 := "CREATE INDEX dimension_orgId_index ON dimension (orgId)"
+
+	if _, err = tx.Exec(query4); err != nil {
+		return
+	}
+
+	query5 := "CREATE INDEX dimensionvalue_dimensionId_index ON dimensionvalue (dimensionId)"
+
+	if _, err = tx.Exec(query5); err != nil {
+		return
+	}
+
+	query6 := "CREATE INDEX splitdimension_dimensionId_valueId_index ON splitdimension (dimensionId, valueId)"
+
+	if _, err = tx.Exec(query6); err != nil {
+		return
+	}
+
+	return
+}
+
+func downgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "DROP TABLE splitdimension"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "DROP TABLE dimensionvalue"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "DROP TABLE dimension"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/openaccounting/oa-server/core/model/db"
+	"github.com/openaccounting/oa-server/core/model/types"
+	"log"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("Usage: migrate6.go <upgrade/downgrade>")
+	}
+
+	command := os.Args[1]
+
+	if command != "upgrade" && command != "downgrade" {
+		log.Fatal("Usage: migrate6.go <upgrade/downgrade>")
+	}
+
+	//filename is the path to the json config file
+	var config types.Config
+	file, err := os.Open("./config.json")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decoder := json.NewDecoder(file)
+	err = decoder.Decode(&config)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	connectionString := config.User + ":" + config.Password + "@/" + config.Database
+	db, err := db.NewDB(connectionString)
+
+	if command == "upgrade" {
+		err = upgrade(db)
+	} else {
+		err = downgrade(db)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Println("done")
+}
+
+func upgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "CREATE TABLE budget (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, `interval` VARCHAR(10) NOT NULL, start BIGINT UNSIGNED NOT NULL, periods INT UNSIGNED NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "ALTER TABLE budgetitem ADD COLUMN budgetId BINARY(16) AFTER orgId, ADD COLUMN period INT UNSIGNED NOT NULL DEFAULT 0 AFTER accountId"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "CREATE INDEX budget_orgId_index ON budget (orgId)"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	query4 := "CREATE INDEX budgetitem_budgetId_index ON budgetitem (budgetId)"
+
+	if _, err = tx.Exec(query4); err != nil {
+		return
+	}
+
+	return
+}
+
+func downgrade(db *db.DB) (err error) {
+	tx, err := db.Begin()
+
+	if err != nil {
+		return
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p) // re-throw panic after Rollback
+		} else if err != nil {
+			tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+	}()
+
+	query1 := "DELETE FROM budgetitem WHERE budgetId IS NOT NULL"
+
+	if _, err = tx.Exec(query1); err != nil {
+		return
+	}
+
+	query2 := "ALTER TABLE budgetitem DROP INDEX budgetitem_budgetId_index, DROP COLUMN budgetId, DROP COLUMN period"
+
+	if _, err = tx.Exec(query2); err != nil {
+		return
+	}
+
+	query3 := "DROP TABLE budget"
+
+	if _, err = tx.Exec(query3); err != nil {
+		return
+	}
+
+	return
+}
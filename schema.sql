@@ -4,7 +4,7 @@ CREATE DATABASE openaccounting CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci;
 
 use openaccounting;
 
-CREATE TABLE org (id BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, currency VARCHAR(10) NOT NULL, `precision` INT NOT NULL, timezone VARCHAR(100) NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+CREATE TABLE org (id BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, currency VARCHAR(10) NOT NULL, `precision` INT NOT NULL, timezone VARCHAR(100) NOT NULL, lockDate BIGINT UNSIGNED, fiscalYearStart INT NOT NULL DEFAULT 1, PRIMARY KEY(id)) ENGINE=InnoDB;
 
 CREATE TABLE user (id BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, firstName VARCHAR(50) NOT NULL, lastName VARCHAR(50) NOT NULL, email VARCHAR(100) NOT NULL, passwordHash VARCHAR(100) NOT NULL, agreeToTerms BOOLEAN NOT NULL, passwordReset VARCHAR(32) NOT NULL, emailVerified BOOLEAN NOT NULL, emailVerifyCode VARCHAR(32) NOT NULL, signupSource VARCHAR(100) NOT NULL, UNIQUE(email), PRIMARY KEY(id)) ENGINE=InnoDB;
 
@@ -12,13 +12,13 @@ CREATE TABLE userorg (id INT UNSIGNED NOT NULL AUTO_INCREMENT, userId BINARY(16)
 
 CREATE TABLE token (id BINARY(16) NOT NULL, name VARCHAR(100), userOrgId INT UNSIGNED NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
 
-CREATE TABLE account (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, parent BINARY(16) NOT NULL, currency VARCHAR(10) NOT NULL, `precision` INT NOT NULL, debitBalance BOOLEAN NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+CREATE TABLE account (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, parent BINARY(16) NOT NULL, currency VARCHAR(10) NOT NULL, `precision` INT NOT NULL, debitBalance BOOLEAN NOT NULL, cash BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
 
-CREATE TABLE transaction (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, date BIGINT UNSIGNED NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, description VARCHAR(300) NOT NULL, data TEXT NOT NULL, deleted BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
+CREATE TABLE transaction (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, date BIGINT UNSIGNED NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, description VARCHAR(300) NOT NULL, contactId BINARY(16), data TEXT NOT NULL, reversalOf BINARY(16), autoReverseDate BIGINT UNSIGNED, deleted BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
 
-CREATE TABLE split (id INT UNSIGNED NOT NULL AUTO_INCREMENT, transactionId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, date BIGINT UNSIGNED NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, amount BIGINT NOT NULL, nativeAmount BIGINT NOT NULL, deleted BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
+CREATE TABLE split (id INT UNSIGNED NOT NULL AUTO_INCREMENT, transactionId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, date BIGINT UNSIGNED NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, amount BIGINT NOT NULL, nativeAmount BIGINT NOT NULL, externalId VARCHAR(100), status VARCHAR(20) NOT NULL DEFAULT 'uncleared', memo VARCHAR(300) NOT NULL DEFAULT '', quantity DOUBLE, unitPrice DOUBLE, position INT UNSIGNED NOT NULL DEFAULT 0, deleted BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
 
-CREATE TABLE balance (id INT UNSIGNED NOT NULL AUTO_INCREMENT, date BIGINT UNSIGNED NOT NULL, accountId BINARY(16) NOT NULL, amount BIGINT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+CREATE TABLE balance (id INT UNSIGNED NOT NULL AUTO_INCREMENT, date BIGINT UNSIGNED NOT NULL, accountId BINARY(16) NOT NULL, amount BIGINT NOT NULL, nativeAmount BIGINT NOT NULL, UNIQUE balance_accountId_date (accountId, date), PRIMARY KEY(id)) ENGINE=InnoDB;
 
 CREATE TABLE permission (id BINARY(16) NOT NULL, userId BINARY(16), tokenId BINARY(16), orgId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, type INT UNSIGNED NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
 
@@ -30,4 +30,34 @@ CREATE TABLE apikey (id BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL,
 
 CREATE TABLE invite (id VARCHAR(32) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, email VARCHAR(100) NOT NULL, accepted BOOLEAN NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
 
-CREATE TABLE budgetitem (id INT UNSIGNED NOT NULL AUTO_INCREMENT, orgId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, amount BIGINT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
\ No newline at end of file
+CREATE TABLE budget (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, `interval` VARCHAR(10) NOT NULL, start BIGINT UNSIGNED NOT NULL, periods INT UNSIGNED NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE budgetitem (id INT UNSIGNED NOT NULL AUTO_INCREMENT, orgId BINARY(16) NOT NULL, budgetId BINARY(16), accountId BINARY(16) NOT NULL, period INT UNSIGNED NOT NULL DEFAULT 0, inserted BIGINT UNSIGNED NOT NULL, amount BIGINT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE accountingperiod (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, start BIGINT UNSIGNED NOT NULL, end BIGINT UNSIGNED NOT NULL, closed BOOLEAN NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE closing (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, year INT NOT NULL, start BIGINT UNSIGNED NOT NULL, end BIGINT UNSIGNED NOT NULL, equityAccountId BINARY(16) NOT NULL, transactionId BINARY(16) NOT NULL, UNIQUE closing_orgId_year (orgId, year), PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE recurringtransaction (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, description VARCHAR(300) NOT NULL, data TEXT NOT NULL, rule VARCHAR(200) NOT NULL, start BIGINT UNSIGNED NOT NULL, end BIGINT UNSIGNED, lastRun BIGINT UNSIGNED, nextRun BIGINT UNSIGNED, lastError VARCHAR(300) NOT NULL DEFAULT '', PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE recurringsplit (id INT UNSIGNED NOT NULL AUTO_INCREMENT, recurringTransactionId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, amount BIGINT NOT NULL, nativeAmount BIGINT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE template (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, description VARCHAR(300) NOT NULL, data TEXT NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE templatesplit (id INT UNSIGNED NOT NULL AUTO_INCREMENT, templateId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, amount BIGINT, percent DOUBLE, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE csvmapping (orgId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, delimiter VARCHAR(4) NOT NULL, headerRows INT UNSIGNED NOT NULL, dateColumn INT UNSIGNED NOT NULL, dateFormat VARCHAR(50) NOT NULL, amountColumn INT UNSIGNED NOT NULL, outflowColumn INT UNSIGNED NOT NULL, amountSign VARCHAR(10) NOT NULL, decimalSeparator VARCHAR(1) NOT NULL, descriptionColumn INT UNSIGNED NOT NULL, referenceColumn INT UNSIGNED NOT NULL, categoryColumn INT UNSIGNED NOT NULL, PRIMARY KEY(accountId)) ENGINE=InnoDB;
+
+CREATE TABLE rule (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, priority INT NOT NULL, descriptionPattern VARCHAR(200) NOT NULL, minAmount BIGINT, maxAmount BIGINT, accountId BINARY(16) NOT NULL, payee VARCHAR(300) NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE reconciliation (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, userId BINARY(16) NOT NULL, accountId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, statementDate BIGINT UNSIGNED NOT NULL, statementBalance BIGINT NOT NULL, finished BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE contact (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, type VARCHAR(20) NOT NULL, email VARCHAR(100) NOT NULL, phone VARCHAR(50) NOT NULL, address VARCHAR(300) NOT NULL, inactive BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE transactiontag (transactionId BINARY(16) NOT NULL, tag VARCHAR(100) NOT NULL, PRIMARY KEY(transactionId, tag)) ENGINE=InnoDB;
+
+CREATE TABLE dimension (id BINARY(16) NOT NULL, orgId BINARY(16) NOT NULL, inserted BIGINT UNSIGNED NOT NULL, updated BIGINT UNSIGNED NOT NULL, name VARCHAR(100) NOT NULL, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE dimensionvalue (id BINARY(16) NOT NULL, dimensionId BINARY(16) NOT NULL, name VARCHAR(100) NOT NULL, inactive BOOLEAN NOT NULL DEFAULT false, PRIMARY KEY(id)) ENGINE=InnoDB;
+
+CREATE TABLE splitdimension (transactionId BINARY(16) NOT NULL, position INT UNSIGNED NOT NULL, dimensionId BINARY(16) NOT NULL, valueId BINARY(16) NOT NULL, PRIMARY KEY(transactionId, position, dimensionId)) ENGINE=InnoDB;
\ No newline at end of file